@@ -22,23 +22,29 @@ const (
 	MESSAGE_FAILED_PARSE_MULTIPART_FORM = "failed to parse multipart form"
 	MESSAGE_FAILED_PARSE_QUANTITY       = "failed to parse quantity"
 	// Authentication
-	MESSAGE_FAILED_REGISTER_USER = "failed register user"
-	MESSAGE_FAILED_LOGIN_USER    = "failed login user"
-	MESSAGE_FAILED_REFRESH_TOKEN = "failed refresh token"
+	MESSAGE_FAILED_REGISTER_USER       = "failed register user"
+	MESSAGE_FAILED_LOGIN_USER          = "failed login user"
+	MESSAGE_FAILED_REFRESH_TOKEN       = "failed refresh token"
+	MESSAGE_FAILED_LOGOUT              = "failed logout"
+	MESSAGE_FAILED_VERIFY_EMAIL        = "failed verify email"
+	MESSAGE_FAILED_RESEND_VERIFICATION = "failed resend verification email"
+	MESSAGE_FAILED_FORGOT_PASSWORD     = "failed request password reset"
+	MESSAGE_FAILED_RESET_PASSWORD      = "failed reset password"
+	MESSAGE_FAILED_REQUEST_OTP         = "failed request login code"
+	MESSAGE_FAILED_VERIFY_OTP          = "failed verify login code"
 	// Middleware
-	MESSAGE_FAILED_PROSES_REQUEST             = "failed proses request"
-	MESSAGE_FAILED_ACCESS_DENIED              = "failed access denied"
-	MESSAGE_FAILED_TOKEN_NOT_FOUND            = "failed token not found"
-	MESSAGE_FAILED_TOKEN_NOT_VALID            = "failed token not valid"
-	MESSAGE_FAILED_TOKEN_DENIED_ACCESS        = "failed token denied access"
-	MESSAGE_FAILED_INAVLID_ENPOINTS_TOKEN     = "failed invalid endpoints in token"
-	MESSAGE_FAILED_INAVLID_ROUTE_FORMAT_TOKEN = "failed invalid route format in token"
+	MESSAGE_FAILED_PROSES_REQUEST      = "failed proses request"
+	MESSAGE_FAILED_ACCESS_DENIED       = "failed access denied"
+	MESSAGE_FAILED_TOKEN_NOT_FOUND     = "failed token not found"
+	MESSAGE_FAILED_TOKEN_NOT_VALID     = "failed token not valid"
+	MESSAGE_FAILED_TOKEN_DENIED_ACCESS = "failed token denied access"
 	// User
 	MESSAGE_FAILED_CREATE_USER     = "failed create user"
 	MESSAGE_FAILED_GET_DETAIL_USER = "failed get detail user"
 	MESSAGE_FAILED_GET_LIST_USER   = "failed get list user"
 	MESSAGE_FAILED_UPDATE_USER     = "failed update user"
 	MESSAGE_FAILED_DELETE_USER     = "failed delete user"
+	MESSAGE_FAILED_UNLOCK_USER     = "failed unlock user"
 	// Package
 	MESSAGE_FAILED_CREATE_PACKAGE           = "failed create package"
 	MESSAGE_FAILED_GET_DETAIL_PACKAGE       = "failed get detail package"
@@ -48,12 +54,27 @@ const (
 	MESSAGE_FAILED_UPDATE_STATUS_PACKAGES   = "failed update status packages"
 	MESSAGE_FAILED_DELETE_PACKAGE           = "failed delete package"
 	MESSAGE_FAILED_GET_PROOFIMAGE           = "failed get proof image"
+	MESSAGE_FAILED_REGENERATE_PICKUP_CODE   = "failed regenerate pickup code"
+	// Package Import
+	MESSAGE_FAILED_IMPORT_PACKAGE = "failed import package"
+	// Stats
+	MESSAGE_FAILED_GET_PACKAGE_STATS = "failed get package stats"
+	// Package Export
+	MESSAGE_FAILED_EXPORT_PACKAGE         = "failed export package"
+	MESSAGE_FAILED_EXPORT_PACKAGE_HISTORY = "failed export package history"
+	// Package Label
+	MESSAGE_FAILED_PRINT_PACKAGE_LABEL = "failed print package label"
+	// Pickup Delegation
+	MESSAGE_FAILED_CREATE_PICKUP_DELEGATION   = "failed create pickup delegation"
+	MESSAGE_FAILED_GET_LIST_PICKUP_DELEGATION = "failed get list pickup delegation"
+	MESSAGE_FAILED_REVOKE_PICKUP_DELEGATION   = "failed revoke pickup delegation"
 	// Company
-	MESSAGE_FAILED_CREATE_COMPANY     = "failed create company"
-	MESSAGE_FAILED_GET_DETAIL_COMPANY = "failed get detail company"
-	MESSAGE_FAILED_GET_LIST_COMPANY   = "failed get list company"
-	MESSAGE_FAILED_UPDATE_COMPANY     = "failed update company"
-	MESSAGE_FAILED_DELETE_COMPANY     = "failed delete company"
+	MESSAGE_FAILED_CREATE_COMPANY          = "failed create company"
+	MESSAGE_FAILED_GET_DETAIL_COMPANY      = "failed get detail company"
+	MESSAGE_FAILED_GET_LIST_COMPANY        = "failed get list company"
+	MESSAGE_FAILED_UPDATE_COMPANY          = "failed update company"
+	MESSAGE_FAILED_UPDATE_COMPANY_CONTACTS = "failed update company contacts"
+	MESSAGE_FAILED_DELETE_COMPANY          = "failed delete company"
 
 	// recipient
 	MESSAGE_FAILED_CREATE_RECIPIENT     = "Failed to create recipient. Please check the provided data and try again."
@@ -68,6 +89,12 @@ const (
 	MESSAGE_FAILED_GET_DETAIL_LOCKER = "failed get detail locker"
 	MESSAGE_FAILED_UPDATE_LOCKER     = "failed update locker"
 	MESSAGE_FAILED_DELETE_LOCKER     = "failed delete locker"
+	// locker slot
+	MESSAGE_FAILED_CREATE_LOCKER_SLOT   = "failed create locker slot"
+	MESSAGE_FAILED_GET_LIST_LOCKER_SLOT = "failed get list locker slot"
+	MESSAGE_FAILED_UPDATE_LOCKER_SLOT   = "failed update locker slot"
+	MESSAGE_FAILED_DELETE_LOCKER_SLOT   = "failed delete locker slot"
+	MESSAGE_FAILED_GET_LOCKER_OCCUPANCY = "failed get locker occupancy"
 
 	MESSAGE_FAILED_CREATE_SENDER     = "Failed to create sender. Please check the provided data and try again."
 	MESSAGE_FAILED_GET_ALL_SENDERS   = "Failed to retrieve senders. Please try again later."
@@ -75,19 +102,57 @@ const (
 	MESSAGE_FAILED_UPDATE_SENDER     = "Failed to update sender. Please check the provided data and try again."
 	MESSAGE_FAILED_DELETE_SENDER     = "Failed to delete sender. Please ensure the sender exists and try again."
 
+	// notification
+	MESSAGE_FAILED_GET_LIST_NOTIFICATION = "failed get list notification"
+	MESSAGE_FAILED_RETRY_NOTIFICATION    = "failed retry notification"
+	MESSAGE_FAILED_CANCEL_NOTIFICATION   = "failed cancel notification"
+
+	// retention policy
+	MESSAGE_FAILED_CREATE_RETENTION_POLICY     = "failed create retention policy"
+	MESSAGE_FAILED_GET_LIST_RETENTION_POLICY   = "failed get list retention policy"
+	MESSAGE_FAILED_GET_DETAIL_RETENTION_POLICY = "failed get detail retention policy"
+	MESSAGE_FAILED_UPDATE_RETENTION_POLICY     = "failed update retention policy"
+	MESSAGE_FAILED_DELETE_RETENTION_POLICY     = "failed delete retention policy"
+
+	// role & permission
+	MESSAGE_FAILED_CREATE_ROLE       = "failed create role"
+	MESSAGE_FAILED_GET_LIST_ROLE     = "failed get list role"
+	MESSAGE_FAILED_GET_DETAIL_ROLE   = "failed get detail role"
+	MESSAGE_FAILED_UPDATE_ROLE       = "failed update role"
+	MESSAGE_FAILED_DELETE_ROLE       = "failed delete role"
+	MESSAGE_FAILED_GET_LIST_ROUTE    = "failed get list route"
+	MESSAGE_FAILED_ATTACH_PERMISSION = "failed attach permission"
+	MESSAGE_FAILED_DETACH_PERMISSION = "failed detach permission"
+	MESSAGE_FAILED_ASSIGN_ROLE       = "failed assign role"
+
+	// audit log
+	MESSAGE_FAILED_GET_LIST_AUDIT_LOG   = "failed get list audit log"
+	MESSAGE_FAILED_GET_DETAIL_AUDIT_LOG = "failed get detail audit log"
+
+	// search
+	MESSAGE_FAILED_SEARCH = "failed search"
+
 	// ====================================== Success ======================================
 	// Cron
 	MESSAGE_SUCCESS_AUTO_CHANGE_STATUS = "success packages expired successfully"
 	// Authentication
-	MESSAGE_SUCCESS_REGISTER_USER = "success register user"
-	MESSAGE_SUCCESS_LOGIN_USER    = "success login user"
-	MESSAGE_SUCCESS_REFRESH_TOKEN = "success refresh token"
+	MESSAGE_SUCCESS_REGISTER_USER       = "success register user"
+	MESSAGE_SUCCESS_LOGIN_USER          = "success login user"
+	MESSAGE_SUCCESS_REFRESH_TOKEN       = "success refresh token"
+	MESSAGE_SUCCESS_LOGOUT              = "success logout"
+	MESSAGE_SUCCESS_LOGOUT_ALL          = "success logout from all devices"
+	MESSAGE_SUCCESS_VERIFY_EMAIL        = "success verify email"
+	MESSAGE_SUCCESS_RESEND_VERIFICATION = "if the account exists and is not verified yet, a verification email has been sent"
+	MESSAGE_SUCCESS_FORGOT_PASSWORD     = "if the account exists, a password reset email has been sent"
+	MESSAGE_SUCCESS_RESET_PASSWORD      = "success reset password"
+	MESSAGE_SUCCESS_REQUEST_OTP         = "if the phone number is registered, a login code has been sent via whatsapp"
 	// User
 	MESSAGE_SUCCESS_CREATE_USER     = "success create user"
 	MESSAGE_SUCCESS_GET_DETAIL_USER = "success get detail user"
 	MESSAGE_SUCCESS_GET_LIST_USER   = "success get list user"
 	MESSAGE_SUCCESS_UPDATE_USER     = "success update user"
 	MESSAGE_SUCCESS_DELETE_USER     = "success delete user"
+	MESSAGE_SUCCESS_UNLOCK_USER     = "success unlock user"
 	// Package
 	MESSAGE_SUCCESS_CREATE_PACKAGE           = "success create package"
 	MESSAGE_SUCCESS_GET_DETAIL_PACKAGE       = "success get detail package"
@@ -96,12 +161,23 @@ const (
 	MESSAGE_SUCCESS_UPDATE_PACKAGE           = "success update package"
 	MESSAGE_SUCCESS_UPDATE_STATUS_PACKAGES   = "success update status packages"
 	MESSAGE_SUCCESS_DELETE_PACKAGE           = "success delete package"
+	MESSAGE_SUCCESS_REGENERATE_PICKUP_CODE   = "success regenerate pickup code"
+	// Package Import
+	MESSAGE_SUCCESS_IMPORT_PACKAGE          = "success import package"
+	MESSAGE_SUCCESS_VALIDATE_IMPORT_PACKAGE = "success validate import package"
+	// Stats
+	MESSAGE_SUCCESS_GET_PACKAGE_STATS = "success get package stats"
+	// Pickup Delegation
+	MESSAGE_SUCCESS_CREATE_PICKUP_DELEGATION   = "success create pickup delegation"
+	MESSAGE_SUCCESS_GET_LIST_PICKUP_DELEGATION = "success get list pickup delegation"
+	MESSAGE_SUCCESS_REVOKE_PICKUP_DELEGATION   = "success revoke pickup delegation"
 	// Company
-	MESSAGE_SUCCESS_CREATE_COMPANY     = "success create company"
-	MESSAGE_SUCCESS_GET_DETAIL_COMPANY = "success get detail company"
-	MESSAGE_SUCCESS_GET_LIST_COMPANY   = "success get list company"
-	MESSAGE_SUCCESS_UPDATE_COMPANY     = "success update company"
-	MESSAGE_SUCCESS_DELETE_COMPANY     = "success delete company"
+	MESSAGE_SUCCESS_CREATE_COMPANY          = "success create company"
+	MESSAGE_SUCCESS_GET_DETAIL_COMPANY      = "success get detail company"
+	MESSAGE_SUCCESS_GET_LIST_COMPANY        = "success get list company"
+	MESSAGE_SUCCESS_UPDATE_COMPANY          = "success update company"
+	MESSAGE_SUCCESS_UPDATE_COMPANY_CONTACTS = "success update company contacts"
+	MESSAGE_SUCCESS_DELETE_COMPANY          = "success delete company"
 
 	// recipient
 	MESSAGE_SUCCESS_CREATE_RECIPIENT     = "recipient created successfully."
@@ -116,6 +192,12 @@ const (
 	MESSAGE_SUCCESS_GET_DETAIL_LOCKER = "success get detail locker"
 	MESSAGE_SUCCESS_UPDATE_LOCKER     = "success update locker"
 	MESSAGE_SUCCESS_DELETE_LOCKER     = "success delete locker"
+	// locker slot
+	MESSAGE_SUCCESS_CREATE_LOCKER_SLOT   = "success create locker slot"
+	MESSAGE_SUCCESS_GET_LIST_LOCKER_SLOT = "success get list locker slot"
+	MESSAGE_SUCCESS_UPDATE_LOCKER_SLOT   = "success update locker slot"
+	MESSAGE_SUCCESS_DELETE_LOCKER_SLOT   = "success delete locker slot"
+	MESSAGE_SUCCESS_GET_LOCKER_OCCUPANCY = "success get locker occupancy"
 
 	// sender
 	MESSAGE_SUCCESS_CREATE_SENDER     = "sender created successfully."
@@ -123,6 +205,36 @@ const (
 	MESSAGE_SUCCESS_GET_DETAIL_SENDER = "sender details retrieved successfully."
 	MESSAGE_SUCCESS_UPDATE_SENDER     = "sender updated successfully."
 	MESSAGE_SUCCESS_DELETE_SENDER     = "sender deleted successfully."
+
+	// notification
+	MESSAGE_SUCCESS_GET_LIST_NOTIFICATION = "success get list notification"
+	MESSAGE_SUCCESS_RETRY_NOTIFICATION    = "success retry notification"
+	MESSAGE_SUCCESS_CANCEL_NOTIFICATION   = "success cancel notification"
+
+	// retention policy
+	MESSAGE_SUCCESS_CREATE_RETENTION_POLICY     = "success create retention policy"
+	MESSAGE_SUCCESS_GET_LIST_RETENTION_POLICY   = "success get list retention policy"
+	MESSAGE_SUCCESS_GET_DETAIL_RETENTION_POLICY = "success get detail retention policy"
+	MESSAGE_SUCCESS_UPDATE_RETENTION_POLICY     = "success update retention policy"
+	MESSAGE_SUCCESS_DELETE_RETENTION_POLICY     = "success delete retention policy"
+
+	// role & permission
+	MESSAGE_SUCCESS_CREATE_ROLE       = "success create role"
+	MESSAGE_SUCCESS_GET_LIST_ROLE     = "success get list role"
+	MESSAGE_SUCCESS_GET_DETAIL_ROLE   = "success get detail role"
+	MESSAGE_SUCCESS_UPDATE_ROLE       = "success update role"
+	MESSAGE_SUCCESS_DELETE_ROLE       = "success delete role"
+	MESSAGE_SUCCESS_GET_LIST_ROUTE    = "success get list route"
+	MESSAGE_SUCCESS_ATTACH_PERMISSION = "success attach permission"
+	MESSAGE_SUCCESS_DETACH_PERMISSION = "success detach permission"
+	MESSAGE_SUCCESS_ASSIGN_ROLE       = "success assign role"
+
+	// audit log
+	MESSAGE_SUCCESS_GET_LIST_AUDIT_LOG   = "success get list audit log"
+	MESSAGE_SUCCESS_GET_DETAIL_AUDIT_LOG = "success get detail audit log"
+
+	// search
+	MESSAGE_SUCCESS_SEARCH = "success search"
 )
 
 var (
@@ -133,6 +245,15 @@ var (
 	ErrDecryptToken            = errors.New("failed to decrypt token")
 	ErrTokenInvalid            = errors.New("token invalid")
 	ErrValidateToken           = errors.New("failed to validate token")
+	ErrNotRefreshToken         = errors.New("failed token is not a refresh token")
+	ErrNotAccessToken          = errors.New("failed token is not an access token")
+	// Session
+	ErrCreateSession      = errors.New("failed create session")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrSessionRevoked     = errors.New("failed session has been revoked or expired")
+	ErrRefreshTokenReused = errors.New("failed refresh token was already used, the session has been revoked")
+	ErrRotateSession      = errors.New("failed rotate session")
+	ErrRevokeSession      = errors.New("failed revoke session")
 	// File
 	ErrInvalidExtensionPhoto = errors.New("only jpg/jpeg/png allowed")
 	ErrCreateFile            = errors.New("failed create file")
@@ -148,9 +269,9 @@ var (
 	ErrInvalidEmail                = errors.New("failed invalid email")
 	ErrInvalidPassword             = errors.New("failed invalid password")
 	ErrFormatPhoneNumber           = errors.New("failed standarize phone number input")
+	ErrInvalidNotificationChannel  = errors.New("failed invalid notification channel")
 	ErrMissingRequiredField        = errors.New("failed missing required field")
 	ErrDescriptionPackageToShort   = errors.New("failed description package to short (min 5 word)")
-	ErrInvalidStatusTransition     = errors.New("failed invalid package status transition")
 	ErrCannotChangeStatusToExpired = errors.New("failed cannot change status to expired")
 	// Email
 	ErrEmailAlreadyExists = errors.New("email already exists")
@@ -158,10 +279,27 @@ var (
 	// Password
 	ErrPasswordNotMatch = errors.New("password not match")
 	// Authentication
-	ErrRegisterUser = errors.New("failed to register user")
+	ErrRegisterUser     = errors.New("failed to register user")
+	ErrEmailNotVerified = errors.New("failed email is not verified yet")
+	ErrUserTokenInvalid = errors.New("failed token is invalid, expired or already used")
+	ErrCreateUserToken  = errors.New("failed create token")
+	ErrVerifyEmail      = errors.New("failed verify email")
+	ErrResetPassword    = errors.New("failed reset password")
+	// Login Attempt
+	ErrAccountLocked        = errors.New("failed account is temporarily locked after too many wrong passwords, try again later")
+	ErrTooManyLoginAttempts = errors.New("failed too many failed logins from this address, try again later")
+	ErrUnlockUser           = errors.New("failed unlock user")
+	// Login OTP
+	ErrOTPRateLimited     = errors.New("failed too many login code requests, try again later")
+	ErrOTPInvalid         = errors.New("failed login code is invalid or expired")
+	ErrOTPTooManyAttempts = errors.New("failed too many wrong login codes, request a new one")
+	ErrCreateOTP          = errors.New("failed create login code")
+	ErrSendOTP            = errors.New("failed send login code")
 	// User
 	ErrUserNotFound             = errors.New("user not found")
 	ErrGetAllUserWithPagination = errors.New("failed get list user with pagination")
+	ErrGetAllUserWithCursor     = errors.New("failed get list user with cursor")
+	ErrUserNotManageable        = errors.New("failed admin accounts can not be managed by a company admin")
 	ErrGetUserByID              = errors.New("failed get user by id")
 	ErrUpdateUser               = errors.New("failed to update user")
 	ErrPasswordSame             = errors.New("failed new password same as old password")
@@ -172,20 +310,77 @@ var (
 	ErrCreatePackage               = errors.New("failed create package")
 	ErrCreatePackageHistory        = errors.New("failed create package history")
 	ErrGetAllPackageWithPagination = errors.New("failed get list package with pagination")
+	ErrGetAllPackageWithCursor     = errors.New("failed get list package with cursor")
 	ErrGetAllPackageHistory        = errors.New("failed get list package history")
+	ErrGetAllPackageHistoryCursor  = errors.New("failed get list package history with cursor")
 	ErrPackageNotFound             = errors.New("failed package not found")
 	ErrUpdateStatusPackage         = errors.New("failed update status package")
 	ErrInvalidPackageType          = errors.New("failed invalid package type")
 	ErrInvalidPackageStatus        = errors.New("failed invalid package status")
 	ErrUpdatePackage               = errors.New("failed update package")
+	ErrDeletePackage               = errors.New("failed delete package")
 	ErrInvalidQuantityPackage      = errors.New("failed invalid quantity package")
+	ErrPackageRecipient            = errors.New("failed package must be addressed to either a user or a company")
+	ErrCollectorNotCompanyMember   = errors.New("failed collector is not a member of the package company")
+	ErrInvalidPackageSort          = errors.New("failed invalid sort, use field:asc or field:desc separated by commas")
+	ErrInvalidQuantityRange        = errors.New("failed min quantity must not be greater than max quantity")
+	ErrInvalidCursor               = errors.New("failed invalid cursor")
+	ErrCursorSortUnsupported       = errors.New("failed sort is not supported with cursor pagination")
+	// Pickup Code
+	ErrGeneratePickupCode     = errors.New("failed generate pickup code")
+	ErrPickupCodeRequired     = errors.New("failed pickup code is required to complete the package")
+	ErrInvalidPickupCode      = errors.New("failed invalid pickup code")
+	ErrPickupCodeLocked       = errors.New("failed pickup code locked after too many attempts, regenerate the code")
+	ErrPickupCodeCountInvalid = errors.New("failed pickup codes must match the package ids")
+	ErrPickupCodeNotAvailable = errors.New("failed pickup code only exists while the package waits for pickup")
+	ErrUpdatePickupCode       = errors.New("failed update pickup code")
+	// Package Import
+	ErrImportFileRequired      = errors.New("failed import file is required")
+	ErrInvalidImportFile       = errors.New("failed import file must be a .csv or .xlsx file")
+	ErrReadImportFile          = errors.New("failed read import file")
+	ErrImportFileEmpty         = errors.New("failed import file has no data rows")
+	ErrImportTooManyRows       = errors.New("failed import file has too many rows")
+	ErrImportMissingColumn     = errors.New("failed import file is missing a required column")
+	ErrImportHasInvalidRows    = errors.New("failed import file has invalid rows, nothing was imported")
+	ErrImportPackage           = errors.New("failed import package")
+	ErrImportRecipientRequired = errors.New("failed recipient email or phone is required")
+	ErrImportDuplicateTracking = errors.New("failed tracking code is repeated in the import file")
+	ErrTrackingCodeExists      = errors.New("failed tracking code already exists")
+	// Stats
+	ErrInvalidStatsInterval = errors.New("failed interval must be day, week or month")
+	ErrGetPackageStats      = errors.New("failed get package stats")
+	// Package Export
+	ErrInvalidExportFormat  = errors.New("failed export format must be csv, xlsx or pdf")
+	ErrInvalidDateRange     = errors.New("failed invalid date range, use YYYY-MM-DD and from before to")
+	ErrExportPackage        = errors.New("failed export package")
+	ErrExportPackageHistory = errors.New("failed export package history")
+	// Package Label
+	ErrInvalidLabelFormat   = errors.New("failed label format must be pdf or zpl")
+	ErrPackageLabelEmpty    = errors.New("failed at least one package id is required")
+	ErrTooManyPackageLabels = errors.New("failed too many labels in one batch")
+	ErrPrintPackageLabel    = errors.New("failed print package label")
+	// Pickup Delegation
+	ErrInvalidDelegateName          = errors.New("failed invalid delegate name")
+	ErrInvalidDelegationWindow      = errors.New("failed delegation must end after it starts and not in the past")
+	ErrCreatePickupDelegation       = errors.New("failed create pickup delegation")
+	ErrGetAllPickupDelegation       = errors.New("failed get all pickup delegation")
+	ErrPickupDelegationNotFound     = errors.New("pickup delegation not found")
+	ErrPickupDelegationRevoked      = errors.New("failed pickup delegation already revoked")
+	ErrRevokePickupDelegation       = errors.New("failed revoke pickup delegation")
+	ErrDelegationNotValidForPackage = errors.New("failed pickup delegation is not active for this package")
+	ErrPackageNotOwned              = errors.New("failed package does not belong to this user")
 	// Company
 	ErrGetCompanyByID              = errors.New("failed get company by id")
+	ErrCompanyRequired             = errors.New("failed at least one company is required")
+	ErrGetCompanyByUserID          = errors.New("failed get companies of user")
 	ErrCreateCompany               = errors.New("failed to create company")
 	ErrCompanyNotFound             = errors.New("company not found")
 	ErrGetAllCompany               = errors.New("failed get all company")
 	ErrGetAllCompanyWithPagination = errors.New("failed to get list company with pagination")
 	ErrUpdateCompany               = errors.New("failed to update company")
+	ErrGetCompanyMembers           = errors.New("failed get company members")
+	ErrUpdateCompanyContacts       = errors.New("failed to update company contacts")
+	ErrContactNotCompanyMember     = errors.New("failed contact is not a member of the company")
 	ErrDeleteCompany               = errors.New("failed to delete company")
 	ErrCompanyIDRequired           = errors.New("company ID is required")
 	ErrInvalidCompanyName          = errors.New("failed invalid company name")
@@ -195,6 +390,26 @@ var (
 	ErrGetRoleFromName  = errors.New("failed get role by name")
 	ErrGetRoleFromToken = errors.New("failed get role from token")
 	ErrGetRoleFromID    = errors.New("failed get role by role id")
+	ErrRoleNotFound     = errors.New("role not found")
+	ErrGetAllRole       = errors.New("failed get all role")
+	ErrCreateRole       = errors.New("failed create role")
+	ErrUpdateRole       = errors.New("failed update role")
+	ErrDeleteRole       = errors.New("failed delete role")
+	ErrInvalidRoleName  = errors.New("failed invalid role name (min 3 characters)")
+	ErrRoleNameExists   = errors.New("failed role name already exists")
+	ErrBuiltInRole      = errors.New("failed built-in roles cannot be renamed or deleted")
+	ErrRoleHasUsers     = errors.New("failed role is still assigned to users")
+	ErrAssignRole       = errors.New("failed assign role")
+	ErrLastAdmin        = errors.New("failed cannot remove the last admin")
+	ErrCountRoleUsers   = errors.New("failed count users of role")
+	// Permission
+	ErrPermissionNotFound        = errors.New("permission not found")
+	ErrPermissionExists          = errors.New("failed permission already attached to role")
+	ErrCreatePermission          = errors.New("failed create permission")
+	ErrDeletePermission          = errors.New("failed delete permission")
+	ErrInvalidPermissionMethod   = errors.New("failed invalid permission method")
+	ErrInvalidPermissionEndpoint = errors.New("failed permission endpoint matches no registered route")
+	ErrAdminLockout              = errors.New("failed admins must keep access to permission management")
 
 	// Locker
 	ErrCreateLocker               = errors.New("failed to create locker")
@@ -207,11 +422,25 @@ var (
 	ErrLockerCodeAlreadyExists    = errors.New("locker already exists")
 	ErrGetLockerByLockerCode      = errors.New("failed get locker by locker code")
 
+	// Locker Slot
+	ErrInvalidSlotSize             = errors.New("failed invalid slot size")
+	ErrInvalidSlotCapacity         = errors.New("failed invalid slot capacity")
+	ErrCreateLockerSlot            = errors.New("failed create locker slot")
+	ErrGetAllLockerSlot            = errors.New("failed get all locker slot")
+	ErrLockerSlotNotFound          = errors.New("locker slot not found")
+	ErrUpdateLockerSlot            = errors.New("failed update locker slot")
+	ErrDeleteLockerSlot            = errors.New("failed delete locker slot")
+	ErrLockerSlotInUse             = errors.New("failed locker slot still holds packages")
+	ErrLockerSlotCodeAlreadyExists = errors.New("failed slot code already exists in this locker")
+	ErrGetLockerOccupancy          = errors.New("failed get locker occupancy")
+	ErrAssignLockerSlot            = errors.New("failed assign locker slot")
+
 	// Sender
 	ErrInvalidSenderName           = errors.New("invalid sender name")
 	ErrInvalidAddressName          = errors.New("invalid address name")
 	ErrCreateSender                = errors.New("failed to create sender")
 	ErrGetAllSendersWithPagination = errors.New("failed to get all senders with pagination")
+	ErrGetAllSendersWithCursor     = errors.New("failed to get all senders with cursor")
 	ErrGetSenderByID               = errors.New("failed to get sender by id")
 	ErrUpdateSender                = errors.New("failed to update sender")
 	ErrSenderNotFound              = errors.New("sender not found")
@@ -223,6 +452,36 @@ var (
 	ErrFindCompanyID        = errors.New("failed found company by id")
 	ErrFailedCreateUserCompany = errors.New("failed create user company")
 	ErrFailedPreloadUserCompany = errors.New("failed preload user companies")
+
+	// Notification
+	ErrCreateNotification               = errors.New("failed create notification")
+	ErrGetAllNotificationWithPagination = errors.New("failed get list notification with pagination")
+	ErrNotificationNotFound             = errors.New("notification not found")
+	ErrInvalidNotificationStatus        = errors.New("failed invalid notification status")
+	ErrUpdateNotification               = errors.New("failed update notification")
+	ErrNotificationCannotBeRetried      = errors.New("failed only failed, dead or cancelled notification can be retried")
+	ErrNotificationCannotBeCancelled    = errors.New("failed only pending or failed notification can be cancelled")
+
+	// Retention Policy
+	ErrCreateRetentionPolicy        = errors.New("failed create retention policy")
+	ErrGetAllRetentionPolicy        = errors.New("failed get all retention policy")
+	ErrRetentionPolicyNotFound      = errors.New("retention policy not found")
+	ErrUpdateRetentionPolicy        = errors.New("failed update retention policy")
+	ErrDeleteRetentionPolicy        = errors.New("failed delete retention policy")
+	ErrRetentionPolicyAlreadyExists = errors.New("failed retention policy for this company and package type already exists")
+	ErrGetRetentionPolicy           = errors.New("failed get retention policy")
+	ErrInvalidExpiryDays            = errors.New("failed invalid expiry days (min 1)")
+	ErrInvalidReminderDays          = errors.New("failed invalid reminder days (must be between 1 and expiry days)")
+	ErrInvalidGracePeriodDays       = errors.New("failed invalid grace period days (min 0)")
+
+	// Audit Log
+	ErrGetAllAuditLogWithPagination = errors.New("failed get list audit log with pagination")
+	ErrAuditLogNotFound             = errors.New("audit log not found")
+
+	// Search
+	ErrSearchQueryTooShort = errors.New("failed search query must be at least 2 characters")
+	ErrInvalidSearchType   = errors.New("failed search type must be package, user, sender or company")
+	ErrSearch              = errors.New("failed search")
 )
 
 type (
@@ -239,7 +498,32 @@ type (
 		RefreshToken string `json:"refresh_token"`
 	}
 	RefreshTokenResponse struct {
-		AccessToken string `json:"access_token"`
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	VerifyEmailRequest struct {
+		Token string `json:"token" binding:"required"`
+	}
+	ResendVerificationRequest struct {
+		Email string `json:"user_email" binding:"required"`
+	}
+	ForgotPasswordRequest struct {
+		Email string `json:"user_email" binding:"required"`
+	}
+	RequestLoginOTPRequest struct {
+		PhoneNumber string `json:"user_phone_number" binding:"required"`
+	}
+	RequestLoginOTPResponse struct {
+		ExpiresIn  int `json:"otp_expires_in"`
+		RetryAfter int `json:"otp_retry_after"`
+	}
+	VerifyLoginOTPRequest struct {
+		PhoneNumber string `json:"user_phone_number" binding:"required"`
+		Code        string `json:"otp_code" binding:"required"`
+	}
+	ResetPasswordRequest struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required"`
 	}
 
 	// Role
@@ -250,31 +534,36 @@ type (
 
 	// User
 	UserResponse struct {
-		ID          uuid.UUID         `json:"user_id"`
-		Name        string            `json:"user_name"`
-		Email       string            `json:"user_email"`
-		Password    string            `json:"user_password"`
-		PhoneNumber string            `json:"user_phone_number"`
-		Address     string            `json:"user_address"`
-		Companies   []CompanyResponse `json:"companies"`
-		Role        RoleResponse      `json:"role"`
+		ID                   uuid.UUID                    `json:"user_id"`
+		Name                 string                       `json:"user_name"`
+		Email                string                       `json:"user_email"`
+		Password             string                       `json:"user_password"`
+		PhoneNumber          string                       `json:"user_phone_number"`
+		Address              string                       `json:"user_address"`
+		NotificationChannels []entity.NotificationChannel `json:"user_notification_channels,omitempty"`
+		Companies            []CompanyResponse            `json:"companies"`
+		Role                 RoleResponse                 `json:"role"`
+		FailedLoginAttempts  int                          `json:"user_failed_login_attempts,omitempty"`
+		LockedUntil          *time.Time                   `json:"user_locked_until,omitempty"`
 	}
 	CreateUserRequest struct {
-		Name        string       `json:"user_name" form:"user_name"`
-		Email       string       `json:"user_email" form:"user_email"`
-		Password    string       `json:"user_password" form:"user_password"`
-		PhoneNumber string       `json:"user_phone_number" form:"user_phone_number"`
-		Address     string       `json:"user_address,omitempty" form:"user_address"`
-		CompanyIDs  []*uuid.UUID `json:"company_ids" form:"company_ids"`
+		Name                 string                       `json:"user_name" form:"user_name"`
+		Email                string                       `json:"user_email" form:"user_email"`
+		Password             string                       `json:"user_password" form:"user_password"`
+		PhoneNumber          string                       `json:"user_phone_number" form:"user_phone_number"`
+		Address              string                       `json:"user_address,omitempty" form:"user_address"`
+		NotificationChannels []entity.NotificationChannel `json:"user_notification_channels,omitempty" form:"user_notification_channels"`
+		CompanyIDs           []*uuid.UUID                 `json:"company_ids" form:"company_ids"`
 	}
 	UpdateUserRequest struct {
-		ID          string       `json:"-"`
-		Name        string       `json:"user_name,omitempty"`
-		Email       string       `json:"user_email,omitempty"`
-		Password    string       `json:"user_password,omitempty"`
-		PhoneNumber string       `json:"user_phone_number,omitempty"`
-		Address     string       `json:"user_address,omitempty"`
-		CompanyIDs  []*uuid.UUID `json:"company_ids,omitempty"`
+		ID                   string                       `json:"-"`
+		Name                 string                       `json:"user_name,omitempty"`
+		Email                string                       `json:"user_email,omitempty"`
+		Password             string                       `json:"user_password,omitempty"`
+		PhoneNumber          string                       `json:"user_phone_number,omitempty"`
+		Address              string                       `json:"user_address,omitempty"`
+		NotificationChannels []entity.NotificationChannel `json:"user_notification_channels,omitempty"`
+		CompanyIDs           []*uuid.UUID                 `json:"company_ids,omitempty"`
 	}
 	DeleteUserRequest struct {
 		UserID string `json:"-"`
@@ -287,6 +576,14 @@ type (
 		PaginationResponse
 		Users []entity.User
 	}
+	UserCursorResponse struct {
+		CursorResponse
+		Data []UserResponse `json:"data"`
+	}
+	UserCursorRepositoryResponse struct {
+		CursorResponse
+		Users []entity.User
+	}
 
 	// Package
 	CreatePackageRequest struct {
@@ -296,8 +593,10 @@ type (
 		Type         entity.Type           `json:"package_type" form:"package_type"`
 		Quantity     int                   `json:"package_quantity" form:"package_quantity"`
 		UserID       uuid.UUID             `json:"user_id" form:"user_id"`
+		CompanyID    *uuid.UUID            `json:"company_id,omitempty" form:"company_id"`
 		SenderID     *uuid.UUID            `json:"sender_id" form:"sender_id"`
 		LockerID     *uuid.UUID            `json:"locker_id" form:"locker_id"`
+		Size         entity.SlotSize       `json:"package_size" form:"package_size"`
 		FileHeader   *multipart.FileHeader `json:"fileheader,omitempty"`
 		FileReader   multipart.File        `json:"filereader,omitempty"`
 	}
@@ -315,6 +614,9 @@ type (
 		User         UserResponse   `json:"user"`
 		Locker       LockerResponse `json:"locker"`
 		entity.TimeStamp
+		Size    entity.SlotSize     `json:"package_size"`
+		Slot    *LockerSlotResponse `json:"slot,omitempty"`
+		Company *CompanyResponse    `json:"company,omitempty"`
 	}
 	PackagePaginationResponse struct {
 		PaginationResponse
@@ -324,6 +626,14 @@ type (
 		PaginationResponse
 		Packages []entity.Package
 	}
+	PackageCursorResponse struct {
+		CursorResponse
+		Data []PackageResponse `json:"data"`
+	}
+	PackageCursorRepositoryResponse struct {
+		CursorResponse
+		Packages []entity.Package
+	}
 	UpdatePackageRequest struct {
 		ID           string                `json:"-"`
 		TrackingCode string                `json:"package_tracking_code" form:"package_tracking_code"`
@@ -334,13 +644,25 @@ type (
 		Quantity     *int                  `json:"package_quantity" form:"package_quantity"`
 		SenderID     *uuid.UUID            `json:"sender_id,omitempty" form:"sender_id"`
 		LockerID     *uuid.UUID            `json:"locker_id,omitempty" form:"locker_id"`
+		Size         entity.SlotSize       `json:"package_size,omitempty" form:"package_size"`
+		PickupCode   string                `json:"pickup_code,omitempty" form:"pickup_code"`
+		DelegationID *uuid.UUID            `json:"delegation_id,omitempty" form:"delegation_id"`
+		CollectorID  *uuid.UUID            `json:"collector_id,omitempty" form:"collector_id"`
 		FileHeader   *multipart.FileHeader `json:"fileheader,omitempty"`
 		FileReader   multipart.File        `json:"filereader,omitempty"`
 	}
 	UpdateStatusPackages struct {
-		PackageIDs []uuid.UUID           `json:"package_ids" form:"package_ids"`
-		FileReader multipart.File        `form:"proof_image"`
-		FileHeader *multipart.FileHeader `form:"proof_image"`
+		PackageIDs   []uuid.UUID           `json:"package_ids" form:"package_ids"`
+		PickupCodes  []string              `json:"pickup_codes" form:"pickup_codes"`
+		DelegationID *uuid.UUID            `json:"delegation_id,omitempty" form:"delegation_id"`
+		CollectorID  *uuid.UUID            `json:"collector_id,omitempty" form:"collector_id"`
+		FileReader   multipart.File        `form:"proof_image"`
+		FileHeader   *multipart.FileHeader `form:"proof_image"`
+	}
+	PickupCodeResponse struct {
+		PackageID      uuid.UUID `json:"package_id"`
+		TrackingCode   string    `json:"package_tracking_code"`
+		PickupAttempts int       `json:"package_pickup_attempts"`
 	}
 
 	UserResponseCustom struct {
@@ -353,7 +675,27 @@ type (
 		Status      entity.Status      `json:"history_status"`
 		Description string             `json:"history_description"`
 		ChangedBy   UserResponseCustom `json:"changed_by"`
+		CollectedBy string             `json:"history_collected_by,omitempty"`
 		CreatedAt   time.Time          `json:"created_at"`
+
+		EventType entity.HistoryEventType `json:"history_event_type"`
+		Changes   entity.HistoryChanges   `json:"history_changes"`
+		ActorRole string                  `json:"history_actor_role"`
+		IPAddress string                  `json:"history_ip_address,omitempty"`
+		UserAgent string                  `json:"history_user_agent,omitempty"`
+		Summary   string                  `json:"history_summary"`
+	}
+	PackageHistoryCursorResponse struct {
+		CursorResponse
+		Data []PackageHistoryResponse `json:"data"`
+	}
+	PackageHistoryCursorRepositoryResponse struct {
+		CursorResponse
+		PackageHistories []entity.PackageHistory
+	}
+	PackageTimelineDay struct {
+		Date   string                   `json:"date"`
+		Events []PackageHistoryResponse `json:"events"`
 	}
 	UpdatePackageResponse struct {
 		ID           uuid.UUID          `json:"package_id"`
@@ -370,20 +712,127 @@ type (
 		User         UserResponseCustom `json:"user_id"`
 		ChangedBy    UserResponseCustom `json:"changed_by"`
 		entity.TimeStamp
+		Size    entity.SlotSize     `json:"package_size"`
+		Slot    *LockerSlotResponse `json:"slot,omitempty"`
+		Company *CompanyResponse    `json:"company,omitempty"`
 	}
 	DeletePackageRequest struct {
 		PackageID string `json:"-"`
 	}
 
+	// Package Import
+	ImportPackageRequest struct {
+		DryRun     bool                  `json:"dry_run" form:"dry_run"`
+		FileHeader *multipart.FileHeader `json:"fileheader,omitempty"`
+		FileReader multipart.File        `json:"filereader,omitempty"`
+	}
+	ImportPackageRowResult struct {
+		Row          int        `json:"row"`
+		TrackingCode string     `json:"package_tracking_code"`
+		Valid        bool       `json:"valid"`
+		Errors       []string   `json:"errors,omitempty"`
+		PackageID    *uuid.UUID `json:"package_id,omitempty"`
+		UserID       *uuid.UUID `json:"user_id,omitempty"`
+		SlotCode     string     `json:"slot_code,omitempty"`
+	}
+	ImportPackageResponse struct {
+		DryRun        bool                     `json:"dry_run"`
+		TotalRows     int                      `json:"total_rows"`
+		ValidRows     int                      `json:"valid_rows"`
+		InvalidRows   int                      `json:"invalid_rows"`
+		ImportedRows  int                      `json:"imported_rows"`
+		Notifications int                      `json:"notifications"`
+		Rows          []ImportPackageRowResult `json:"rows"`
+	}
+
+	// Stats
+	StatsRequest struct {
+		From     *time.Time `json:"from,omitempty" form:"from"`
+		To       *time.Time `json:"to,omitempty" form:"to"`
+		Interval string     `json:"interval,omitempty" form:"interval"`
+		Limit    int        `json:"limit,omitempty" form:"limit"`
+	}
+	StatsBucket struct {
+		Key   string `json:"key"`
+		Label string `json:"label"`
+		Count int64  `json:"count"`
+	}
+	PackageStatsResponse struct {
+		Total                      int64         `json:"total"`
+		Overdue                    int64         `json:"overdue"`
+		AverageTimeToPickupSeconds float64       `json:"average_time_to_pickup_seconds"`
+		AverageTimeToPickup        string        `json:"average_time_to_pickup"`
+		ByStatus                   []StatsBucket `json:"by_status"`
+		ByType                     []StatsBucket `json:"by_type"`
+		ByCompany                  []StatsBucket `json:"by_company"`
+		ByLocker                   []StatsBucket `json:"by_locker"`
+		GeneratedAt                time.Time     `json:"generated_at"`
+	}
+	StatsPoint struct {
+		Period time.Time `json:"period"`
+		Count  int64     `json:"count"`
+	}
+	PackageReceivedStatsResponse struct {
+		Interval    string       `json:"interval"`
+		Series      []StatsPoint `json:"series"`
+		GeneratedAt time.Time    `json:"generated_at"`
+	}
+	TopRecipientResponse struct {
+		UserID  uuid.UUID `json:"user_id"`
+		Name    string    `json:"user_name"`
+		Email   string    `json:"user_email"`
+		Total   int64     `json:"total"`
+		Pending int64     `json:"pending"`
+	}
+
+	// Package Export
+	ExportPackageRequest struct {
+		Format    string        `json:"format" form:"format"`
+		From      *time.Time    `json:"from,omitempty" form:"from"`
+		To        *time.Time    `json:"to,omitempty" form:"to"`
+		CompanyID *uuid.UUID    `json:"company_id,omitempty" form:"company_id"`
+		LockerID  *uuid.UUID    `json:"locker_id,omitempty" form:"locker_id"`
+		Status    entity.Status `json:"package_status,omitempty" form:"package_status"`
+		Type      entity.Type   `json:"package_type,omitempty" form:"package_type"`
+	}
+
+	// Package Label
+	PackageLabelRequest struct {
+		Format     string      `json:"format" form:"format"`
+		PackageIDs []uuid.UUID `json:"package_ids" form:"package_ids"`
+	}
+
+	// Pickup Delegation
+	CreatePickupDelegationRequest struct {
+		Name        string      `json:"delegate_name"`
+		PhoneNumber string      `json:"delegate_phone_number"`
+		ValidFrom   *time.Time  `json:"delegation_valid_from,omitempty"`
+		ValidUntil  time.Time   `json:"delegation_valid_until"`
+		PackageIDs  []uuid.UUID `json:"package_ids,omitempty"`
+	}
+	PickupDelegationResponse struct {
+		ID          uuid.UUID   `json:"delegation_id"`
+		Name        string      `json:"delegate_name"`
+		PhoneNumber string      `json:"delegate_phone_number"`
+		ValidFrom   time.Time   `json:"delegation_valid_from"`
+		ValidUntil  time.Time   `json:"delegation_valid_until"`
+		AllPackages bool        `json:"delegation_all_packages"`
+		PackageIDs  []uuid.UUID `json:"package_ids"`
+		RevokedAt   *time.Time  `json:"delegation_revoked_at"`
+		Active      bool        `json:"delegation_active"`
+		UserID      *uuid.UUID  `json:"user_id"`
+	}
+
 	// Company
 	CreateCompanyRequest struct {
 		Name    string `json:"company_name" binding:"required"`
 		Address string `json:"company_address" binding:"required"`
 	}
 	CompanyResponse struct {
-		ID      *uuid.UUID `json:"company_id"`
-		Name    string     `json:"company_name"`
-		Address string     `json:"company_address"`
+		ID       *uuid.UUID           `json:"company_id"`
+		Name     string               `json:"company_name"`
+		Address  string               `json:"company_address"`
+		Contacts []UserResponseCustom `json:"contacts,omitempty"`
 	}
 	CompanyPaginationResponse struct {
 		PaginationResponse
@@ -398,6 +847,10 @@ type (
 		Name    string `json:"company_name,omitempty"`
 		Address string `json:"company_address,omitempty"`
 	}
+	UpdateCompanyContactsRequest struct {
+		ID      string      `json:"-"`
+		UserIDs []uuid.UUID `json:"user_ids"`
+	}
 	UpdateCompanyResponse struct {
 		ID      *uuid.UUID `json:"company_id"`
 		Name    string     `json:"company_name"`
@@ -435,6 +888,39 @@ type (
 		Lockers []entity.Locker
 	}
 
+	// Locker Slot
+	CreateLockerSlotRequest struct {
+		LockerID uuid.UUID       `json:"locker_id"`
+		SlotCode string          `json:"slot_code"`
+		Size     entity.SlotSize `json:"slot_size"`
+		Capacity int             `json:"slot_capacity"`
+	}
+	UpdateLockerSlotRequest struct {
+		ID       string          `json:"-"`
+		SlotCode string          `json:"slot_code,omitempty"`
+		Size     entity.SlotSize `json:"slot_size,omitempty"`
+		Capacity *int            `json:"slot_capacity,omitempty"`
+	}
+	LockerSlotResponse struct {
+		ID       uuid.UUID       `json:"slot_id"`
+		SlotCode string          `json:"slot_code"`
+		Size     entity.SlotSize `json:"slot_size"`
+		Capacity int             `json:"slot_capacity"`
+		LockerID *uuid.UUID      `json:"locker_id"`
+	}
+	LockerSlotOccupancyResponse struct {
+		LockerSlotResponse
+		Used int64 `json:"slot_used"`
+		Free int64 `json:"slot_free"`
+	}
+	LockerOccupancyResponse struct {
+		Locker   LockerResponse                `json:"locker"`
+		Capacity int64                         `json:"locker_capacity"`
+		Used     int64                         `json:"locker_used"`
+		Free     int64                         `json:"locker_free"`
+		Slots    []LockerSlotOccupancyResponse `json:"slots"`
+	}
+
 	// Sender
 	CreateSenderRequest struct {
 		Name        string `json:"sender_name"`
@@ -467,4 +953,168 @@ type (
 		PaginationResponse
 		Senders []entity.Sender
 	}
+	SenderCursorResponse struct {
+		CursorResponse
+		Data []SenderResponse `json:"data"`
+	}
+	SenderCursorRepositoryResponse struct {
+		CursorResponse
+		Senders []entity.Sender
+	}
+
+	// Notification
+	NotificationResponse struct {
+		ID            uuid.UUID                 `json:"notification_id"`
+		Channels      string                    `json:"notification_channels"`
+		PhoneNumber   string                    `json:"notification_phone_number"`
+		Email         string                    `json:"notification_email"`
+		Subject       string                    `json:"notification_subject"`
+		Status        entity.NotificationStatus `json:"notification_status"`
+		Attempts      int                       `json:"notification_attempts"`
+		NextAttemptAt time.Time                 `json:"notification_next_attempt_at"`
+		LastError     string                    `json:"notification_last_error"`
+		SentAt        *time.Time                `json:"notification_sent_at"`
+		UserID        *uuid.UUID                `json:"user_id"`
+		PackageID     *uuid.UUID                `json:"package_id"`
+		entity.TimeStamp
+	}
+	NotificationPaginationResponse struct {
+		PaginationResponse
+		Data []NotificationResponse `json:"data"`
+	}
+	NotificationPaginationRepositoryResponse struct {
+		PaginationResponse
+		Notifications []entity.NotificationOutbox
+	}
+
+	// Retention Policy
+	CreateRetentionPolicyRequest struct {
+		CompanyID       *uuid.UUID  `json:"company_id,omitempty"`
+		PackageType     entity.Type `json:"retention_policy_package_type,omitempty"`
+		ExpiryDays      int         `json:"retention_policy_expiry_days"`
+		ReminderDays    []int       `json:"retention_policy_reminder_days"`
+		GracePeriodDays int         `json:"retention_policy_grace_period_days"`
+	}
+	UpdateRetentionPolicyRequest struct {
+		ID              string `json:"-"`
+		ExpiryDays      *int   `json:"retention_policy_expiry_days,omitempty"`
+		ReminderDays    []int  `json:"retention_policy_reminder_days,omitempty"`
+		GracePeriodDays *int   `json:"retention_policy_grace_period_days,omitempty"`
+	}
+	RetentionPolicyResponse struct {
+		ID              uuid.UUID        `json:"retention_policy_id"`
+		Company         *CompanyResponse `json:"company"`
+		PackageType     entity.Type      `json:"retention_policy_package_type"`
+		ExpiryDays      int              `json:"retention_policy_expiry_days"`
+		ReminderDays    []int            `json:"retention_policy_reminder_days"`
+		GracePeriodDays int              `json:"retention_policy_grace_period_days"`
+	}
+
+	// Role & Permission
+	CreateRoleRequest struct {
+		Name string `json:"role_name" binding:"required"`
+	}
+	UpdateRoleRequest struct {
+		ID   string `json:"-"`
+		Name string `json:"role_name" binding:"required"`
+	}
+	RoleDetailResponse struct {
+		ID          uuid.UUID            `json:"role_id"`
+		Name        string               `json:"role_name"`
+		UserCount   int64                `json:"role_user_count"`
+		Permissions []PermissionResponse `json:"permissions"`
+	}
+	AttachPermissionRequest struct {
+		RoleID   string `json:"-"`
+		Endpoint string `json:"permission_endpoint" binding:"required"`
+		Method   string `json:"permission_method,omitempty"`
+	}
+	PermissionResponse struct {
+		ID       uuid.UUID  `json:"permission_id"`
+		Endpoint string     `json:"permission_endpoint"`
+		Method   string     `json:"permission_method"`
+		RoleID   *uuid.UUID `json:"role_id"`
+	}
+	RouteResponse struct {
+		Method  string `json:"route_method"`
+		Path    string `json:"route_path"`
+		Granted *bool  `json:"route_granted,omitempty"`
+	}
+	AssignRoleRequest struct {
+		UserID string    `json:"-"`
+		RoleID uuid.UUID `json:"role_id" binding:"required"`
+	}
+	AssignRoleResponse struct {
+		UserID uuid.UUID    `json:"user_id"`
+		Role   RoleResponse `json:"role"`
+	}
+
+	// Audit Log
+	AuditLogFilter struct {
+		Action     string `form:"action"`
+		EntityType string `form:"entity_type"`
+		EntityID   string `form:"entity_id"`
+		ActorID    string `form:"actor_id"`
+	}
+	AuditLogResponse struct {
+		ID         uuid.UUID            `json:"audit_id"`
+		Action     string               `json:"audit_action"`
+		EntityType string               `json:"audit_entity_type"`
+		EntityID   string               `json:"audit_entity_id"`
+		Method     string               `json:"audit_method"`
+		Path       string               `json:"audit_path"`
+		StatusCode int                  `json:"audit_status_code"`
+		Before     entity.AuditSnapshot `json:"audit_before"`
+		After      entity.AuditSnapshot `json:"audit_after"`
+		Actor      *UserResponseCustom  `json:"actor"`
+		ActorRole  string               `json:"audit_actor_role"`
+		IPAddress  string               `json:"audit_ip_address"`
+		UserAgent  string               `json:"audit_user_agent"`
+		CreatedAt  time.Time            `json:"created_at"`
+	}
+	AuditLogPaginationResponse struct {
+		PaginationResponse
+		Data []AuditLogResponse `json:"data"`
+	}
+	AuditLogPaginationRepositoryResponse struct {
+		PaginationResponse
+		AuditLogs []entity.AuditLog
+	}
+
+	// Search
+	SearchRequest struct {
+		Query string   `json:"q" form:"q"`
+		Types []string `json:"types,omitempty" form:"types"`
+		Limit int      `json:"limit,omitempty" form:"limit"`
+	}
+	SearchResult struct {
+		Type     string    `json:"type"`
+		ID       uuid.UUID `json:"id"`
+		Title    string    `json:"title"`
+		Subtitle string    `json:"subtitle"`
+		Rank     float64   `json:"rank"`
+	}
+	SearchResponse struct {
+		Query   string         `json:"query"`
+		Results []SearchResult `json:"results"`
+	}
+	// SearchQuery is a search prepared for the repository: TSQuery is the
+	// to_tsquery input, Pattern and PhonePattern are escaped ILIKE patterns.
+	SearchQuery struct {
+		Term         string
+		TSQuery      string
+		Pattern      string
+		PhonePattern string
+		Limit        int
+	}
 )
+
+// LockerFullError is returned when no slot of the requested locker fits the
+// package, Alternatives lists lockers that still have a fitting slot.
+type LockerFullError struct {
+	Alternatives []LockerResponse
+}
+
+func (e *LockerFullError) Error() string {
+	return "failed locker is full"
+}
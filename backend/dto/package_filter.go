@@ -0,0 +1,111 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/google/uuid"
+)
+
+// PackageSortColumns whitelists the fields a package list can be sorted by
+// and the column behind each of them.
+var PackageSortColumns = map[string]string{
+	"received_at":   "packages.created_at",
+	"updated_at":    "packages.updated_at",
+	"completed_at":  "packages.completed_at",
+	"expired_at":    "packages.expired_at",
+	"tracking_code": "packages.tracking_code",
+	"status":        "packages.status",
+	"type":          "packages.type",
+	"quantity":      "packages.quantity",
+	"size":          "packages.size",
+}
+
+type (
+	// PackageFilter narrows and orders a package list, it is shared by the
+	// admin and user package lists. Every upper date bound is exclusive.
+	PackageFilter struct {
+		UserID        *uuid.UUID
+		CompanyID     *uuid.UUID
+		LockerID      *uuid.UUID
+		SenderID      *uuid.UUID
+		Statuses      []entity.Status
+		Type          entity.Type
+		ReceivedFrom  *time.Time
+		ReceivedTo    *time.Time
+		CompletedFrom *time.Time
+		CompletedTo   *time.Time
+		ExpiredFrom   *time.Time
+		ExpiredTo     *time.Time
+		MinQuantity   *int
+		MaxQuantity   *int
+		Sort          []SortField
+	}
+
+	SortField struct {
+		Column string
+		Desc   bool
+	}
+)
+
+// ParsePackageSort reads a sort like `status:asc,received_at:desc`, the
+// direction defaults to ascending. Only PackageSortColumns fields are allowed.
+func ParsePackageSort(sort string) ([]SortField, error) {
+	var fields []SortField
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, direction, _ := strings.Cut(part, ":")
+		column, ok := PackageSortColumns[strings.ToLower(name)]
+		if !ok {
+			return nil, ErrInvalidPackageSort
+		}
+
+		field := SortField{Column: column}
+		switch strings.ToLower(direction) {
+		case "", "asc":
+		case "desc":
+			field.Desc = true
+		default:
+			return nil, ErrInvalidPackageSort
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// Validate checks the values the query string could not.
+func (f PackageFilter) Validate() error {
+	for _, status := range f.Statuses {
+		if !entity.IsValidStatus(status) {
+			return ErrInvalidPackageStatus
+		}
+	}
+
+	if f.Type != "" && !entity.IsValidType(f.Type) {
+		return ErrInvalidPackageType
+	}
+
+	ranges := [][2]*time.Time{
+		{f.ReceivedFrom, f.ReceivedTo},
+		{f.CompletedFrom, f.CompletedTo},
+		{f.ExpiredFrom, f.ExpiredTo},
+	}
+	for _, r := range ranges {
+		if r[0] != nil && r[1] != nil && !r[0].Before(*r[1]) {
+			return ErrInvalidDateRange
+		}
+	}
+
+	if f.MinQuantity != nil && f.MaxQuantity != nil && *f.MinQuantity > *f.MaxQuantity {
+		return ErrInvalidQuantityRange
+	}
+
+	return nil
+}
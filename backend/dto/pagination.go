@@ -1,5 +1,13 @@
 package dto
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type (
 	PaginationRequest struct {
 		Search  string `form:"search"`
@@ -13,6 +21,28 @@ type (
 		MaxPage int64 `json:"max_page"`
 		Count   int64 `json:"count"`
 	}
+
+	// CursorRequest asks for a keyset page, used with pagination=cursor. An
+	// empty Cursor starts at the newest row.
+	CursorRequest struct {
+		Search  string `form:"search"`
+		Cursor  string `form:"cursor"`
+		PerPage int    `form:"per_page"`
+	}
+
+	CursorResponse struct {
+		PerPage    int    `json:"per_page"`
+		NextCursor string `json:"next_cursor,omitempty"`
+		PrevCursor string `json:"prev_cursor,omitempty"`
+	}
+
+	// Cursor is the row a keyset page continues from, clients only ever see
+	// it encoded. Backward pages towards newer rows.
+	Cursor struct {
+		CreatedAt time.Time `json:"t"`
+		ID        uuid.UUID `json:"id"`
+		Backward  bool      `json:"b,omitempty"`
+	}
 )
 
 func (p *PaginationRequest) GetOffset() int {
@@ -26,3 +56,33 @@ func (pr *PaginationResponse) GetLimit() int {
 func (pr *PaginationResponse) GetPage() int {
 	return pr.Page
 }
+
+// Validate rejects a cursor that was not handed out by CursorResponse.
+func (cr CursorRequest) Validate() error {
+	_, err := DecodeCursor(cr.Cursor)
+	return err
+}
+
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor reads an encoded Cursor, it is nil for an empty string.
+func DecodeCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == uuid.Nil || c.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// AuditSnapshot holds the columns of an audited row, stored as a JSON object.
+type AuditSnapshot map[string]any
+
+// AuditLog records one successful mutating admin request: who made it, which
+// row it touched and how that row looked before and after.
+type AuditLog struct {
+	ID         uuid.UUID     `gorm:"type:uuid;primaryKey" json:"audit_id"`
+	Action     string        `gorm:"type:varchar(50);not null;index" json:"audit_action"`
+	EntityType string        `gorm:"type:varchar(50);index" json:"audit_entity_type"`
+	EntityID   string        `gorm:"type:varchar(64);index" json:"audit_entity_id"`
+	Method     string        `gorm:"type:varchar(10);not null" json:"audit_method"`
+	Path       string        `gorm:"type:varchar(255);not null" json:"audit_path"`
+	StatusCode int           `gorm:"not null" json:"audit_status_code"`
+	Before     AuditSnapshot `gorm:"type:jsonb" json:"audit_before"`
+	After      AuditSnapshot `gorm:"type:jsonb" json:"audit_after"`
+	ActorID    *uuid.UUID    `gorm:"type:uuid;index" json:"actor_id"`
+	Actor      User          `gorm:"foreignKey:ActorID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	ActorRole  string        `gorm:"type:varchar(50)" json:"audit_actor_role"`
+	IPAddress  string        `gorm:"type:varchar(45)" json:"audit_ip_address"`
+	UserAgent  string        `gorm:"type:text" json:"audit_user_agent"`
+
+	TimeStamp
+}
+
+func (s AuditSnapshot) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (s *AuditSnapshot) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return fmt.Errorf("unsupported audit snapshot type %T", value)
+	}
+}
@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,8 +15,18 @@ type (
 		DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 	}
 
-	Type   string
-	Status string
+	Type                string
+	Status              string
+	NotificationChannel string
+	NotificationStatus  string
+	SlotSize            string
+
+	// StatusTransitionError is returned when a package is asked to move between
+	// two statuses that are not connected in the transition table.
+	StatusTransitionError struct {
+		From Status
+		To   Status
+	}
 )
 
 const (
@@ -26,8 +38,40 @@ const (
 	Completed Status = "completed"
 	Expired   Status = "expired"
 	Deleted   Status = "deleted"
+
+	WhatsAppChannel NotificationChannel = "whatsapp"
+	EmailChannel    NotificationChannel = "email"
+
+	NotificationPending   NotificationStatus = "pending"
+	NotificationSending   NotificationStatus = "sending"
+	NotificationSent      NotificationStatus = "sent"
+	NotificationFailed    NotificationStatus = "failed"
+	NotificationDead      NotificationStatus = "dead"
+	NotificationCancelled NotificationStatus = "cancelled"
+
+	SmallSlot  SlotSize = "small"
+	MediumSlot SlotSize = "medium"
+	LargeSlot  SlotSize = "large"
 )
 
+var slotSizeRank = map[SlotSize]int{
+	SmallSlot:  1,
+	MediumSlot: 2,
+	LargeSlot:  3,
+}
+
+// OccupyingStatuses are the package statuses that still take up a locker slot.
+var OccupyingStatuses = []Status{Received, Expired}
+
+// statusTransitions is the single source of truth for the package lifecycle.
+// An expired package can go back to received when the owner appeals.
+var statusTransitions = map[Status][]Status{
+	Received:  {Completed, Expired},
+	Expired:   {Deleted, Received},
+	Completed: {},
+	Deleted:   {},
+}
+
 func IsValidType(t Type) bool {
 	return t == Document || t == Item || t == Other
 }
@@ -35,3 +79,61 @@ func IsValidType(t Type) bool {
 func IsValidStatus(s Status) bool {
 	return s == Received || s == Completed || s == Expired || s == Deleted
 }
+
+func IsValidSlotSize(s SlotSize) bool {
+	_, ok := slotSizeRank[s]
+	return ok
+}
+
+func SlotSizeRank(s SlotSize) int {
+	return slotSizeRank[s]
+}
+
+func IsValidNotificationChannel(c NotificationChannel) bool {
+	return c == WhatsAppChannel || c == EmailChannel
+}
+
+// ParseNotificationChannels reads a comma separated channel list and falls back
+// to whatsapp when nothing valid is left.
+func ParseNotificationChannels(value string) []NotificationChannel {
+	var channels []NotificationChannel
+	for _, c := range strings.Split(value, ",") {
+		channel := NotificationChannel(strings.TrimSpace(c))
+		if IsValidNotificationChannel(channel) {
+			channels = append(channels, channel)
+		}
+	}
+
+	if len(channels) == 0 {
+		channels = []NotificationChannel{WhatsAppChannel}
+	}
+
+	return channels
+}
+
+func IsValidNotificationStatus(s NotificationStatus) bool {
+	return s == NotificationPending || s == NotificationSending || s == NotificationSent || s == NotificationFailed ||
+		s == NotificationDead || s == NotificationCancelled
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("failed invalid package status transition from %s to %s", e.From, e.To)
+}
+
+func CanTransitionStatus(from, to Status) bool {
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ValidateStatusTransition(from, to Status) error {
+	if !IsValidStatus(to) || !CanTransitionStatus(from, to) {
+		return &StatusTransitionError{From: from, To: to}
+	}
+
+	return nil
+}
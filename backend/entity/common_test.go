@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateStatusTransition(t *testing.T) {
+	tests := []struct {
+		from Status
+		to   Status
+		ok   bool
+	}{
+		{Received, Completed, true},
+		{Received, Expired, true},
+		{Received, Deleted, false},
+		{Received, Received, false},
+		{Expired, Deleted, true},
+		{Expired, Received, true},
+		{Expired, Completed, false},
+		{Completed, Received, false},
+		{Completed, Expired, false},
+		{Completed, Deleted, false},
+		{Deleted, Received, false},
+		{Deleted, Expired, false},
+		{Received, "lost", false},
+		{"", Received, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			err := ValidateStatusTransition(tt.from, tt.to)
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("ValidateStatusTransition() = %v, want nil", err)
+				}
+				return
+			}
+
+			var transitionErr *StatusTransitionError
+			if !errors.As(err, &transitionErr) {
+				t.Fatalf("ValidateStatusTransition() = %v, want a StatusTransitionError", err)
+			}
+			if transitionErr.From != tt.from || transitionErr.To != tt.to {
+				t.Fatalf("transition error = %s -> %s, want %s -> %s", transitionErr.From, transitionErr.To, tt.from, tt.to)
+			}
+		})
+	}
+}
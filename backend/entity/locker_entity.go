@@ -7,7 +7,8 @@ type Locker struct {
 	LockerCode string    `gorm:"not null" json:"locker_code"`
 	Location   string    `gorm:"not null" json:"location"`
 
-	Packages []Package `gorm:"foreignKey:LockerID"`
+	Packages []Package    `gorm:"foreignKey:LockerID"`
+	Slots    []LockerSlot `gorm:"foreignKey:LockerID"`
 
 	TimeStamp
 }
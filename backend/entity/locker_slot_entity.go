@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LockerSlot struct {
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"slot_id"`
+	SlotCode string    `gorm:"not null;uniqueIndex:idx_locker_slot_code" json:"slot_code"`
+	Size     SlotSize  `gorm:"type:varchar(10);not null" json:"slot_size"`
+	Capacity int       `gorm:"not null;default:1" json:"slot_capacity"`
+
+	LockerID *uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_locker_slot_code" json:"locker_id"`
+	Locker   Locker     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	Packages []Package `gorm:"foreignKey:SlotID"`
+
+	TimeStamp
+}
+
+func (s *LockerSlot) BeforeCreate(tx *gorm.DB) error {
+	if !IsValidSlotSize(s.Size) || s.Capacity <= 0 {
+		return errors.New("invalid slot size or capacity")
+	}
+	return nil
+}
+
+// Fits reports whether a package of the given size can go into the slot.
+func (s *LockerSlot) Fits(size SlotSize) bool {
+	return slotSizeRank[s.Size] >= slotSizeRank[size]
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+)
+
+const (
+	LoginAttemptSuccess          = "success"
+	LoginAttemptEmailNotFound    = "email_not_found"
+	LoginAttemptPasswordNotMatch = "password_not_match"
+	LoginAttemptDeniedAccess     = "denied_access"
+	LoginAttemptEmailNotVerified = "email_not_verified"
+	LoginAttemptLocked           = "locked"
+	LoginAttemptIPBlocked        = "ip_blocked"
+)
+
+// LoginAttempt records every password login, successful or not, for auditing
+// and for the per IP limit. UserID is empty when the email is unknown.
+type LoginAttempt struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"login_attempt_id"`
+	Email     string    `gorm:"type:varchar(255);not null;index" json:"login_attempt_email"`
+	Success   bool      `gorm:"not null" json:"login_attempt_success"`
+	Reason    string    `gorm:"type:varchar(30);not null" json:"login_attempt_reason"`
+	IPAddress string    `gorm:"type:varchar(45);index" json:"login_attempt_ip_address"`
+	UserAgent string    `gorm:"type:text" json:"login_attempt_user_agent"`
+
+	UserID *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	User   User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+
+	TimeStamp
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginOTP is a one-time code sent over WhatsApp for passwordless login, only
+// the sha256 of the code is stored. ConsumedAt is set once it logged someone
+// in, ran out of attempts or was replaced by a newer code.
+type LoginOTP struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"otp_id"`
+	PhoneNumber string     `gorm:"type:varchar(20);not null;index" json:"otp_phone_number"`
+	CodeHash    string     `gorm:"type:char(64);not null" json:"-"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"otp_expires_at"`
+	Attempts    int        `gorm:"not null;default:0" json:"otp_attempts"`
+	ConsumedAt  *time.Time `json:"otp_consumed_at"`
+	IPAddress   string     `gorm:"type:varchar(45);index" json:"otp_ip_address"`
+
+	UserID *uuid.UUID `gorm:"type:uuid" json:"user_id"`
+	User   User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	TimeStamp
+}
+
+func (o *LoginOTP) IsUsable(now time.Time) bool {
+	return o.ConsumedAt == nil && now.Before(o.ExpiresAt)
+}
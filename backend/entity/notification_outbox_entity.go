@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationOutbox struct {
+	ID            uuid.UUID          `gorm:"type:uuid;primaryKey" json:"notification_id"`
+	Channels      string             `gorm:"type:varchar(50);not null" json:"notification_channels"`
+	PhoneNumber   string             `gorm:"type:varchar(20)" json:"notification_phone_number"`
+	Email         string             `gorm:"type:varchar(100)" json:"notification_email"`
+	Subject       string             `gorm:"type:varchar(255)" json:"notification_subject"`
+	Body          string             `gorm:"type:text;not null" json:"notification_body"`
+	ImagePath     string             `gorm:"type:text" json:"notification_image_path"`
+	Status        NotificationStatus `gorm:"type:varchar(20);not null;index" json:"notification_status"`
+	Attempts      int                `gorm:"not null;default:0" json:"notification_attempts"`
+	NextAttemptAt time.Time          `gorm:"index" json:"notification_next_attempt_at"`
+	LeaseUntil    *time.Time         `gorm:"index" json:"notification_lease_until"`
+	LastError     string             `gorm:"type:text" json:"notification_last_error"`
+	SentAt        *time.Time         `json:"notification_sent_at"`
+
+	UserID *uuid.UUID `gorm:"type:uuid" json:"user_id"`
+	User   User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+
+	PackageID *uuid.UUID `gorm:"type:uuid" json:"package_id"`
+	Package   Package    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+
+	TimeStamp
+}
+
+func (NotificationOutbox) TableName() string {
+	return "notification_outbox"
+}
+
+func (n *NotificationOutbox) GetChannels() []NotificationChannel {
+	return ParseNotificationChannels(n.Channels)
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/Amierza/TitipanQ/backend/helpers"
@@ -21,15 +22,26 @@ type Package struct {
 	ExpiredAt          *time.Time `json:"package_expired_at"`
 	LastReminderSentAt *time.Time `json:"package_last_reminder_sent_at"`
 	ProofImage         *string    `gorm:"type:text" json:"package_proof_Image"`
+	Size               SlotSize   `gorm:"type:varchar(10);not null;default:'small'" json:"package_size"`
+	PickupCode         string     `gorm:"type:text" json:"-"`
+	PickupAttempts     int        `gorm:"not null;default:0" json:"package_pickup_attempts"`
 
 	PackageHistories []PackageHistory `gorm:"foreignKey:PackageID"`
 
 	UserID *uuid.UUID `gorm:"type:uuid" json:"user_id"`
 	User   User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 
+	// CompanyID addresses the package to a whole company instead of UserID,
+	// every member of the company sees it and may collect it.
+	CompanyID *uuid.UUID `gorm:"type:uuid" json:"company_id"`
+	Company   Company    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+
 	LockerID *uuid.UUID `gorm:"type:uuid" json:"locker_id"`
 	Locker   Locker     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 
+	SlotID *uuid.UUID `gorm:"type:uuid" json:"slot_id"`
+	Slot   LockerSlot `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+
 	SenderID *uuid.UUID `gorm:"type:uuid" json:"sender_id"`
 	Sender   Sender     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 
@@ -37,10 +49,41 @@ type Package struct {
 }
 
 func (p *Package) BeforeCreate(tx *gorm.DB) error {
-	p.ExpiredAt = helpers.PtrTime(time.Now().AddDate(0, 3, 0))
+	// callers set the expiry from the retention policy, seeds fall back to the default
+	if p.ExpiredAt == nil {
+		p.ExpiredAt = helpers.PtrTime(time.Now().AddDate(0, 0, DefaultExpiryDays))
+	}
 
 	if !IsValidType(p.Type) || !IsValidStatus(p.Status) {
 		return errors.New("invalid type or status")
 	}
 	return nil
 }
+
+func (p *Package) BeforeUpdate(tx *gorm.DB) error {
+	next := p.Status
+	if values, ok := tx.Statement.Dest.(map[string]interface{}); ok {
+		if status, ok := values["status"]; ok {
+			next = Status(fmt.Sprint(status))
+		}
+	}
+
+	if next == "" || p.ID == uuid.Nil {
+		return nil
+	}
+
+	var current Package
+	if err := tx.Session(&gorm.Session{NewDB: true}).
+		Unscoped().
+		Select("status").
+		Where("id = ?", p.ID).
+		Take(&current).Error; err != nil {
+		return err
+	}
+
+	if current.Status == next {
+		return nil
+	}
+
+	return ValidateStatusTransition(current.Status, next)
+}
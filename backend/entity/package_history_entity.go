@@ -1,20 +1,61 @@
 package entity
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+type (
+	HistoryEventType string
+
+	// FieldChange records the old and new value of one package field.
+	FieldChange struct {
+		Field string `json:"field"`
+		Old   any    `json:"old"`
+		New   any    `json:"new"`
+	}
+
+	// HistoryChanges is stored as a JSON array in the history row.
+	HistoryChanges []FieldChange
+)
+
+const (
+	HistoryReceived              HistoryEventType = "package_received"
+	HistoryUpdated               HistoryEventType = "package_updated"
+	HistoryCompleted             HistoryEventType = "package_completed"
+	HistoryExpired               HistoryEventType = "package_expired"
+	HistoryAppealed              HistoryEventType = "package_appealed"
+	HistoryDeleted               HistoryEventType = "package_deleted"
+	HistoryReminderSent          HistoryEventType = "reminder_sent"
+	HistoryPickupCodeRejected    HistoryEventType = "pickup_code_rejected"
+	HistoryPickupCodeRegenerated HistoryEventType = "pickup_code_regenerated"
+
+	// SystemActor is the actor role of entries written by scheduled jobs.
+	SystemActor = "system"
+)
+
 type PackageHistory struct {
-	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"history_id"`
-	Status        Status     `gorm:"type:varchar(20);not null" json:"history_status"`
-	Description   string     `gorm:"type:varchar(255);not null" json:"history_description"`
-	PackageID     *uuid.UUID `gorm:"type:uuid" json:"package_id"`
-	Package       Package    `gorm:"foreignKey:PackageID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
-	ChangedBy     *uuid.UUID `gorm:"type:uuid" json:"changed_by"`
-	ChangedByUser User       `gorm:"foreignKey:ChangedBy;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	ID            uuid.UUID        `gorm:"type:uuid;primaryKey" json:"history_id"`
+	EventType     HistoryEventType `gorm:"type:varchar(30);not null;default:'package_updated';index" json:"history_event_type"`
+	Status        Status           `gorm:"type:varchar(20);not null" json:"history_status"`
+	Description   string           `gorm:"type:text;not null" json:"history_description"`
+	Changes       HistoryChanges   `gorm:"type:jsonb" json:"history_changes"`
+	PackageID     *uuid.UUID       `gorm:"type:uuid" json:"package_id"`
+	Package       Package          `gorm:"foreignKey:PackageID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	ChangedBy     *uuid.UUID       `gorm:"type:uuid" json:"changed_by"`
+	ChangedByUser User             `gorm:"foreignKey:ChangedBy;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+	ActorRole     string           `gorm:"type:varchar(50)" json:"history_actor_role"`
+	IPAddress     string           `gorm:"type:varchar(45)" json:"history_ip_address"`
+	UserAgent     string           `gorm:"type:text" json:"history_user_agent"`
+	// CollectedBy names whoever picked the package up when it was not the owner
+	CollectedBy  string           `gorm:"type:varchar(100)" json:"history_collected_by"`
+	DelegationID *uuid.UUID       `gorm:"type:uuid" json:"delegation_id"`
+	Delegation   PickupDelegation `gorm:"foreignKey:DelegationID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 
 	TimeStamp
 }
@@ -23,5 +64,35 @@ func (p *PackageHistory) BeforeCreate(tx *gorm.DB) error {
 	if !IsValidStatus(p.Status) {
 		return errors.New("invalid status")
 	}
+	if p.EventType == "" {
+		p.EventType = HistoryUpdated
+	}
 	return nil
 }
+
+func (c HistoryChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (c *HistoryChanges) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return fmt.Errorf("unsupported history changes type %T", value)
+	}
+}
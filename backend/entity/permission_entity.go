@@ -2,9 +2,17 @@ package entity
 
 import "github.com/google/uuid"
 
+// PermissionMethodAny lets a permission match every HTTP method.
+const PermissionMethodAny = "*"
+
+// Permission grants a role access to the routes matching Endpoint for Method.
+// Endpoint is a gin route pattern, a "*" segment matches any single segment
+// (or part of one, e.g. "*-package") and a trailing "/**" matches everything
+// below the prefix, so "/api/v1/admin/**" grants a whole API.
 type Permission struct {
 	ID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"permission_id"`
 	Endpoint string    `gorm:"not null" json:"permission_endpoint"`
+	Method   string    `gorm:"type:varchar(10);not null;default:'*'" json:"permission_method"`
 
 	RoleID *uuid.UUID `gorm:"type:uuid" json:"role_id"`
 	Role   Role       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PickupDelegation lets an owner authorize someone else to collect packages
+// within a validity window. A delegation with AllPackages covers every package
+// of the owner, otherwise only the linked ones.
+type PickupDelegation struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"delegation_id"`
+	Name        string     `gorm:"type:varchar(100);not null" json:"delegate_name"`
+	PhoneNumber string     `gorm:"type:varchar(20);not null" json:"delegate_phone_number"`
+	ValidFrom   time.Time  `gorm:"not null" json:"delegation_valid_from"`
+	ValidUntil  time.Time  `gorm:"not null;index" json:"delegation_valid_until"`
+	AllPackages bool       `gorm:"not null;default:false" json:"delegation_all_packages"`
+	RevokedAt   *time.Time `json:"delegation_revoked_at"`
+
+	UserID *uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	User   User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	Packages []Package `gorm:"many2many:pickup_delegation_packages;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	TimeStamp
+}
+
+func (d *PickupDelegation) IsActive(now time.Time) bool {
+	return d.RevokedAt == nil && !now.Before(d.ValidFrom) && !now.After(d.ValidUntil)
+}
+
+func (d *PickupDelegation) Covers(pkgID uuid.UUID) bool {
+	if d.AllPackages {
+		return true
+	}
+
+	for _, pkg := range d.Packages {
+		if pkg.ID == pkgID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultExpiryDays      = 90
+	DefaultReminderDays    = "60,30"
+	DefaultGracePeriodDays = 14
+)
+
+// RetentionPolicy decides how long a package is kept. A policy without a
+// company applies globally and a policy without a package type applies to
+// every type; the most specific match wins.
+type RetentionPolicy struct {
+	ID uuid.UUID `gorm:"type:uuid;primaryKey" json:"retention_policy_id"`
+
+	CompanyID *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_retention_policy_scope" json:"company_id"`
+	Company   Company    `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	// company_id is nullable, so global policies need their own partial index
+	// to stay unique per package type
+	PackageType Type `gorm:"type:varchar(20);not null;default:'';uniqueIndex:idx_retention_policy_scope;uniqueIndex:idx_retention_policy_global,where:company_id IS NULL" json:"retention_policy_package_type"`
+
+	ExpiryDays int `gorm:"not null" json:"retention_policy_expiry_days"`
+	// comma separated list of days before expiry, e.g. "60,30"
+	ReminderDays    string `gorm:"type:varchar(100);not null;default:''" json:"retention_policy_reminder_days"`
+	GracePeriodDays int    `gorm:"not null;default:0" json:"retention_policy_grace_period_days"`
+
+	TimeStamp
+}
+
+func DefaultRetentionPolicy() RetentionPolicy {
+	return RetentionPolicy{
+		ExpiryDays:      DefaultExpiryDays,
+		ReminderDays:    DefaultReminderDays,
+		GracePeriodDays: DefaultGracePeriodDays,
+	}
+}
+
+// GetReminderDays returns the reminder offsets, furthest from expiry first.
+func (rp *RetentionPolicy) GetReminderDays() []int {
+	var days []int
+	for _, d := range strings.Split(rp.ReminderDays, ",") {
+		day, err := strconv.Atoi(strings.TrimSpace(d))
+		if err != nil || day <= 0 {
+			continue
+		}
+		days = append(days, day)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(days)))
+
+	return days
+}
@@ -1,9 +1,26 @@
 package entity
 
 import (
+	"strings"
+
 	"github.com/google/uuid"
 )
 
+// Built-in roles, login checks them by name so they cannot be renamed or
+// deleted. RoleAdmin is the super admin seeing every company, every other
+// role that reaches the admin API only sees the companies it belongs to.
+const (
+	RoleAdmin        = "admin"
+	RoleCompanyAdmin = "company_admin"
+	RoleUser         = "user"
+)
+
+// The APIs a role can sign in to.
+const (
+	AdminAPIPrefix = "/api/v1/admin"
+	UserAPIPrefix  = "/api/v1/user"
+)
+
 type Role struct {
 	ID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"role_id"`
 	Name string    `gorm:"not null" json:"role_name"`
@@ -13,3 +30,23 @@ type Role struct {
 
 	TimeStamp
 }
+
+// CanSignInTo tells whether the role may sign in to the API under prefix. The
+// built-in roles keep their own API, a custom role may sign in wherever it
+// holds a permission, so Permissions must be loaded.
+func (r Role) CanSignInTo(prefix string) bool {
+	switch r.Name {
+	case RoleAdmin, RoleCompanyAdmin:
+		return prefix == AdminAPIPrefix
+	case RoleUser:
+		return prefix == UserAPIPrefix
+	}
+
+	for _, permission := range r.Permissions {
+		if permission.Endpoint == prefix || strings.HasPrefix(permission.Endpoint, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
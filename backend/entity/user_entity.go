@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/Amierza/TitipanQ/backend/helpers"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -14,6 +16,16 @@ type User struct {
 	PhoneNumber string    `gorm:"not null" json:"user_phone_number"`
 	Address     string    `gorm:"type:text" json:"user_address"`
 
+	EmailVerifiedAt *time.Time `json:"user_email_verified_at"`
+
+	// wrong passwords since the last successful login, LockedUntil delays the
+	// next attempt once there are too many of them
+	FailedLoginAttempts int        `gorm:"not null;default:0" json:"user_failed_login_attempts"`
+	LockedUntil         *time.Time `json:"user_locked_until"`
+
+	// comma separated list of channels, e.g. "whatsapp,email"
+	NotificationChannels string `gorm:"type:varchar(50);not null;default:'whatsapp'" json:"user_notification_channels"`
+
 	Packages         []Package        `gorm:"foreignKey:UserID"`
 	PackageHistories []PackageHistory `gorm:"foreignKey:ChangedBy"`
 
@@ -40,3 +52,7 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 
 	return nil
 }
+
+func (u *User) GetNotificationChannels() []NotificationChannel {
+	return ParseNotificationChannels(u.NotificationChannels)
+}
@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SessionRevokedLogout      = "logout"
+	SessionRevokedLogoutAll   = "logout_all"
+	SessionRevokedTokenReuse  = "refresh_token_reused"
+	SessionRevokedUserDeleted = "user_deleted"
+	SessionRevokedRoleChanged = "role_changed"
+	SessionRevokedPassword    = "password_reset"
+)
+
+// UserSession backs one login. Access and refresh tokens carry the session id,
+// RefreshID is the id of the only refresh token that may still be exchanged,
+// a rotated one coming back means it leaked and the session is revoked.
+type UserSession struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"session_id"`
+	RefreshID     uuid.UUID  `gorm:"type:uuid;not null" json:"-"`
+	ExpiresAt     time.Time  `gorm:"not null;index" json:"session_expires_at"`
+	LastUsedAt    time.Time  `gorm:"not null" json:"session_last_used_at"`
+	RevokedAt     *time.Time `gorm:"index" json:"session_revoked_at"`
+	RevokedReason string     `gorm:"type:varchar(30)" json:"session_revoked_reason"`
+	IPAddress     string     `gorm:"type:varchar(45)" json:"session_ip_address"`
+	UserAgent     string     `gorm:"type:text" json:"session_user_agent"`
+
+	UserID *uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	User   User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	TimeStamp
+}
+
+func (s *UserSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
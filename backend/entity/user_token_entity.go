@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TokenPurpose string
+
+const (
+	EmailVerificationToken TokenPurpose = "email_verification"
+	PasswordResetToken     TokenPurpose = "password_reset"
+)
+
+// UserToken is a single-use token mailed to a user, only the sha256 of the
+// token is stored. UsedAt is set once it is redeemed or superseded.
+type UserToken struct {
+	ID        uuid.UUID    `gorm:"type:uuid;primaryKey" json:"token_id"`
+	Purpose   TokenPurpose `gorm:"type:varchar(30);not null;index" json:"token_purpose"`
+	TokenHash string       `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time    `gorm:"not null" json:"token_expires_at"`
+	UsedAt    *time.Time   `json:"token_used_at"`
+
+	UserID *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	User   User       `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	TimeStamp
+}
+
+func (t *UserToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -1,11 +1,17 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Amierza/TitipanQ/backend/dto"
 	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/Amierza/TitipanQ/backend/internal/label"
+	"github.com/Amierza/TitipanQ/backend/internal/report"
 	"github.com/Amierza/TitipanQ/backend/service"
 	"github.com/Amierza/TitipanQ/backend/utils"
 	"github.com/gin-gonic/gin"
@@ -17,6 +23,8 @@ type (
 		// Authentication
 		Login(ctx *gin.Context)
 		RefreshToken(ctx *gin.Context)
+		Logout(ctx *gin.Context)
+		LogoutAll(ctx *gin.Context)
 
 		// User
 		CreateUser(ctx *gin.Context)
@@ -24,15 +32,22 @@ type (
 		GetDetailUser(ctx *gin.Context)
 		UpdateUser(ctx *gin.Context)
 		DeleteUser(ctx *gin.Context)
+		UnlockUser(ctx *gin.Context)
 
 		// Package & Package History & Company
 		CreatePackage(ctx *gin.Context)
+		ImportPackages(ctx *gin.Context)
+		ExportPackages(ctx *gin.Context)
+		ExportPackageHistories(ctx *gin.Context)
+		PrintPackageLabels(ctx *gin.Context)
 		ReadAllPackage(ctx *gin.Context)
 		GetDetailPackage(ctx *gin.Context)
 		GetAllPackageHistory(ctx *gin.Context)
 		UpdatePackage(ctx *gin.Context)
 		UpdateStatusPackages(ctx *gin.Context)
 		DeletePackage(ctx *gin.Context)
+		RegeneratePickupCode(ctx *gin.Context)
+		ReadAllActivePickupDelegation(ctx *gin.Context)
 
 		// Cron
 		TriggerExpire(ctx *gin.Context)
@@ -42,6 +57,7 @@ type (
 		ReadAllCompany(ctx *gin.Context)
 		GetDetailCompany(ctx *gin.Context)
 		UpdateCompany(ctx *gin.Context)
+		UpdateCompanyContacts(ctx *gin.Context)
 		DeleteCompany(ctx *gin.Context)
 
 		//Locker
@@ -50,6 +66,13 @@ type (
 		GetDetailLocker(ctx *gin.Context)
 		UpdateLocker(ctx *gin.Context)
 		DeleteLocker(ctx *gin.Context)
+		GetLockerOccupancy(ctx *gin.Context)
+
+		// Locker Slot
+		CreateLockerSlot(ctx *gin.Context)
+		ReadAllLockerSlot(ctx *gin.Context)
+		UpdateLockerSlot(ctx *gin.Context)
+		DeleteLockerSlot(ctx *gin.Context)
 
 		// Sender
 		CreateSender(ctx *gin.Context)
@@ -57,16 +80,55 @@ type (
 		GetDetailSender(ctx *gin.Context)
 		UpdateSender(ctx *gin.Context)
 		DeleteSender(ctx *gin.Context)
+
+		// Notification
+		ReadAllNotification(ctx *gin.Context)
+		RetryNotification(ctx *gin.Context)
+		CancelNotification(ctx *gin.Context)
+
+		// Stats
+		GetPackageStats(ctx *gin.Context)
+		GetPackageReceivedStats(ctx *gin.Context)
+		GetTopRecipients(ctx *gin.Context)
+
+		// Retention Policy
+		CreateRetentionPolicy(ctx *gin.Context)
+		ReadAllRetentionPolicy(ctx *gin.Context)
+		GetDetailRetentionPolicy(ctx *gin.Context)
+		UpdateRetentionPolicy(ctx *gin.Context)
+		DeleteRetentionPolicy(ctx *gin.Context)
+
+		// Role & Permission
+		CreateRole(ctx *gin.Context)
+		ReadAllRole(ctx *gin.Context)
+		GetDetailRole(ctx *gin.Context)
+		UpdateRole(ctx *gin.Context)
+		DeleteRole(ctx *gin.Context)
+		ReadAllRoute(ctx *gin.Context)
+		AttachPermission(ctx *gin.Context)
+		DetachPermission(ctx *gin.Context)
+		AssignRole(ctx *gin.Context)
+
+		// Audit Log
+		ReadAllAuditLog(ctx *gin.Context)
+		GetDetailAuditLog(ctx *gin.Context)
+
+		// Search
+		Search(ctx *gin.Context)
 	}
 
 	AdminHandler struct {
-		adminService service.IAdminService
+		adminService  service.IAdminService
+		auditService  service.IAuditService
+		searchService service.ISearchService
 	}
 )
 
-func NewAdminHandler(adminService service.IAdminService) *AdminHandler {
+func NewAdminHandler(adminService service.IAdminService, auditService service.IAuditService, searchService service.ISearchService) *AdminHandler {
 	return &AdminHandler{
-		adminService: adminService,
+		adminService:  adminService,
+		auditService:  auditService,
+		searchService: searchService,
 	}
 }
 
@@ -82,6 +144,11 @@ func (ah *AdminHandler) Login(ctx *gin.Context) {
 	result, err := ah.adminService.Login(ctx, payload)
 	if err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_LOGIN_USER, err.Error(), nil)
+		if errors.Is(err, dto.ErrAccountLocked) || errors.Is(err, dto.ErrTooManyLoginAttempts) {
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, res)
+			return
+		}
+
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
 		return
 	}
@@ -100,6 +167,11 @@ func (ah *AdminHandler) RefreshToken(ctx *gin.Context) {
 	result, err := ah.adminService.RefreshToken(ctx, payload)
 	if err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REFRESH_TOKEN, err.Error(), nil)
+		if errors.Is(err, dto.ErrSessionNotFound) || errors.Is(err, dto.ErrSessionRevoked) || errors.Is(err, dto.ErrRefreshTokenReused) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			return
+		}
+
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
 		return
 	}
@@ -107,6 +179,26 @@ func (ah *AdminHandler) RefreshToken(ctx *gin.Context) {
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REFRESH_TOKEN, result)
 	ctx.AbortWithStatusJSON(http.StatusOK, res)
 }
+func (ah *AdminHandler) Logout(ctx *gin.Context) {
+	if err := ah.adminService.Logout(ctx); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_LOGOUT, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_LOGOUT, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) LogoutAll(ctx *gin.Context) {
+	if err := ah.adminService.LogoutAll(ctx); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_LOGOUT, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_LOGOUT_ALL, nil)
+	ctx.JSON(http.StatusOK, res)
+}
 
 // User
 func (ah *AdminHandler) CreateUser(ctx *gin.Context) {
@@ -145,6 +237,31 @@ func (ah *AdminHandler) ReadAllUser(ctx *gin.Context) {
 		return
 	}
 
+	if paginationParam == "cursor" {
+		var payload dto.CursorRequest
+		if err := ctx.ShouldBindQuery(&payload); err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		result, err := ah.adminService.ReadAllUserWithCursor(ctx.Request.Context(), payload)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_USER, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		res := utils.Response{
+			Status:   true,
+			Messsage: dto.MESSAGE_SUCCESS_GET_LIST_USER,
+			Data:     result.Data,
+			Meta:     result.CursorResponse,
+		}
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
 	var payload dto.PaginationRequest
 	if err := ctx.ShouldBind(&payload); err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
@@ -214,13 +331,24 @@ func (ah *AdminHandler) DeleteUser(ctx *gin.Context) {
 	result, err := ah.adminService.DeleteUser(ctx.Request.Context(), payload)
 	if err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DELETE_USER, err.Error(), nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		ctx.AbortWithStatusJSON(roleErrorStatus(err), res)
 		return
 	}
 
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_USER, result)
 	ctx.JSON(http.StatusOK, res)
 }
+func (ah *AdminHandler) UnlockUser(ctx *gin.Context) {
+	result, err := ah.adminService.UnlockUser(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UNLOCK_USER, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UNLOCK_USER, result)
+	ctx.JSON(http.StatusOK, res)
+}
 
 // Cron
 func (ah *AdminHandler) TriggerExpire(ctx *gin.Context) {
@@ -259,14 +387,26 @@ func (ah *AdminHandler) CreatePackage(ctx *gin.Context) {
 		}
 	}
 
-	userIDStr := ctx.PostForm("user_id")
-	userUUID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PARSE_UUID, "invalid user_id", nil)
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
-		return
+	// a package is addressed to either user_id or company_id
+	if userIDStr := ctx.PostForm("user_id"); userIDStr != "" {
+		userUUID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PARSE_UUID, "invalid user_id", nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+		payload.UserID = userUUID
+	}
+
+	if companyIDStr := ctx.PostForm("company_id"); companyIDStr != "" {
+		companyUUID, err := uuid.Parse(companyIDStr)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PARSE_UUID, "invalid company_id", nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+		payload.CompanyID = &companyUUID
 	}
-	payload.UserID = userUUID
 
 	lockerIDStr := ctx.PostForm("locker_id")
 	lockerUUID, err := uuid.Parse(lockerIDStr)
@@ -285,6 +425,7 @@ func (ah *AdminHandler) CreatePackage(ctx *gin.Context) {
 		return
 	}
 	payload.SenderID = &senderUUID
+	payload.Size = entity.SlotSize(ctx.PostForm("package_size"))
 
 	fileHeader, err := ctx.FormFile("package_image")
 	if err == nil {
@@ -302,6 +443,13 @@ func (ah *AdminHandler) CreatePackage(ctx *gin.Context) {
 
 	result, err := ah.adminService.CreatePackage(ctx, payload)
 	if err != nil {
+		var lockerFullErr *dto.LockerFullError
+		if errors.As(err, &lockerFullErr) {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_PACKAGE, err.Error(), lockerFullErr.Alternatives)
+			ctx.AbortWithStatusJSON(http.StatusConflict, res)
+			return
+		}
+
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_PACKAGE, err.Error(), nil)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, res)
 		return
@@ -310,15 +458,291 @@ func (ah *AdminHandler) CreatePackage(ctx *gin.Context) {
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_PACKAGE, result)
 	ctx.JSON(http.StatusOK, res)
 }
+func (ah *AdminHandler) ImportPackages(ctx *gin.Context) {
+	var payload dto.ImportPackageRequest
+
+	if err := ctx.Request.ParseMultipartForm(32 << 20); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PARSE_MULTIPART_FORM, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	payload.DryRun, _ = strconv.ParseBool(ctx.DefaultPostForm("dry_run", "false"))
+
+	fileHeader, err := ctx.FormFile("file")
+	if err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_IMPORT_PACKAGE, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, res)
+			return
+		}
+		defer file.Close()
+
+		payload.FileHeader = fileHeader
+		payload.FileReader = file
+	}
+
+	result, err := ah.adminService.ImportPackages(ctx, payload)
+	if err != nil {
+		if errors.Is(err, dto.ErrImportHasInvalidRows) {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_IMPORT_PACKAGE, err.Error(), result)
+			ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, res)
+			return
+		}
+
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_IMPORT_PACKAGE, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	message := dto.MESSAGE_SUCCESS_IMPORT_PACKAGE
+	if result.DryRun {
+		message = dto.MESSAGE_SUCCESS_VALIDATE_IMPORT_PACKAGE
+	}
+
+	res := utils.BuildResponseSuccess(message, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// parseDateRange reads from and to (YYYY-MM-DD, both inclusive) from the
+// query string, month=YYYY-MM is a shortcut for a whole month. The returned
+// upper bound is exclusive.
+func parseDateRange(ctx *gin.Context) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+
+	if month := ctx.Query("month"); month != "" {
+		start, err := time.ParseInLocation("2006-01", month, time.Local)
+		if err != nil {
+			return nil, nil, dto.ErrInvalidDateRange
+		}
+		end := start.AddDate(0, 1, 0)
+		from, to = &start, &end
+	}
+
+	if fromStr := ctx.Query("from"); fromStr != "" {
+		start, err := time.ParseInLocation("2006-01-02", fromStr, time.Local)
+		if err != nil {
+			return nil, nil, dto.ErrInvalidDateRange
+		}
+		from = &start
+	}
+
+	if toStr := ctx.Query("to"); toStr != "" {
+		end, err := time.ParseInLocation("2006-01-02", toStr, time.Local)
+		if err != nil {
+			return nil, nil, dto.ErrInvalidDateRange
+		}
+		end = end.AddDate(0, 0, 1)
+		to = &end
+	}
+
+	return from, to, nil
+}
+
+// parseExportRequest reads the export filters from the query string.
+func parseExportRequest(ctx *gin.Context) (dto.ExportPackageRequest, error) {
+	req := dto.ExportPackageRequest{
+		Format: ctx.DefaultQuery("format", string(report.CSV)),
+		Status: entity.Status(ctx.Query("status")),
+		Type:   entity.Type(ctx.Query("type")),
+	}
+
+	var err error
+	if req.From, req.To, err = parseDateRange(ctx); err != nil {
+		return req, err
+	}
+
+	if companyIDStr := ctx.Query("company_id"); companyIDStr != "" {
+		companyID, err := uuid.Parse(companyIDStr)
+		if err != nil {
+			return req, dto.ErrParseUUID
+		}
+		req.CompanyID = &companyID
+	}
+
+	if lockerIDStr := ctx.Query("locker_id"); lockerIDStr != "" {
+		lockerID, err := uuid.Parse(lockerIDStr)
+		if err != nil {
+			return req, dto.ErrParseUUID
+		}
+		req.LockerID = &lockerID
+	}
+
+	return req, nil
+}
+
+// parsePackageFilter reads the package list filters from the query string.
+// Dates are YYYY-MM-DD and both bounds are inclusive, status may be repeated
+// or comma separated and sort looks like `status:asc,received_at:desc`.
+func parsePackageFilter(ctx *gin.Context) (dto.PackageFilter, error) {
+	filter := dto.PackageFilter{
+		Type: entity.Type(ctx.Query("type")),
+	}
+
+	for _, value := range ctx.QueryArray("status") {
+		for _, status := range strings.Split(value, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				filter.Statuses = append(filter.Statuses, entity.Status(status))
+			}
+		}
+	}
+
+	ids := map[string]**uuid.UUID{
+		"user_id":    &filter.UserID,
+		"company_id": &filter.CompanyID,
+		"locker_id":  &filter.LockerID,
+		"sender_id":  &filter.SenderID,
+	}
+	for key, dest := range ids {
+		if value := ctx.Query(key); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				return filter, dto.ErrParseUUID
+			}
+			*dest = &id
+		}
+	}
+
+	dates := map[string]**time.Time{
+		"received_from":  &filter.ReceivedFrom,
+		"completed_from": &filter.CompletedFrom,
+		"expired_from":   &filter.ExpiredFrom,
+		"received_to":    &filter.ReceivedTo,
+		"completed_to":   &filter.CompletedTo,
+		"expired_to":     &filter.ExpiredTo,
+	}
+	for key, dest := range dates {
+		if value := ctx.Query(key); value != "" {
+			date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				return filter, dto.ErrInvalidDateRange
+			}
+			if strings.HasSuffix(key, "_to") {
+				date = date.AddDate(0, 0, 1)
+			}
+			*dest = &date
+		}
+	}
+
+	quantities := map[string]**int{
+		"min_quantity": &filter.MinQuantity,
+		"max_quantity": &filter.MaxQuantity,
+	}
+	for key, dest := range quantities {
+		if value := ctx.Query(key); value != "" {
+			quantity, err := strconv.Atoi(value)
+			if err != nil {
+				return filter, dto.ErrInvalidQuantityPackage
+			}
+			*dest = &quantity
+		}
+	}
+
+	var err error
+	filter.Sort, err = dto.ParsePackageSort(ctx.Query("sort"))
+
+	return filter, err
+}
+
+// streamExport sets the download headers and runs export, which writes the
+// report straight to the response. Errors can only be reported as JSON while
+// nothing has been written yet.
+func streamExport(ctx *gin.Context, name, failedMessage string, export func(dto.ExportPackageRequest) error) {
+	req, err := parseExportRequest(ctx)
+	if err != nil {
+		res := utils.BuildResponseFailed(failedMessage, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	format := report.Format(req.Format)
+	ctx.Header("Content-Type", report.ContentType(format))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", report.FileName(name, time.Now().Format("20060102"), format)))
+
+	if err := export(req); err != nil {
+		if ctx.Writer.Written() {
+			_ = ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Writer.Header().Del("Content-Disposition")
+		res := utils.BuildResponseFailed(failedMessage, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+func (ah *AdminHandler) ExportPackages(ctx *gin.Context) {
+	streamExport(ctx, "packages", dto.MESSAGE_FAILED_EXPORT_PACKAGE, func(req dto.ExportPackageRequest) error {
+		return ah.adminService.ExportPackages(ctx, req, ctx.Writer)
+	})
+}
+func (ah *AdminHandler) ExportPackageHistories(ctx *gin.Context) {
+	streamExport(ctx, "package_histories", dto.MESSAGE_FAILED_EXPORT_PACKAGE_HISTORY, func(req dto.ExportPackageRequest) error {
+		return ah.adminService.ExportPackageHistories(ctx, req, ctx.Writer)
+	})
+}
+
+// PrintPackageLabels prints the labels of package_ids, which may be repeated
+// or comma separated, as a PDF or as ZPL for thermal printers.
+func (ah *AdminHandler) PrintPackageLabels(ctx *gin.Context) {
+	req := dto.PackageLabelRequest{
+		Format: ctx.DefaultQuery("format", string(label.PDF)),
+	}
+
+	for _, value := range ctx.QueryArray("package_ids") {
+		for _, idStr := range strings.Split(value, ",") {
+			if idStr = strings.TrimSpace(idStr); idStr == "" {
+				continue
+			}
+
+			id, err := uuid.Parse(idStr)
+			if err != nil {
+				res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PRINT_PACKAGE_LABEL, dto.ErrParseUUID.Error(), nil)
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+				return
+			}
+			req.PackageIDs = append(req.PackageIDs, id)
+		}
+	}
+
+	format := label.Format(req.Format)
+	ctx.Header("Content-Type", label.ContentType(format))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", label.FileName("labels", time.Now().Format("20060102150405"), format)))
+
+	if err := ah.adminService.PrintPackageLabels(ctx, req, ctx.Writer); err != nil {
+		if ctx.Writer.Written() {
+			_ = ctx.Error(err)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Writer.Header().Del("Content-Type")
+		ctx.Writer.Header().Del("Content-Disposition")
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PRINT_PACKAGE_LABEL, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
 func (ah *AdminHandler) ReadAllPackage(ctx *gin.Context) {
 	paginationParam := ctx.DefaultQuery("pagination", "true")
 	usePagination := paginationParam != "false"
-	userID := ctx.Query("user_id")
-	pkgType := ctx.Query("type")
+
+	filter, err := parsePackageFilter(ctx)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PACKAGE, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
 
 	if !usePagination {
 		// Tanpa pagination
-		result, err := ah.adminService.ReadAllPackageNoPagination(ctx, userID, pkgType)
+		result, err := ah.adminService.ReadAllPackageNoPagination(ctx, filter)
 		if err != nil {
 			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PACKAGE, err.Error(), nil)
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
@@ -330,6 +754,31 @@ func (ah *AdminHandler) ReadAllPackage(ctx *gin.Context) {
 		return
 	}
 
+	if paginationParam == "cursor" {
+		var payload dto.CursorRequest
+		if err := ctx.ShouldBindQuery(&payload); err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		result, err := ah.adminService.ReadAllPackageWithCursor(ctx, payload, filter)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PACKAGE, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		res := utils.Response{
+			Status:   true,
+			Messsage: dto.MESSAGE_SUCCESS_GET_LIST_PACKAGE,
+			Data:     result.Data,
+			Meta:     result.CursorResponse,
+		}
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
 	var payload dto.PaginationRequest
 	if err := ctx.ShouldBind(&payload); err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
@@ -337,7 +786,7 @@ func (ah *AdminHandler) ReadAllPackage(ctx *gin.Context) {
 		return
 	}
 
-	result, err := ah.adminService.ReadAllPackageWithPagination(ctx, payload, userID, pkgType)
+	result, err := ah.adminService.ReadAllPackageWithPagination(ctx, payload, filter)
 	if err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PACKAGE, err.Error(), nil)
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
@@ -367,6 +816,45 @@ func (ah *AdminHandler) GetDetailPackage(ctx *gin.Context) {
 }
 func (ah *AdminHandler) GetAllPackageHistory(ctx *gin.Context) {
 	pkgId := ctx.Param("id")
+
+	if ctx.Query("format") == "timeline" {
+		result, err := ah.adminService.ReadPackageTimeline(ctx, pkgId)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PACKAGE_HISTORY, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_PACKAGE_HISTORY, result)
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
+	if ctx.Query("pagination") == "cursor" {
+		var payload dto.CursorRequest
+		if err := ctx.ShouldBindQuery(&payload); err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		result, err := ah.adminService.ReadAllPackageHistoryWithCursor(ctx, pkgId, payload)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PACKAGE_HISTORY, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		res := utils.Response{
+			Status:   true,
+			Messsage: dto.MESSAGE_SUCCESS_GET_LIST_PACKAGE_HISTORY,
+			Data:     result.Data,
+			Meta:     result.CursorResponse,
+		}
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
 	result, err := ah.adminService.ReadAllPackageHistory(ctx, pkgId)
 	if err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PACKAGE_HISTORY, err.Error(), nil)
@@ -424,6 +912,28 @@ func (ah *AdminHandler) UpdatePackage(ctx *gin.Context) {
 		}
 		payload.LockerID = &lockerUUID
 	}
+	payload.Size = entity.SlotSize(ctx.PostForm("package_size"))
+	payload.PickupCode = ctx.PostForm("pickup_code")
+
+	if delegationIDStr := ctx.PostForm("delegation_id"); delegationIDStr != "" {
+		delegationUUID, err := uuid.Parse(delegationIDStr)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PARSE_UUID, "invalid delegation_id", nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+		payload.DelegationID = &delegationUUID
+	}
+
+	if collectorIDStr := ctx.PostForm("collector_id"); collectorIDStr != "" {
+		collectorUUID, err := uuid.Parse(collectorIDStr)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PARSE_UUID, "invalid collector_id", nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+		payload.CollectorID = &collectorUUID
+	}
 
 	fileHeader, err := ctx.FormFile("package_image")
 	if err == nil {
@@ -441,6 +951,13 @@ func (ah *AdminHandler) UpdatePackage(ctx *gin.Context) {
 
 	result, err := ah.adminService.UpdatePackage(ctx, payload)
 	if err != nil {
+		var lockerFullErr *dto.LockerFullError
+		if errors.As(err, &lockerFullErr) {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_PACKAGE, err.Error(), lockerFullErr.Alternatives)
+			ctx.AbortWithStatusJSON(http.StatusConflict, res)
+			return
+		}
+
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_PACKAGE, err.Error(), nil)
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
 		return
@@ -471,6 +988,27 @@ func (ah *AdminHandler) UpdateStatusPackages(ctx *gin.Context) {
 		}
 		payload.PackageIDs = append(payload.PackageIDs, parsedID)
 	}
+	payload.PickupCodes = ctx.PostFormArray("pickup_codes")
+
+	if delegationIDStr := ctx.PostForm("delegation_id"); delegationIDStr != "" {
+		delegationUUID, err := uuid.Parse(delegationIDStr)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PARSE_UUID, "invalid delegation_id", nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+		payload.DelegationID = &delegationUUID
+	}
+
+	if collectorIDStr := ctx.PostForm("collector_id"); collectorIDStr != "" {
+		collectorUUID, err := uuid.Parse(collectorIDStr)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PARSE_UUID, "invalid collector_id", nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+		payload.CollectorID = &collectorUUID
+	}
 
 	err = ah.adminService.UpdateStatusPackages(ctx, payload)
 	if err != nil {
@@ -482,28 +1020,52 @@ func (ah *AdminHandler) UpdateStatusPackages(ctx *gin.Context) {
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_STATUS_PACKAGES, nil)
 	ctx.JSON(http.StatusOK, res)
 }
-func (ah *AdminHandler) DeletePackage(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	var payload dto.DeletePackageRequest
-	payload.PackageID = idStr
-	if err := ctx.ShouldBind(&payload); err != nil {
-		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+func (ah *AdminHandler) RegeneratePickupCode(ctx *gin.Context) {
+	pkgID := ctx.Param("id")
+	result, err := ah.adminService.RegeneratePickupCode(ctx, pkgID)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REGENERATE_PICKUP_CODE, err.Error(), nil)
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
 		return
 	}
 
-	result, err := ah.adminService.DeletePackage(ctx.Request.Context(), payload)
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REGENERATE_PICKUP_CODE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) ReadAllActivePickupDelegation(ctx *gin.Context) {
+	pkgID := ctx.Param("id")
+	result, err := ah.adminService.ReadAllActivePickupDelegation(ctx.Request.Context(), pkgID)
 	if err != nil {
-		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DELETE_PACKAGE, err.Error(), nil)
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PICKUP_DELEGATION, err.Error(), nil)
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
 		return
 	}
 
-	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_PACKAGE, result)
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_PICKUP_DELEGATION, result)
 	ctx.JSON(http.StatusOK, res)
 }
-
-// Company
+func (ah *AdminHandler) DeletePackage(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	var payload dto.DeletePackageRequest
+	payload.PackageID = idStr
+	if err := ctx.ShouldBind(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := ah.adminService.DeletePackage(ctx, payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DELETE_PACKAGE, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_PACKAGE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Company
 func (ah *AdminHandler) CreateCompany(ctx *gin.Context) {
 	var payload dto.CreateCompanyRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
@@ -595,6 +1157,25 @@ func (ah *AdminHandler) UpdateCompany(ctx *gin.Context) {
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_COMPANY, result)
 	ctx.JSON(http.StatusOK, res)
 }
+func (ah *AdminHandler) UpdateCompanyContacts(ctx *gin.Context) {
+	var payload dto.UpdateCompanyContactsRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+	payload.ID = ctx.Param("id")
+
+	result, err := ah.adminService.UpdateCompanyContacts(ctx.Request.Context(), payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_COMPANY_CONTACTS, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_COMPANY_CONTACTS, result)
+	ctx.JSON(http.StatusOK, res)
+}
 func (ah *AdminHandler) DeleteCompany(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	result, err := ah.adminService.DeleteCompany(ctx.Request.Context(), idStr)
@@ -720,6 +1301,83 @@ func (ah *AdminHandler) DeleteLocker(ctx *gin.Context) {
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_LOCKER, result)
 	ctx.JSON(http.StatusOK, res)
 }
+func (ah *AdminHandler) GetLockerOccupancy(ctx *gin.Context) {
+	lockerID := ctx.Query("locker_id")
+	result, err := ah.adminService.GetLockerOccupancy(ctx.Request.Context(), lockerID)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LOCKER_OCCUPANCY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LOCKER_OCCUPANCY, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Locker Slot
+func (ah *AdminHandler) CreateLockerSlot(ctx *gin.Context) {
+	var payload dto.CreateLockerSlotRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := ah.adminService.CreateLockerSlot(ctx.Request.Context(), payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_LOCKER_SLOT, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_LOCKER_SLOT, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) ReadAllLockerSlot(ctx *gin.Context) {
+	lockerID := ctx.Param("id")
+	result, err := ah.adminService.ReadAllLockerSlot(ctx.Request.Context(), lockerID)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_LOCKER_SLOT, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_LOCKER_SLOT, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) UpdateLockerSlot(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+
+	var payload dto.UpdateLockerSlotRequest
+	payload.ID = idStr
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := ah.adminService.UpdateLockerSlot(ctx.Request.Context(), payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_LOCKER_SLOT, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_LOCKER_SLOT, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) DeleteLockerSlot(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	result, err := ah.adminService.DeleteLockerSlot(ctx.Request.Context(), idStr)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DELETE_LOCKER_SLOT, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_LOCKER_SLOT, result)
+	ctx.JSON(http.StatusOK, res)
+}
 
 // Sender
 func (ah *AdminHandler) CreateSender(ctx *gin.Context) {
@@ -758,6 +1416,31 @@ func (ah *AdminHandler) ReadAllSender(ctx *gin.Context) {
 		return
 	}
 
+	if paginationParam == "cursor" {
+		var payload dto.CursorRequest
+		if err := ctx.ShouldBindQuery(&payload); err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		result, err := ah.adminService.GetAllSenderWithCursor(ctx.Request.Context(), payload)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_ALL_SENDERS, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		res := utils.Response{
+			Status:   true,
+			Messsage: dto.MESSAGE_SUCCESS_GET_ALL_SENDERS,
+			Data:     result.Data,
+			Meta:     result.CursorResponse,
+		}
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
 	var payload dto.PaginationRequest
 	if err := ctx.ShouldBindQuery(&payload); err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
@@ -833,3 +1516,423 @@ func (ah *AdminHandler) DeleteSender(ctx *gin.Context) {
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_SENDER, result)
 	ctx.JSON(http.StatusOK, res)
 }
+
+// Notification
+func (ah *AdminHandler) ReadAllNotification(ctx *gin.Context) {
+	status := ctx.Query("status")
+
+	var payload dto.PaginationRequest
+	if err := ctx.ShouldBindQuery(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := ah.adminService.ReadAllNotificationWithPagination(ctx.Request.Context(), payload, status)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_NOTIFICATION, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.Response{
+		Status:   true,
+		Messsage: dto.MESSAGE_SUCCESS_GET_LIST_NOTIFICATION,
+		Data:     result.Data,
+		Meta:     result.PaginationResponse,
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) RetryNotification(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	result, err := ah.adminService.RetryNotification(ctx.Request.Context(), idStr)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_RETRY_NOTIFICATION, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_RETRY_NOTIFICATION, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) CancelNotification(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	result, err := ah.adminService.CancelNotification(ctx.Request.Context(), idStr)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CANCEL_NOTIFICATION, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CANCEL_NOTIFICATION, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Retention Policy
+func (ah *AdminHandler) CreateRetentionPolicy(ctx *gin.Context) {
+	var payload dto.CreateRetentionPolicyRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := ah.adminService.CreateRetentionPolicy(ctx.Request.Context(), payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_RETENTION_POLICY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_RETENTION_POLICY, result)
+	ctx.JSON(http.StatusCreated, res)
+}
+func (ah *AdminHandler) ReadAllRetentionPolicy(ctx *gin.Context) {
+	result, err := ah.adminService.ReadAllRetentionPolicy(ctx.Request.Context())
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_RETENTION_POLICY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_RETENTION_POLICY, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) GetDetailRetentionPolicy(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	result, err := ah.adminService.GetDetailRetentionPolicy(ctx.Request.Context(), idStr)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DETAIL_RETENTION_POLICY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_DETAIL_RETENTION_POLICY, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) UpdateRetentionPolicy(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+
+	var payload dto.UpdateRetentionPolicyRequest
+	payload.ID = idStr
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := ah.adminService.UpdateRetentionPolicy(ctx.Request.Context(), payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_RETENTION_POLICY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_RETENTION_POLICY, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) DeleteRetentionPolicy(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	result, err := ah.adminService.DeleteRetentionPolicy(ctx.Request.Context(), idStr)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DELETE_RETENTION_POLICY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_RETENTION_POLICY, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Stats
+func parseStatsRequest(ctx *gin.Context) (dto.StatsRequest, error) {
+	req := dto.StatsRequest{
+		Interval: ctx.Query("interval"),
+	}
+
+	var err error
+	if req.From, req.To, err = parseDateRange(ctx); err != nil {
+		return req, err
+	}
+
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if req.Limit, err = strconv.Atoi(limitStr); err != nil {
+			return req, err
+		}
+	}
+
+	return req, nil
+}
+
+// respondStats answers a stats request, the results are cached server side so
+// clients may cache them for as long too.
+func (ah *AdminHandler) respondStats(ctx *gin.Context, result any, err error) {
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_PACKAGE_STATS, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if ttl := ah.adminService.StatsCacheTTL(); ttl > 0 {
+		ctx.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(ttl.Seconds())))
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_PACKAGE_STATS, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) GetPackageStats(ctx *gin.Context) {
+	req, err := parseStatsRequest(ctx)
+	if err != nil {
+		ah.respondStats(ctx, nil, err)
+		return
+	}
+
+	result, err := ah.adminService.GetPackageStats(ctx, req)
+	ah.respondStats(ctx, result, err)
+}
+func (ah *AdminHandler) GetPackageReceivedStats(ctx *gin.Context) {
+	req, err := parseStatsRequest(ctx)
+	if err != nil {
+		ah.respondStats(ctx, nil, err)
+		return
+	}
+
+	result, err := ah.adminService.GetPackageReceivedStats(ctx, req)
+	ah.respondStats(ctx, result, err)
+}
+func (ah *AdminHandler) GetTopRecipients(ctx *gin.Context) {
+	req, err := parseStatsRequest(ctx)
+	if err != nil {
+		ah.respondStats(ctx, nil, err)
+		return
+	}
+
+	result, err := ah.adminService.GetTopRecipients(ctx, req)
+	ah.respondStats(ctx, result, err)
+}
+
+// Role & Permission
+// roleErrorStatus answers 409 when a safeguard refused the change and 400
+// otherwise.
+func roleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dto.ErrLastAdmin),
+		errors.Is(err, dto.ErrAdminLockout),
+		errors.Is(err, dto.ErrBuiltInRole),
+		errors.Is(err, dto.ErrRoleHasUsers),
+		errors.Is(err, dto.ErrRoleNameExists),
+		errors.Is(err, dto.ErrPermissionExists):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+func (ah *AdminHandler) CreateRole(ctx *gin.Context) {
+	var payload dto.CreateRoleRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := ah.adminService.CreateRole(ctx, payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_ROLE, err.Error(), nil)
+		ctx.AbortWithStatusJSON(roleErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_ROLE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) ReadAllRole(ctx *gin.Context) {
+	result, err := ah.adminService.ReadAllRole(ctx)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_ROLE, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_ROLE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) GetDetailRole(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	result, err := ah.adminService.GetDetailRole(ctx, idStr)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DETAIL_ROLE, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_DETAIL_ROLE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) UpdateRole(ctx *gin.Context) {
+	var payload dto.UpdateRoleRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+	payload.ID = ctx.Param("id")
+
+	result, err := ah.adminService.UpdateRole(ctx, payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_UPDATE_ROLE, err.Error(), nil)
+		ctx.AbortWithStatusJSON(roleErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_UPDATE_ROLE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) DeleteRole(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	result, err := ah.adminService.DeleteRole(ctx, idStr)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DELETE_ROLE, err.Error(), nil)
+		ctx.AbortWithStatusJSON(roleErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DELETE_ROLE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) ReadAllRoute(ctx *gin.Context) {
+	result, err := ah.adminService.ReadAllRoute(ctx, ctx.Query("role_id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_ROUTE, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_ROUTE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) AttachPermission(ctx *gin.Context) {
+	var payload dto.AttachPermissionRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+	payload.RoleID = ctx.Param("id")
+
+	result, err := ah.adminService.AttachPermission(ctx, payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_ATTACH_PERMISSION, err.Error(), nil)
+		ctx.AbortWithStatusJSON(roleErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_ATTACH_PERMISSION, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) DetachPermission(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	result, err := ah.adminService.DetachPermission(ctx, idStr)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_DETACH_PERMISSION, err.Error(), nil)
+		ctx.AbortWithStatusJSON(roleErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_DETACH_PERMISSION, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) AssignRole(ctx *gin.Context) {
+	var payload dto.AssignRoleRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+	payload.UserID = ctx.Param("id")
+
+	result, err := ah.adminService.AssignRole(ctx, payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_ASSIGN_ROLE, err.Error(), nil)
+		ctx.AbortWithStatusJSON(roleErrorStatus(err), res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_ASSIGN_ROLE, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Audit Log
+func (ah *AdminHandler) ReadAllAuditLog(ctx *gin.Context) {
+	var payload dto.PaginationRequest
+	if err := ctx.ShouldBindQuery(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var filter dto.AuditLogFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := ah.auditService.ReadAllAuditLog(ctx.Request.Context(), payload, filter)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_AUDIT_LOG, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.Response{
+		Status:   true,
+		Messsage: dto.MESSAGE_SUCCESS_GET_LIST_AUDIT_LOG,
+		Data:     result.Data,
+		Meta:     result.PaginationResponse,
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+func (ah *AdminHandler) GetDetailAuditLog(ctx *gin.Context) {
+	result, err := ah.auditService.GetDetailAuditLog(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DETAIL_AUDIT_LOG, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_DETAIL_AUDIT_LOG, result)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// Search
+func (ah *AdminHandler) Search(ctx *gin.Context) {
+	var payload dto.SearchRequest
+	if err := ctx.ShouldBindQuery(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	// types can be repeated or comma separated
+	var types []string
+	for _, value := range payload.Types {
+		for _, t := range strings.Split(value, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, strings.ToLower(t))
+			}
+		}
+	}
+	payload.Types = types
+
+	result, err := ah.searchService.Search(ctx.Request.Context(), payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_SEARCH, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_SEARCH, result)
+	ctx.JSON(http.StatusOK, res)
+}
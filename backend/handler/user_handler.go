@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/Amierza/TitipanQ/backend/dto"
@@ -15,6 +16,14 @@ type (
 		Register(ctx *gin.Context)
 		Login(ctx *gin.Context)
 		RefreshToken(ctx *gin.Context)
+		Logout(ctx *gin.Context)
+		LogoutAll(ctx *gin.Context)
+		VerifyEmail(ctx *gin.Context)
+		ResendVerification(ctx *gin.Context)
+		ForgotPassword(ctx *gin.Context)
+		ResetPassword(ctx *gin.Context)
+		RequestLoginOTP(ctx *gin.Context)
+		VerifyLoginOTP(ctx *gin.Context)
 
 		// Company
 		ReadAllCompany(ctx *gin.Context)
@@ -27,6 +36,11 @@ type (
 		ReadAllPackage(ctx *gin.Context)
 		GetDetailPackage(ctx *gin.Context)
 		GetAllPackageHistory(ctx *gin.Context)
+
+		// Pickup Delegation
+		CreatePickupDelegation(ctx *gin.Context)
+		ReadAllPickupDelegation(ctx *gin.Context)
+		RevokePickupDelegation(ctx *gin.Context)
 	}
 
 	UserHandler struct {
@@ -70,6 +84,15 @@ func (uh *UserHandler) Login(ctx *gin.Context) {
 	result, err := uh.userService.Login(ctx, payload)
 	if err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_LOGIN_USER, err.Error(), nil)
+		switch {
+		case errors.Is(err, dto.ErrEmailNotVerified):
+			ctx.AbortWithStatusJSON(http.StatusForbidden, res)
+			return
+		case errors.Is(err, dto.ErrAccountLocked), errors.Is(err, dto.ErrTooManyLoginAttempts):
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, res)
+			return
+		}
+
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
 		return
 	}
@@ -88,6 +111,11 @@ func (uh *UserHandler) RefreshToken(ctx *gin.Context) {
 	result, err := uh.userService.RefreshToken(ctx, payload)
 	if err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REFRESH_TOKEN, err.Error(), nil)
+		if errors.Is(err, dto.ErrSessionNotFound) || errors.Is(err, dto.ErrSessionRevoked) || errors.Is(err, dto.ErrRefreshTokenReused) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			return
+		}
+
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
 		return
 	}
@@ -95,6 +123,142 @@ func (uh *UserHandler) RefreshToken(ctx *gin.Context) {
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REFRESH_TOKEN, result)
 	ctx.AbortWithStatusJSON(http.StatusOK, res)
 }
+func (uh *UserHandler) Logout(ctx *gin.Context) {
+	if err := uh.userService.Logout(ctx); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_LOGOUT, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_LOGOUT, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+func (uh *UserHandler) LogoutAll(ctx *gin.Context) {
+	if err := uh.userService.LogoutAll(ctx); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_LOGOUT, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_LOGOUT_ALL, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+func (uh *UserHandler) VerifyEmail(ctx *gin.Context) {
+	var payload dto.VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := uh.userService.VerifyEmail(ctx, payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_VERIFY_EMAIL, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_VERIFY_EMAIL, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+func (uh *UserHandler) ResendVerification(ctx *gin.Context) {
+	var payload dto.ResendVerificationRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := uh.userService.ResendVerification(ctx, payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_RESEND_VERIFICATION, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_RESEND_VERIFICATION, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+func (uh *UserHandler) ForgotPassword(ctx *gin.Context) {
+	var payload dto.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := uh.userService.ForgotPassword(ctx, payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_FORGOT_PASSWORD, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_FORGOT_PASSWORD, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+func (uh *UserHandler) ResetPassword(ctx *gin.Context) {
+	var payload dto.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := uh.userService.ResetPassword(ctx, payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_RESET_PASSWORD, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_RESET_PASSWORD, nil)
+	ctx.JSON(http.StatusOK, res)
+}
+func (uh *UserHandler) RequestLoginOTP(ctx *gin.Context) {
+	var payload dto.RequestLoginOTPRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := uh.userService.RequestLoginOTP(ctx, payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REQUEST_OTP, err.Error(), nil)
+		switch {
+		case errors.Is(err, dto.ErrOTPRateLimited):
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, res)
+		case errors.Is(err, dto.ErrSendOTP):
+			ctx.AbortWithStatusJSON(http.StatusBadGateway, res)
+		default:
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		}
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REQUEST_OTP, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (uh *UserHandler) VerifyLoginOTP(ctx *gin.Context) {
+	var payload dto.VerifyLoginOTPRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := uh.userService.VerifyLoginOTP(ctx, payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_VERIFY_OTP, err.Error(), nil)
+		if errors.Is(err, dto.ErrOTPInvalid) || errors.Is(err, dto.ErrOTPTooManyAttempts) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_LOGIN_USER, result)
+	ctx.JSON(http.StatusOK, res)
+}
 
 // Company
 func (uh *UserHandler) ReadAllCompany(ctx *gin.Context) {
@@ -142,7 +306,14 @@ func (uh *UserHandler) UpdateUser(ctx *gin.Context) {
 
 // Package
 func (uh *UserHandler) ReadAllPackage(ctx *gin.Context) {
-	result, err := uh.userService.ReadAllPackage(ctx)
+	filter, err := parsePackageFilter(ctx)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PACKAGE, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := uh.userService.ReadAllPackage(ctx, filter)
 	if err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PACKAGE, err.Error(), nil)
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
@@ -166,6 +337,20 @@ func (uh *UserHandler) GetDetailPackage(ctx *gin.Context) {
 }
 func (uh *UserHandler) GetAllPackageHistory(ctx *gin.Context) {
 	pkgID := ctx.Param("id")
+
+	if ctx.Query("format") == "timeline" {
+		result, err := uh.userService.ReadPackageTimeline(ctx, pkgID)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PACKAGE_HISTORY, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+			return
+		}
+
+		res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_PACKAGE_HISTORY, result)
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+
 	result, err := uh.userService.ReadAllPackageHistory(ctx, pkgID)
 	if err != nil {
 		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PACKAGE_HISTORY, err.Error(), nil)
@@ -176,3 +361,46 @@ func (uh *UserHandler) GetAllPackageHistory(ctx *gin.Context) {
 	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_PACKAGE_HISTORY, result)
 	ctx.JSON(http.StatusOK, res)
 }
+
+// Pickup Delegation
+func (uh *UserHandler) CreatePickupDelegation(ctx *gin.Context) {
+	var payload dto.CreatePickupDelegationRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_DATA_FROM_BODY, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := uh.userService.CreatePickupDelegation(ctx, payload)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_CREATE_PICKUP_DELEGATION, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_CREATE_PICKUP_DELEGATION, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (uh *UserHandler) ReadAllPickupDelegation(ctx *gin.Context) {
+	result, err := uh.userService.ReadAllPickupDelegation(ctx)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_GET_LIST_PICKUP_DELEGATION, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_GET_LIST_PICKUP_DELEGATION, result)
+	ctx.JSON(http.StatusOK, res)
+}
+func (uh *UserHandler) RevokePickupDelegation(ctx *gin.Context) {
+	delegationID := ctx.Param("id")
+	result, err := uh.userService.RevokePickupDelegation(ctx, delegationID)
+	if err != nil {
+		res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_REVOKE_PICKUP_DELEGATION, err.Error(), nil)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, res)
+		return
+	}
+
+	res := utils.BuildResponseSuccess(dto.MESSAGE_SUCCESS_REVOKE_PICKUP_DELEGATION, result)
+	ctx.JSON(http.StatusOK, res)
+}
@@ -1,36 +1,58 @@
 package helpers
 
 import (
+	"bytes"
 	"fmt"
+	"image"
+	"image/draw"
 	"image/png"
 	"os"
 
 	"github.com/boombuler/barcode"
 	"github.com/boombuler/barcode/code128"
+	"github.com/skip2/go-qrcode"
 )
 
-func GenerateBarcodeFile(trackingCode string) (string, error) {
+// BarcodePNG renders trackingCode as a Code128 barcode of width x height
+// pixels.
+func BarcodePNG(trackingCode string, width, height int) ([]byte, error) {
 	barcodeData, err := code128.Encode(trackingCode)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	scaledBarcode, err := barcode.Scale(barcodeData, 300, 100)
+	scaledBarcode, err := barcode.Scale(barcodeData, width, height)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	_ = os.MkdirAll("assets/barcode", os.ModePerm)
-	fileName := fmt.Sprintf("barcode_%s.png", trackingCode)
-	savePath := fmt.Sprintf("assets/barcode/%s", fileName)
-	file, err := os.Create(savePath)
+	// barcodes come out as 16-bit gray, which PDF renderers do not take
+	gray := image.NewGray(scaledBarcode.Bounds())
+	draw.Draw(gray, gray.Bounds(), scaledBarcode, scaledBarcode.Bounds().Min, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, gray); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// QRCodePNG renders content as a square QR code of size pixels.
+func QRCodePNG(content string, size int) ([]byte, error) {
+	return qrcode.Encode(content, qrcode.Medium, size)
+}
+
+func GenerateBarcodeFile(trackingCode string) (string, error) {
+	barcodePNG, err := BarcodePNG(trackingCode, 300, 100)
 	if err != nil {
 		return "", err
 	}
-	defer file.Close()
 
-	err = png.Encode(file, scaledBarcode)
-	if err != nil {
+	_ = os.MkdirAll("assets/barcode", os.ModePerm)
+	fileName := fmt.Sprintf("barcode_%s.png", trackingCode)
+	savePath := fmt.Sprintf("assets/barcode/%s", fileName)
+	if err := os.WriteFile(savePath, barcodePNG, 0o644); err != nil {
 		return "", err
 	}
 
@@ -0,0 +1,26 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+const pickupCodeDigits = 6
+
+// GeneratePickupCode returns a random numeric one-time code for collecting a package.
+func GeneratePickupCode() (string, error) {
+	return GenerateNumericCode(pickupCodeDigits)
+}
+
+// GenerateNumericCode returns a random code of exactly digits digits, leading
+// zeros included.
+func GenerateNumericCode(digits int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", digits, n.Int64()), nil
+}
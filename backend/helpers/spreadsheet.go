@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ReadSpreadsheet returns every row of a CSV file or of the first sheet of an
+// XLSX file, ext is the file extension without the dot.
+func ReadSpreadsheet(r io.Reader, ext string) ([][]string, error) {
+	switch strings.ToLower(ext) {
+	case "csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+
+		// Excel saves "CSV UTF-8" with a byte order mark before the first header
+		if len(rows) > 0 && len(rows[0]) > 0 {
+			rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+		}
+
+		return rows, nil
+	case "xlsx":
+		file, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		sheets := file.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, fmt.Errorf("workbook has no sheets")
+		}
+
+		return file.GetRows(sheets[0])
+	default:
+		return nil, fmt.Errorf("unsupported spreadsheet extension %q", ext)
+	}
+}
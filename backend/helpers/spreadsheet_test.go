@@ -0,0 +1,31 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadSpreadsheetCSV(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"plain", "tracking_code,quantity\nTRK-1,2\n"},
+		{"byte order mark", "\ufefftracking_code,quantity\nTRK-1,2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadSpreadsheet(strings.NewReader(tt.input), "CSV")
+			if err != nil {
+				t.Fatalf("ReadSpreadsheet() error = %v", err)
+			}
+			if len(rows) != 2 {
+				t.Fatalf("got %d rows, want 2", len(rows))
+			}
+			if rows[0][0] != "tracking_code" {
+				t.Fatalf("first header = %q, want %q", rows[0][0], "tracking_code")
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// TTL is a small in-process cache whose entries expire after a fixed
+	// duration, good enough for values that may be a little stale.
+	TTL struct {
+		mu    sync.Mutex
+		ttl   time.Duration
+		items map[string]item
+	}
+
+	item struct {
+		value     any
+		expiresAt time.Time
+	}
+)
+
+func NewTTL(ttl time.Duration) *TTL {
+	return &TTL{
+		ttl:   ttl,
+		items: make(map[string]item),
+	}
+}
+
+func (c *TTL) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(c.items, key)
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+func (c *TTL) Set(key string, value any) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.items {
+		if now.After(entry.expiresAt) {
+			delete(c.items, k)
+		}
+	}
+
+	c.items[key] = item{
+		value:     value,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// TTL returns how long entries live, callers use it for Cache-Control.
+func (c *TTL) TTL() time.Duration {
+	return c.ttl
+}
+
+func (c *TTL) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, key)
+}
+
+func (c *TTL) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]item)
+}
@@ -0,0 +1,68 @@
+package label
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+type Format string
+
+const (
+	PDF Format = "pdf"
+	ZPL Format = "zpl"
+)
+
+// Label is what gets printed on a single parcel, every label is 100x150mm
+// (4x6in), the usual size of thermal shipping labels.
+type Label struct {
+	TrackingCode string
+	Recipient    string
+	Company      string
+	LockerCode   string
+	ReceivedAt   time.Time
+}
+
+func IsValidFormat(format Format) bool {
+	switch format {
+	case PDF, ZPL:
+		return true
+	}
+	return false
+}
+
+// ContentType returns the MIME type of the format.
+func ContentType(format Format) string {
+	if format == PDF {
+		return "application/pdf"
+	}
+
+	return "text/plain; charset=utf-8"
+}
+
+// FileName builds the attachment name of a label batch, e.g.
+// labels_20250701.zpl.
+func FileName(name, suffix string, format Format) string {
+	return fmt.Sprintf("%s_%s.%s", name, suffix, format)
+}
+
+// Write renders labels in the format, one label per page or per ZPL format.
+func Write(format Format, w io.Writer, labels []Label) error {
+	switch format {
+	case PDF:
+		return writePDF(w, labels)
+	case ZPL:
+		return writeZPL(w, labels)
+	default:
+		return fmt.Errorf("unsupported label format %q", format)
+	}
+}
+
+// orDash keeps an empty field visibly empty on the label.
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
@@ -0,0 +1,93 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/Amierza/TitipanQ/backend/helpers"
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	pdfWidth  = 100
+	pdfHeight = 150
+	pdfMargin = 5
+	pdfQRSize = 45
+)
+
+func writePDF(w io.Writer, labels []Label) error {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr: "mm",
+		Size:    gofpdf.SizeType{Wd: pdfWidth, Ht: pdfHeight},
+	})
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	contentWidth := float64(pdfWidth - 2*pdfMargin)
+	imageOptions := gofpdf.ImageOptions{ImageType: "PNG"}
+
+	for i, l := range labels {
+		barcodePNG, err := helpers.BarcodePNG(l.TrackingCode, 600, 160)
+		if err != nil {
+			return err
+		}
+		qrPNG, err := helpers.QRCodePNG(l.TrackingCode, 512)
+		if err != nil {
+			return err
+		}
+
+		barcodeName := fmt.Sprintf("barcode-%d", i)
+		qrName := fmt.Sprintf("qr-%d", i)
+		pdf.RegisterImageOptionsReader(barcodeName, imageOptions, bytes.NewReader(barcodePNG))
+		pdf.RegisterImageOptionsReader(qrName, imageOptions, bytes.NewReader(qrPNG))
+
+		pdf.AddPage()
+		pdf.ImageOptions(barcodeName, pdfMargin, pdfMargin, contentWidth, 25, false, imageOptions, 0, "")
+
+		pdf.SetY(pdfMargin + 26)
+		pdf.SetFont("Arial", "B", 14)
+		pdf.CellFormat(contentWidth, 7, translate(l.TrackingCode), "", 1, "C", false, 0, "")
+		pdf.Line(pdfMargin, pdf.GetY()+1, pdfWidth-pdfMargin, pdf.GetY()+1)
+		pdf.Ln(3)
+
+		fields := []struct {
+			name, value string
+			size        float64
+		}{
+			{"Recipient", l.Recipient, 16},
+			{"Company", l.Company, 12},
+			{"Locker", l.LockerCode, 12},
+			{"Received", l.ReceivedAt.Format("02 Jan 2006 15:04"), 12},
+		}
+		for _, field := range fields {
+			pdf.SetFont("Arial", "", 8)
+			pdf.CellFormat(contentWidth, 4, field.name, "", 1, "L", false, 0, "")
+			pdf.SetFont("Arial", "B", field.size)
+			pdf.CellFormat(contentWidth, field.size/2, fitPDF(pdf, translate(orDash(field.value)), contentWidth), "", 1, "L", false, 0, "")
+			pdf.Ln(1)
+		}
+
+		pdf.ImageOptions(qrName, (pdfWidth-pdfQRSize)/2, pdfHeight-pdfMargin-pdfQRSize, pdfQRSize, pdfQRSize, false, imageOptions, 0, "")
+	}
+
+	if pdf.PageNo() == 0 {
+		pdf.AddPage()
+	}
+
+	return pdf.Output(w)
+}
+
+// fitPDF truncates an already translated value so it stays on one line of
+// the given width in the current font.
+func fitPDF(pdf *gofpdf.Fpdf, value string, width float64) string {
+	if pdf.GetStringWidth(value) <= width {
+		return value
+	}
+
+	for len(value) > 0 && pdf.GetStringWidth(value+"...") > width {
+		value = value[:len(value)-1]
+	}
+
+	return value + "..."
+}
@@ -0,0 +1,56 @@
+package label
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// zplEscaper hex escapes the characters ZPL reads as commands, it goes with
+// the ^FH field prefix.
+var zplEscaper = strings.NewReplacer(`\`, `\5C`, `^`, `\5E`, `~`, `\7E`)
+
+// writeZPL writes one ^XA...^XZ format per label for 203dpi printers, so a
+// batch prints as many labels in a single job.
+func writeZPL(w io.Writer, labels []Label) error {
+	bw := bufio.NewWriter(w)
+
+	for _, l := range labels {
+		// narrow the bars of long codes so the barcode stays on the label
+		moduleWidth := 3
+		if len(l.TrackingCode) > 12 {
+			moduleWidth = 2
+		}
+
+		fmt.Fprint(bw, "^XA\n^CI28\n^PW800\n^LL1200\n")
+		fmt.Fprintf(bw, "^FO40,40^BY%d^BCN,200,N,N,N^FH\\^FD%s^FS\n", moduleWidth, zplField(l.TrackingCode))
+		fmt.Fprintf(bw, "^FO40,260^A0N,44,44^FB720,1,0,C^FH\\^FD%s^FS\n", zplField(l.TrackingCode))
+		fmt.Fprint(bw, "^FO40,320^GB720,3,3^FS\n")
+
+		fields := []struct {
+			name, value string
+			size        int
+		}{
+			{"Recipient", l.Recipient, 50},
+			{"Company", l.Company, 40},
+			{"Locker", l.LockerCode, 40},
+			{"Received", l.ReceivedAt.Format("02 Jan 2006 15:04"), 40},
+		}
+		y := 350
+		for _, field := range fields {
+			fmt.Fprintf(bw, "^FO40,%d^A0N,26,26^FD%s^FS\n", y, field.name)
+			fmt.Fprintf(bw, "^FO40,%d^A0N,%d,%d^FB720,1,0,L^FH\\^FD%s^FS\n", y+32, field.size, field.size, zplField(orDash(field.value)))
+			y += 32 + field.size + 24
+		}
+
+		fmt.Fprintf(bw, "^FO230,820^BQN,2,10^FH\\^FDQA,%s^FS\n", zplField(l.TrackingCode))
+		fmt.Fprint(bw, "^XZ\n")
+	}
+
+	return bw.Flush()
+}
+
+func zplField(value string) string {
+	return zplEscaper.Replace(value)
+}
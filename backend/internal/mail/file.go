@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileMailer is the development stand-in for SMTPMailer, every mail is logged
+// and, when dir is set, written there as a plain text file.
+type FileMailer struct {
+	dir string
+}
+
+func NewFileMailer(dir string) *FileMailer {
+	return &FileMailer{
+		dir: dir,
+	}
+}
+
+func (fm *FileMailer) Send(ctx context.Context, mail Mail) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if fm.dir == "" {
+		log.Printf("[MAIL] to=%s subject=%q body=%q", mail.To, mail.Subject, mail.Body)
+		return nil
+	}
+
+	if err := os.MkdirAll(fm.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail dir: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s.txt", time.Now().Format("20060102T150405.000000000"), sanitizeFileName(mail.To))
+	path := filepath.Join(fm.dir, name)
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", mail.To, mail.Subject, mail.Body)
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+
+	log.Printf("[MAIL] to=%s subject=%q file=%s", mail.To, mail.Subject, path)
+	return nil
+}
+
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
@@ -0,0 +1,29 @@
+package mail
+
+import (
+	"context"
+	"os"
+)
+
+type (
+	IMailer interface {
+		Send(ctx context.Context, mail Mail) error
+	}
+
+	Mail struct {
+		To      string
+		Subject string
+		Body    string
+	}
+)
+
+// New picks the mailer from MAIL_DRIVER: smtp (default) sends through the
+// SMTP_* settings, file writes every mail to MAIL_DIR and the log so the
+// flows can be tried locally without a mail server.
+func New() IMailer {
+	if os.Getenv("MAIL_DRIVER") == "file" {
+		return NewFileMailer(os.Getenv("MAIL_DIR"))
+	}
+
+	return NewSMTPMailer()
+}
@@ -0,0 +1,82 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"os"
+	"time"
+)
+
+// SMTPSender delivers ready built messages through the SMTP_* settings, it is
+// shared by SMTPMailer and the email notifier.
+type SMTPSender struct {
+	host       string
+	port       string
+	senderName string
+	email      string
+	password   string
+}
+
+func NewSMTPSender() *SMTPSender {
+	return &SMTPSender{
+		host:       os.Getenv("SMTP_HOST"),
+		port:       os.Getenv("SMTP_PORT"),
+		senderName: os.Getenv("SMTP_SENDER_NAME"),
+		email:      os.Getenv("SMTP_AUTH_EMAIL"),
+		password:   os.Getenv("SMTP_AUTH_PASSWORD"),
+	}
+}
+
+// WriteHeaders starts a message with the headers every mail shares, the
+// caller follows up with its own Content-Type.
+func (ss *SMTPSender) WriteHeaders(buf *bytes.Buffer, to, subject string) {
+	from := ss.senderName
+	if from == "" {
+		from = ss.email
+	}
+
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", to)
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+}
+
+func (ss *SMTPSender) Send(ctx context.Context, to string, message []byte) error {
+	if ss.host == "" || ss.port == "" {
+		return fmt.Errorf("smtp not configured")
+	}
+
+	if to == "" {
+		return fmt.Errorf("mail has no recipient")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	auth := smtp.PlainAuth("", ss.email, ss.password, ss.host)
+	return smtp.SendMail(ss.host+":"+ss.port, auth, ss.email, []string{to}, message)
+}
+
+type SMTPMailer struct {
+	sender *SMTPSender
+}
+
+func NewSMTPMailer() *SMTPMailer {
+	return &SMTPMailer{
+		sender: NewSMTPSender(),
+	}
+}
+
+func (sm *SMTPMailer) Send(ctx context.Context, mail Mail) error {
+	var buf bytes.Buffer
+	sm.sender.WriteHeaders(&buf, mail.To, mail.Subject)
+	fmt.Fprintf(&buf, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	buf.WriteString(mail.Body)
+
+	return sm.sender.Send(ctx, mail.To, buf.Bytes())
+}
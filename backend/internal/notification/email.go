@@ -0,0 +1,91 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+
+	"github.com/Amierza/TitipanQ/backend/internal/mail"
+)
+
+type EmailNotifier struct {
+	sender *mail.SMTPSender
+}
+
+func NewEmailNotifier() *EmailNotifier {
+	return &EmailNotifier{
+		sender: mail.NewSMTPSender(),
+	}
+}
+
+func (en *EmailNotifier) Send(ctx context.Context, msg Message) error {
+	if msg.Email == "" {
+		return fmt.Errorf("recipient has no email address")
+	}
+
+	body, err := en.buildBody(msg)
+	if err != nil {
+		return err
+	}
+
+	return en.sender.Send(ctx, msg.Email, body)
+}
+
+func (en *EmailNotifier) buildBody(msg Message) ([]byte, error) {
+	subject := msg.Subject
+	if subject == "" {
+		subject = "TitipanQ"
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	en.sender.WriteHeaders(&buf, msg.Email, subject)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	text, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/plain; charset=utf-8"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := text.Write([]byte(msg.Body)); err != nil {
+		return nil, err
+	}
+
+	if msg.ImagePath != "" {
+		image, err := os.ReadFile(msg.ImagePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image file: %w", err)
+		}
+
+		attachment, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {msg.MimeType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {fmt.Sprintf("attachment; filename=%q", filepath.Base(msg.ImagePath))},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoded := base64.StdEncoding.EncodeToString(image)
+		for len(encoded) > 0 {
+			n := min(len(encoded), 76)
+			if _, err := attachment.Write([]byte(encoded[:n] + "\r\n")); err != nil {
+				return nil, err
+			}
+			encoded = encoded[n:]
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,36 @@
+package notification
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// LogNotifier keeps every message in memory and writes it to the log instead
+// of delivering it, so the backend can run without a paired WhatsApp device.
+type LogNotifier struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (ln *LogNotifier) Send(ctx context.Context, msg Message) error {
+	ln.mu.Lock()
+	ln.messages = append(ln.messages, msg)
+	ln.mu.Unlock()
+
+	log.Printf("[NOTIFY] to=%s email=%s subject=%q body=%q", msg.PhoneNumber, msg.Email, msg.Subject, msg.Body)
+	return nil
+}
+
+func (ln *LogNotifier) Messages() []Message {
+	ln.mu.Lock()
+	defer ln.mu.Unlock()
+
+	messages := make([]Message, len(ln.messages))
+	copy(messages, ln.messages)
+	return messages
+}
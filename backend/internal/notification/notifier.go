@@ -0,0 +1,58 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Amierza/TitipanQ/backend/entity"
+)
+
+type (
+	INotifier interface {
+		Send(ctx context.Context, msg Message) error
+	}
+
+	Message struct {
+		PhoneNumber string
+		Email       string
+		Subject     string
+		Body        string
+		ImagePath   string
+		MimeType    string
+		Channels    []entity.NotificationChannel
+	}
+
+	// Dispatcher fans a message out to every channel the recipient opted into.
+	Dispatcher struct {
+		notifiers map[entity.NotificationChannel]INotifier
+	}
+)
+
+func NewDispatcher(notifiers map[entity.NotificationChannel]INotifier) *Dispatcher {
+	return &Dispatcher{
+		notifiers: notifiers,
+	}
+}
+
+func (d *Dispatcher) Send(ctx context.Context, msg Message) error {
+	channels := msg.Channels
+	if len(channels) == 0 {
+		channels = []entity.NotificationChannel{entity.WhatsAppChannel}
+	}
+
+	var errs []error
+	for _, channel := range channels {
+		notifier, ok := d.notifiers[channel]
+		if !ok {
+			errs = append(errs, fmt.Errorf("notification channel %s not configured", channel))
+			continue
+		}
+
+		if err := notifier.Send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,17 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/Amierza/TitipanQ/backend/internal/whatsapp"
+)
+
+type WhatsAppNotifier struct{}
+
+func NewWhatsAppNotifier() *WhatsAppNotifier {
+	return &WhatsAppNotifier{}
+}
+
+func (wn *WhatsAppNotifier) Send(ctx context.Context, msg Message) error {
+	return whatsapp.SendTextMessage(msg.PhoneNumber, msg.Body, msg.ImagePath, msg.MimeType)
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// csvFlushEvery bounds how many rows are buffered before they are flushed to
+// the client.
+const csvFlushEvery = 200
+
+type CSVWriter struct {
+	writer *csv.Writer
+	rows   int
+}
+
+func newCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{
+		writer: csv.NewWriter(w),
+	}
+}
+
+func (cw *CSVWriter) WriteHeader(columns []string) error {
+	return cw.WriteRow(columns)
+}
+
+func (cw *CSVWriter) WriteRow(values []string) error {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escapeFormula(value)
+	}
+
+	if err := cw.writer.Write(escaped); err != nil {
+		return err
+	}
+
+	cw.rows++
+	if cw.rows%csvFlushEvery == 0 {
+		cw.writer.Flush()
+		return cw.writer.Error()
+	}
+
+	return nil
+}
+
+func (cw *CSVWriter) Close() error {
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+// escapeFormula keeps spreadsheet apps from running a cell as a formula, any
+// value starting with one of the formula characters is prefixed with a quote.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+
+	return value
+}
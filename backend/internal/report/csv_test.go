@@ -0,0 +1,37 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCSVWriterEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"plain description", "plain description"},
+		{"", ""},
+		{"=HYPERLINK(\"http://x\")", "\"'=HYPERLINK(\"\"http://x\"\")\""},
+		{"+62812", "'+62812"},
+		{"-1+1", "'-1+1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"a=b", "a=b"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		cw := newCSVWriter(&buf)
+		if err := cw.WriteRow([]string{tt.value}); err != nil {
+			t.Fatalf("WriteRow(%q) error = %v", tt.value, err)
+		}
+		if err := cw.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		if got := buf.String(); got != tt.want+"\n" {
+			t.Errorf("WriteRow(%q) wrote %q, want %q", tt.value, got, tt.want+"\n")
+		}
+	}
+}
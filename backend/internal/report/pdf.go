@@ -0,0 +1,123 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	pdfFontSize   = 7
+	pdfLineHeight = 5
+	pdfMargin     = 10
+)
+
+// PDFWriter renders a landscape A4 table. A PDF can only be written once it
+// is complete, so unlike the other formats the document is kept in memory
+// until Close.
+type PDFWriter struct {
+	out       io.Writer
+	pdf       *gofpdf.Fpdf
+	translate func(string) string
+	columns   []string
+	widths    []float64
+}
+
+func newPDFWriter(w io.Writer, title string) *PDFWriter {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+
+	pw := &PDFWriter{
+		out:       w,
+		pdf:       pdf,
+		translate: pdf.UnicodeTranslatorFromDescriptor(""),
+	}
+
+	pdf.SetHeaderFunc(func() {
+		if pdf.PageNo() == 1 {
+			pdf.SetFont("Arial", "B", 12)
+			pdf.CellFormat(0, 8, pw.translate(title), "", 1, "L", false, 0, "")
+			pdf.SetFont("Arial", "", pdfFontSize)
+			pdf.CellFormat(0, pdfLineHeight, "Generated "+time.Now().Format("02 Jan 2006 15:04"), "", 1, "L", false, 0, "")
+			pdf.Ln(2)
+		}
+		pw.writeColumns()
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin)
+		pdf.SetFont("Arial", "", pdfFontSize)
+		pdf.CellFormat(0, pdfLineHeight, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AliasNbPages("{nb}")
+
+	return pw
+}
+
+func (pw *PDFWriter) WriteHeader(columns []string) error {
+	pw.columns = columns
+
+	pageWidth, _ := pw.pdf.GetPageSize()
+	width := (pageWidth - 2*pdfMargin) / float64(len(columns))
+	pw.widths = make([]float64, len(columns))
+	for i := range pw.widths {
+		pw.widths[i] = width
+	}
+
+	pw.pdf.AddPage()
+	return pw.pdf.Error()
+}
+
+func (pw *PDFWriter) WriteRow(values []string) error {
+	pw.pdf.SetFont("Arial", "", pdfFontSize)
+	for i, width := range pw.widths {
+		value := ""
+		if i < len(values) {
+			value = pw.fit(pw.translate(strings.ReplaceAll(values[i], "→", "->")), width)
+		}
+		pw.pdf.CellFormat(width, pdfLineHeight, value, "1", 0, "L", false, 0, "")
+	}
+	pw.pdf.Ln(-1)
+
+	return pw.pdf.Error()
+}
+
+func (pw *PDFWriter) Close() error {
+	if pw.pdf.PageNo() == 0 {
+		pw.pdf.AddPage()
+	}
+
+	return pw.pdf.Output(pw.out)
+}
+
+func (pw *PDFWriter) writeColumns() {
+	if len(pw.columns) == 0 {
+		return
+	}
+
+	pw.pdf.SetFont("Arial", "B", pdfFontSize)
+	pw.pdf.SetFillColor(230, 230, 230)
+	for i, column := range pw.columns {
+		pw.pdf.CellFormat(pw.widths[i], pdfLineHeight+1, pw.fit(pw.translate(column), pw.widths[i]), "1", 0, "L", true, 0, "")
+	}
+	pw.pdf.Ln(-1)
+	pw.pdf.SetFont("Arial", "", pdfFontSize)
+}
+
+// fit truncates an already translated (single byte) value so it stays inside
+// a cell of the given width.
+func (pw *PDFWriter) fit(value string, width float64) string {
+	limit := width - 2
+	if pw.pdf.GetStringWidth(value) <= limit {
+		return value
+	}
+
+	for len(value) > 0 && pw.pdf.GetStringWidth(value+"...") > limit {
+		value = value[:len(value)-1]
+	}
+
+	return value + "..."
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+	PDF  Format = "pdf"
+)
+
+type (
+	// IWriter writes a tabular report row by row so callers can feed it
+	// from batched queries instead of loading every record first.
+	IWriter interface {
+		WriteHeader(columns []string) error
+		WriteRow(values []string) error
+		Close() error
+	}
+)
+
+func IsValidFormat(format Format) bool {
+	switch format {
+	case CSV, XLSX, PDF:
+		return true
+	}
+	return false
+}
+
+// ContentType returns the MIME type of the format.
+func ContentType(format Format) string {
+	switch format {
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case PDF:
+		return "application/pdf"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// FileName builds the attachment name of a report, e.g. packages_20250701.csv.
+func FileName(name, suffix string, format Format) string {
+	return fmt.Sprintf("%s_%s.%s", name, suffix, format)
+}
+
+// NewWriter returns the writer of the format, title is printed on top of PDF
+// reports and used as the sheet name for XLSX.
+func NewWriter(format Format, w io.Writer, title string) (IWriter, error) {
+	switch Format(strings.ToLower(string(format))) {
+	case CSV:
+		return newCSVWriter(w), nil
+	case XLSX:
+		return newXLSXWriter(w, title)
+	case PDF:
+		return newPDFWriter(w, title), nil
+	default:
+		return nil, fmt.Errorf("unsupported report format %q", format)
+	}
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXWriter uses the excelize stream writer, rows are spilled to a temporary
+// file instead of being kept in memory.
+type XLSXWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, title string) (*XLSXWriter, error) {
+	file := excelize.NewFile()
+
+	sheet := file.GetSheetName(0)
+	if title != "" {
+		if len(title) > 31 {
+			title = title[:31]
+		}
+		if err := file.SetSheetName(sheet, title); err != nil {
+			return nil, err
+		}
+		sheet = title
+	}
+
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	return &XLSXWriter{
+		out:    w,
+		file:   file,
+		stream: stream,
+	}, nil
+}
+
+func (xw *XLSXWriter) WriteHeader(columns []string) error {
+	if err := xw.stream.SetPanes(&excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+
+	return xw.WriteRow(columns)
+}
+
+func (xw *XLSXWriter) WriteRow(values []string) error {
+	xw.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+
+	row := make([]interface{}, len(values))
+	for i, value := range values {
+		row[i] = value
+	}
+
+	return xw.stream.SetRow(cell, row)
+}
+
+func (xw *XLSXWriter) Close() error {
+	defer xw.file.Close()
+
+	if err := xw.stream.Flush(); err != nil {
+		return err
+	}
+
+	return xw.file.Write(xw.out)
+}
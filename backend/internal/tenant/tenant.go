@@ -0,0 +1,55 @@
+package tenant
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type scopeKey struct{}
+
+// WithCompanies limits every scoped query run with the returned context to
+// rows of the given companies. An empty list matches nothing.
+func WithCompanies(ctx context.Context, companyIDs []uuid.UUID) context.Context {
+	if companyIDs == nil {
+		companyIDs = []uuid.UUID{}
+	}
+
+	return context.WithValue(ctx, scopeKey{}, companyIDs)
+}
+
+// WithoutScope lifts the company scope of ctx, for the few lookups that must
+// see every company such as checking that an email is not taken.
+func WithoutScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, nil)
+}
+
+// Companies returns the companies ctx is limited to, ok is false when it is
+// not limited at all.
+func Companies(ctx context.Context) ([]uuid.UUID, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	companyIDs, ok := ctx.Value(scopeKey{}).([]uuid.UUID)
+
+	return companyIDs, ok
+}
+
+// Key identifies the scope of ctx, for caches shared between scopes.
+func Key(ctx context.Context) string {
+	companyIDs, ok := Companies(ctx)
+	if !ok {
+		return "*"
+	}
+
+	ids := make([]string, 0, len(companyIDs))
+	for _, id := range companyIDs {
+		ids = append(ids, id.String())
+	}
+	sort.Strings(ids)
+
+	return strings.Join(ids, ",")
+}
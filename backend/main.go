@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
 
 	"github.com/Amierza/TitipanQ/backend/cmd"
 	"github.com/Amierza/TitipanQ/backend/config/database"
+	"github.com/Amierza/TitipanQ/backend/entity"
 	"github.com/Amierza/TitipanQ/backend/handler"
+	"github.com/Amierza/TitipanQ/backend/internal/mail"
+	"github.com/Amierza/TitipanQ/backend/internal/notification"
 	// "github.com/Amierza/TitipanQ/backend/internal/openai"
 	// "github.com/Amierza/TitipanQ/backend/internal/whatsapp"
 	"github.com/Amierza/TitipanQ/backend/middleware"
@@ -26,14 +31,50 @@ func main() {
 		return
 	}
 
+	if err := repository.RegisterCompanyScope(db); err != nil {
+		log.Fatalf("failed to register company scope: %v", err)
+	}
+
+	// NOTIFICATION_DRIVER=log keeps every notification in memory and the logs,
+	// so the backend can run without a paired WhatsApp device.
+	var notifiers map[entity.NotificationChannel]notification.INotifier
+	if os.Getenv("NOTIFICATION_DRIVER") == "log" {
+		logNotifier := notification.NewLogNotifier()
+		notifiers = map[entity.NotificationChannel]notification.INotifier{
+			entity.WhatsAppChannel: logNotifier,
+			entity.EmailChannel:    logNotifier,
+		}
+	} else {
+		notifiers = map[entity.NotificationChannel]notification.INotifier{
+			entity.WhatsAppChannel: notification.NewWhatsAppNotifier(),
+			entity.EmailChannel:    notification.NewEmailNotifier(),
+		}
+	}
+
 	var (
-		jwtService = service.NewJWTService()
+		jwtService     = service.NewJWTService()
+		notifier       = notification.NewDispatcher(notifiers)
+		mailer         = mail.New()
+		sessionRepo    = repository.NewSessionRepository(db)
+		sessionService = service.NewSessionService(sessionRepo, jwtService)
+
+		loginAttemptRepo = repository.NewLoginAttemptRepository(db)
+		loginGuard       = service.NewLoginGuardService(loginAttemptRepo)
+
+		auditLogRepo = repository.NewAuditLogRepository(db)
+		auditService = service.NewAuditService(auditLogRepo)
+
+		searchRepo    = repository.NewSearchRepository(db)
+		searchService = service.NewSearchService(searchRepo)
+
+		permissionRepo    = repository.NewPermissionRepository(db)
+		permissionService = service.NewPermissionService(permissionRepo)
 
 		adminRepo    = repository.NewAdminRepository(db)
-		adminService = service.NewAdminService(adminRepo, jwtService)
-		adminHandler = handler.NewAdminHandler(adminService)
+		adminService = service.NewAdminService(adminRepo, jwtService, sessionService, permissionService, loginGuard, notifier)
+		adminHandler = handler.NewAdminHandler(adminService, auditService, searchService)
 		userRepo     = repository.NewUserRepository(db)
-		userService  = service.NewUserService(userRepo, jwtService)
+		userService  = service.NewUserService(userRepo, jwtService, sessionService, loginGuard, mailer, notifiers[entity.WhatsAppChannel])
 		userHandler  = handler.NewUserHandler(userService)
 		// chatbotRepo  = repository.NewChatBotRepository(db)
 	)
@@ -65,10 +106,40 @@ func main() {
 			adminService.LogSuccess("AutoSoftDeletePackages", "Executed successfully")
 		}
 	})
+
+	c.AddFunc("@daily", func() {
+		purged, err := sessionService.PurgeExpiredSessions(context.Background())
+		if err != nil {
+			log.Println("[CRON] PurgeExpiredSessions error:", err)
+			adminService.LogError("PurgeExpiredSessions", err.Error())
+		} else {
+			adminService.LogSuccess("PurgeExpiredSessions", fmt.Sprintf("Purged %d sessions", purged))
+		}
+	})
+
+	c.AddFunc("@daily", func() {
+		purged, err := loginGuard.PurgeLoginAttempts(context.Background())
+		if err != nil {
+			log.Println("[CRON] PurgeLoginAttempts error:", err)
+			adminService.LogError("PurgeLoginAttempts", err.Error())
+		} else {
+			adminService.LogSuccess("PurgeLoginAttempts", fmt.Sprintf("Purged %d login attempts", purged))
+		}
+	})
+
+	c.AddFunc("@every 30s", func() {
+		if err := adminService.DeliverPendingNotifications(); err != nil {
+			log.Println("[CRON] DeliverPendingNotifications error:", err)
+			adminService.LogError("DeliverPendingNotifications", err.Error())
+		}
+	})
 	c.Start()
 
 	server := gin.Default()
-	server.Use(middleware.CORSMiddleware())
+	// services get the *gin.Context itself as often as the request context,
+	// both have to carry the company scope set on the request
+	server.ContextWithFallback = true
+	server.Use(middleware.CORSMiddleware(), middleware.RequestMeta())
 
 	// err := whatsapp.InitClient()
 	// if err != nil {
@@ -79,8 +150,9 @@ func main() {
 	// nlpService := openai.NewChatbotNLPService(os.Getenv("OPENAI_API_KEY"))
 	// whatsapp.InjectNLPService(nlpService)
 
-	routes.User(server, userHandler, jwtService)
-	routes.Admin(server, adminHandler, jwtService)
+	routes.User(server, userHandler, jwtService, sessionService, permissionService)
+	routes.Admin(server, adminHandler, jwtService, sessionService, permissionService, auditService)
+	adminService.RegisterRoutes(routes.Registered(server))
 
 	server.Static("/assets", "./assets")
 
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/Amierza/TitipanQ/backend/service"
+	"github.com/gin-gonic/gin"
+)
+
+// auditBodyLimit caps how much of a response is kept to find a created id,
+// create responses are small and file downloads are never needed.
+const auditBodyLimit = 64 << 10
+
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if w.body.Len()+len(b) <= auditBodyLimit {
+		w.body.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// AuditTrail must run after Authentication, it records every successful
+// mutating request with a snapshot of the touched row before and after it.
+func AuditTrail(auditService service.IAuditService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			ctx.Next()
+			return
+		}
+
+		action, entityType := auditService.Target(ctx.FullPath())
+		entityID := ctx.Param("id")
+		before := auditService.Snapshot(ctx, entityType, entityID)
+
+		writer := &auditResponseWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+
+		ctx.Next()
+
+		status := writer.Status()
+		if status >= http.StatusBadRequest {
+			return
+		}
+
+		if entityID == "" {
+			entityID = auditService.CreatedID(entityType, writer.body.Bytes())
+		}
+
+		auditService.Record(ctx, entity.AuditLog{
+			Action:     action,
+			EntityType: entityType,
+			EntityID:   entityID,
+			Method:     ctx.Request.Method,
+			Path:       ctx.Request.URL.Path,
+			StatusCode: status,
+			Before:     before,
+			After:      auditService.Snapshot(ctx, entityType, entityID),
+		})
+	}
+}
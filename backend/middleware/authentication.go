@@ -10,7 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func Authentication(jwtService service.IJWTService) gin.HandlerFunc {
+func Authentication(jwtService service.IJWTService, sessionService service.ISessionService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
 		if authHeader == "" {
@@ -39,6 +39,26 @@ func Authentication(jwtService service.IJWTService) gin.HandlerFunc {
 			return
 		}
 
+		// refresh tokens only work on the refresh-token endpoints
+		if !jwtService.IsAccessToken(authHeader) {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, dto.ErrNotAccessToken.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			return
+		}
+
+		sessionID, err := jwtService.GetSessionIDByToken(authHeader)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			return
+		}
+
+		if err := sessionService.ValidateSession(ctx, sessionID); err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			return
+		}
+
 		userID, err := jwtService.GetUserIDByToken(authHeader)
 		if err != nil {
 			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, err.Error(), nil)
@@ -46,8 +66,17 @@ func Authentication(jwtService service.IJWTService) gin.HandlerFunc {
 			return
 		}
 
+		roleID, err := jwtService.GetRoleIDByToken(authHeader)
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, res)
+			return
+		}
+
 		ctx.Set("Authorization", authHeader)
 		ctx.Set("user_id", userID)
+		ctx.Set("role_id", roleID)
+		ctx.Set("session_id", sessionID)
 		ctx.Next()
 	}
 }
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/internal/tenant"
+	"github.com/Amierza/TitipanQ/backend/service"
+	"github.com/Amierza/TitipanQ/backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CompanyScope must run after Authentication, it limits the request of a
+// company admin to its companies. The repositories apply the limit to every
+// query made with the request context.
+func CompanyScope(permissionService service.IPermissionService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		companyIDs, scoped, err := permissionService.CompanyScope(ctx, ctx.GetString("user_id"), ctx.GetString("role_id"))
+		if err != nil {
+			res := utils.BuildResponseFailed(dto.MESSAGE_FAILED_PROSES_REQUEST, err.Error(), nil)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, res)
+			return
+		}
+
+		if scoped {
+			ctx.Request = ctx.Request.WithContext(tenant.WithCompanies(ctx.Request.Context(), companyIDs))
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMeta exposes the client IP and user agent to the services through the
+// request context, for sessions and audit records.
+func RequestMeta() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set("client_ip", ctx.ClientIP())
+		ctx.Set("user_agent", ctx.Request.UserAgent())
+		ctx.Next()
+	}
+}
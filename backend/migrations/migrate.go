@@ -6,6 +6,9 @@ import (
 )
 
 func Migrate(db *gorm.DB) error {
+	// accounts created before email verification existed count as verified
+	backfillEmailVerified := db.Migrator().HasTable(&entity.User{}) && !db.Migrator().HasColumn(&entity.User{}, "EmailVerifiedAt")
+
 	if err := db.AutoMigrate(
 		&entity.Role{},
 		&entity.Permission{},
@@ -13,13 +16,32 @@ func Migrate(db *gorm.DB) error {
 		&entity.Sender{},
 		&entity.User{},
 		&entity.Locker{},
+		&entity.LockerSlot{},
 		&entity.UserCompany{},
+		&entity.UserSession{},
+		&entity.UserToken{},
+		&entity.LoginOTP{},
+		&entity.LoginAttempt{},
 		&entity.Package{},
+		&entity.PickupDelegation{},
 		&entity.PackageHistory{},
 		&entity.CronLog{},
+		&entity.NotificationOutbox{},
+		&entity.RetentionPolicy{},
+		&entity.AuditLog{},
 	); err != nil {
 		return err
 	}
 
+	if err := migrateSearch(db); err != nil {
+		return err
+	}
+
+	if backfillEmailVerified {
+		if err := db.Model(&entity.User{}).Where("email_verified_at IS NULL").Update("email_verified_at", gorm.Expr("created_at")).Error; err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -7,12 +7,22 @@ import (
 
 func Rollback(db *gorm.DB) error {
 	tables := []interface{}{
+		&entity.AuditLog{},
+		&entity.RetentionPolicy{},
+		&entity.NotificationOutbox{},
 		&entity.CronLog{},
 		&entity.PackageHistory{},
+		"pickup_delegation_packages",
+		&entity.PickupDelegation{},
 		&entity.Package{},
+		&entity.LoginAttempt{},
+		&entity.LoginOTP{},
+		&entity.UserToken{},
+		&entity.UserSession{},
 		&entity.User{},
 		&entity.Company{},
 		&entity.Sender{},
+		&entity.LockerSlot{},
 		&entity.Locker{},
 		&entity.UserCompany{},
 		&entity.Permission{},
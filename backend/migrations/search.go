@@ -0,0 +1,49 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// searchStatements back the global search. Every searchable table gets a
+// generated, weighted search_vector with a GIN index, and the columns people
+// type in part (tracking codes and phone numbers) get trigram indexes.
+// All of them can run again on every migrate.
+var searchStatements = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+
+	`ALTER TABLE packages ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('simple', coalesce(tracking_code, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+	) STORED`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(email, '') || ' ' || coalesce(phone_number, '')), 'B') ||
+		setweight(to_tsvector('simple', coalesce(address, '')), 'C')
+	) STORED`,
+	`ALTER TABLE senders ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(phone_number, '')), 'B') ||
+		setweight(to_tsvector('simple', coalesce(address, '')), 'C')
+	) STORED`,
+	`ALTER TABLE companies ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(address, '')), 'C')
+	) STORED`,
+
+	`CREATE INDEX IF NOT EXISTS idx_packages_search_vector ON packages USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_users_search_vector ON users USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_senders_search_vector ON senders USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_companies_search_vector ON companies USING GIN (search_vector)`,
+
+	`CREATE INDEX IF NOT EXISTS idx_packages_tracking_code_trgm ON packages USING GIN (tracking_code gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_users_phone_number_trgm ON users USING GIN (phone_number gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_senders_phone_number_trgm ON senders USING GIN (phone_number gin_trgm_ops)`,
+}
+
+func migrateSearch(db *gorm.DB) error {
+	for _, statement := range searchStatements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
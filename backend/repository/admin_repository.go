@@ -8,8 +8,10 @@ import (
 
 	"github.com/Amierza/TitipanQ/backend/dto"
 	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/Amierza/TitipanQ/backend/internal/tenant"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type (
@@ -17,28 +19,66 @@ type (
 		// Get
 		GetRoleByName(ctx context.Context, tx *gorm.DB, roleName string) (entity.Role, bool, error)
 		GetRoleByID(ctx context.Context, tx *gorm.DB, roleID string) (entity.Role, error)
-		GetPermissionsByRoleID(ctx context.Context, tx *gorm.DB, roleID string) ([]string, bool, error)
 		GetUserByEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error)
 		GetUserByID(ctx context.Context, tx *gorm.DB, userID string) (entity.User, bool, error)
+		GetUserByPhoneNumber(ctx context.Context, tx *gorm.DB, phoneNumber string) (entity.User, bool, error)
 		GetAllUser(ctx context.Context) ([]entity.User, error)
 		GetAllUserWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest) (dto.UserPaginationRepositoryResponse, error)
+		GetAllUserWithCursor(ctx context.Context, tx *gorm.DB, req dto.CursorRequest) (dto.UserCursorRepositoryResponse, error)
 		GetPackageByID(ctx context.Context, tx *gorm.DB, pkgID string) (entity.Package, bool, error)
+		LockPackageByID(ctx context.Context, tx *gorm.DB, pkgID string) (entity.Package, bool, error)
 		GetPackageByTrackingCode(ctx context.Context, tx *gorm.DB, trackingCode string) (entity.Package, bool, error)
-		GetAllPackage(ctx context.Context, tx *gorm.DB, userID, pkgType string) ([]entity.Package, error)
-		GetAllPackageWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest, userID, pkgType string) (dto.PackagePaginationRepositoryResponse, error)
+		GetPackagesByIDs(ctx context.Context, tx *gorm.DB, pkgIDs []uuid.UUID) ([]entity.Package, error)
+		GetAllPackage(ctx context.Context, tx *gorm.DB, filter dto.PackageFilter) ([]entity.Package, error)
+		GetAllPackageWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest, filter dto.PackageFilter) (dto.PackagePaginationRepositoryResponse, error)
+		GetAllPackageWithCursor(ctx context.Context, tx *gorm.DB, req dto.CursorRequest, filter dto.PackageFilter) (dto.PackageCursorRepositoryResponse, error)
 		GetAllPackageHistory(ctx context.Context, tx *gorm.DB, pkgID string) ([]entity.PackageHistory, error)
+		GetAllPackageHistoryWithCursor(ctx context.Context, tx *gorm.DB, pkgID string, req dto.CursorRequest) (dto.PackageHistoryCursorRepositoryResponse, error)
+		StreamPackages(ctx context.Context, tx *gorm.DB, filter dto.ExportPackageRequest, fn func([]entity.Package) error) error
+		StreamPackageHistories(ctx context.Context, tx *gorm.DB, filter dto.ExportPackageRequest, fn func([]entity.PackageHistory) error) error
+
+		// Stats
+		CountPackages(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) (int64, error)
+		CountOverduePackages(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest, now time.Time) (int64, error)
+		GetAverageTimeToPickup(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) (float64, error)
+		CountPackagesByStatus(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) ([]dto.StatsBucket, error)
+		CountPackagesByType(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) ([]dto.StatsBucket, error)
+		CountPackagesByCompany(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) ([]dto.StatsBucket, error)
+		CountPackagesByLocker(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) ([]dto.StatsBucket, error)
+		CountPackagesReceivedPerPeriod(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) ([]dto.StatsPoint, error)
+		GetTopRecipients(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) ([]dto.TopRecipientResponse, error)
 		GetCompanyByID(ctx context.Context, tx *gorm.DB, companyID string) (entity.Company, bool, error)
+		GetCompanyMembers(ctx context.Context, tx *gorm.DB, companyID string, contactsOnly bool) ([]entity.User, error)
+		IsCompanyMember(ctx context.Context, tx *gorm.DB, companyID, userID uuid.UUID) (bool, error)
 		GetAllCompany(ctx context.Context, tx *gorm.DB) ([]entity.Company, error)
 		GetAllCompanyWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest) (dto.CompanyPaginationRepositoryResponse, error)
 		GetAllUnclaimedPackages() ([]*entity.Package, error)
-		GetAllExpiredPackagesBefore(cutoff time.Time, out *[]entity.Package) error
+		GetAllExpiredPackages(out *[]entity.Package) error
 		GetAllLockerWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest) (dto.LockerPaginationRepositoryResponse, error)
 		GetAllLocker(ctx context.Context, tx *gorm.DB) ([]entity.Locker, error)
 		GetLockerByID(ctx context.Context, tx *gorm.DB, lockerID string) (entity.Locker, bool, error)
 		GetLockerByLockerCode(ctx context.Context, tx *gorm.DB, lockerCode string) (entity.Locker, bool, error)
+		LockLockerByID(ctx context.Context, tx *gorm.DB, lockerID string) (entity.Locker, bool, error)
+		GetAllLockerSlot(ctx context.Context, tx *gorm.DB, lockerID string) ([]entity.LockerSlot, error)
+		GetLockerSlotByID(ctx context.Context, tx *gorm.DB, slotID string) (entity.LockerSlot, bool, error)
+		GetLockerSlotByCode(ctx context.Context, tx *gorm.DB, lockerID uuid.UUID, slotCode string) (entity.LockerSlot, bool, error)
+		GetLockerSlotUsage(ctx context.Context, tx *gorm.DB, lockerID string, excludePkgID *uuid.UUID) (map[uuid.UUID]int64, error)
 		GetAllSender(ctx context.Context, tx *gorm.DB) ([]entity.Sender, error)
 		GetSenderByID(ctx context.Context, tx *gorm.DB, senderID string) (entity.Sender, bool, error)
+		GetSenderByName(ctx context.Context, tx *gorm.DB, name string) (entity.Sender, bool, error)
 		GetAllSenderWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest) (dto.SenderPaginationRepositoryResponse, error)
+		GetAllSenderWithCursor(ctx context.Context, tx *gorm.DB, req dto.CursorRequest) (dto.SenderCursorRepositoryResponse, error)
+		GetNotificationByID(ctx context.Context, tx *gorm.DB, notificationID string) (entity.NotificationOutbox, bool, error)
+		GetAllNotificationWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest, status string) (dto.NotificationPaginationRepositoryResponse, error)
+		LockNotificationByID(ctx context.Context, tx *gorm.DB, notificationID string) (entity.NotificationOutbox, bool, error)
+		GetDueNotifications(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]entity.NotificationOutbox, error)
+		ClaimNotifications(ctx context.Context, tx *gorm.DB, notificationIDs []uuid.UUID, leaseUntil time.Time) error
+		GetAllRetentionPolicy(ctx context.Context, tx *gorm.DB) ([]entity.RetentionPolicy, error)
+		GetRetentionPolicyByID(ctx context.Context, tx *gorm.DB, policyID string) (entity.RetentionPolicy, bool, error)
+		GetRetentionPolicyByScope(ctx context.Context, tx *gorm.DB, companyID *uuid.UUID, pkgType entity.Type) (entity.RetentionPolicy, bool, error)
+		ResolveRetentionPolicy(ctx context.Context, tx *gorm.DB, userID, companyID *uuid.UUID, pkgType entity.Type) (entity.RetentionPolicy, error)
+		GetPickupDelegationByID(ctx context.Context, tx *gorm.DB, delegationID string) (entity.PickupDelegation, bool, error)
+		GetAllActivePickupDelegationByPackage(ctx context.Context, tx *gorm.DB, pkg entity.Package, now time.Time) ([]entity.PickupDelegation, error)
 
 		//Create
 		CreateUser(ctx context.Context, tx *gorm.DB, user entity.User) error
@@ -46,28 +86,59 @@ type (
 		CreatePackageHistory(ctx context.Context, tx *gorm.DB, history entity.PackageHistory) error
 		CreateCompany(ctx context.Context, tx *gorm.DB, company entity.Company) error
 		CreateLocker(ctx context.Context, tx *gorm.DB, locker entity.Locker) error
+		CreateLockerSlot(ctx context.Context, tx *gorm.DB, slot entity.LockerSlot) error
 		CreateSender(ctx context.Context, tx *gorm.DB, sender entity.Sender) error
 		CreateLog(tx *gorm.DB, cron *entity.CronLog) error
 		CreateUserCompany(ctx context.Context, tx *gorm.DB, uc entity.UserCompany) error
+		CreateNotification(ctx context.Context, tx *gorm.DB, notification entity.NotificationOutbox) error
+		CreateRetentionPolicy(ctx context.Context, tx *gorm.DB, policy entity.RetentionPolicy) error
 
 		// Update
 		UpdateUser(ctx context.Context, tx *gorm.DB, user entity.User) error
-		UpdatePackage(ctx context.Context, tx *gorm.DB, pkg entity.Package) error
-		UpdateStatusPackage(ctx context.Context, tx *gorm.DB, pkgID string, newStatus string, proofImage string) error
+		UpdatePackage(ctx context.Context, tx *gorm.DB, pkgID uuid.UUID, updates map[string]interface{}) error
+		UpdateStatusPackage(ctx context.Context, tx *gorm.DB, pkgID uuid.UUID, newStatus entity.Status, proofImage string, completedAt *time.Time) error
 		UpdateCompany(ctx context.Context, tx *gorm.DB, company entity.Company) error
-		UpdatePackageStatusToExpired(id uuid.UUID, status entity.Status, now *time.Time) error
-		UpdateSoftDeletePackage(id uuid.UUID, deletedAt time.Time) error
+		UpdateCompanyContacts(ctx context.Context, tx *gorm.DB, companyID uuid.UUID, userIDs []uuid.UUID) error
+		UpdatePackageStatusToExpired(tx *gorm.DB, id uuid.UUID, status entity.Status, now *time.Time) error
+		UpdateSoftDeletePackage(tx *gorm.DB, id uuid.UUID, deletedAt time.Time) error
 		UpdateLocker(ctx context.Context, tx *gorm.DB, locker entity.Locker) error
+		UpdateLockerSlot(ctx context.Context, tx *gorm.DB, slot entity.LockerSlot) error
+		UpdatePackageSlot(ctx context.Context, tx *gorm.DB, pkgID uuid.UUID, slotID *uuid.UUID) error
 		UpdateSender(ctx context.Context, tx *gorm.DB, sender entity.Sender) error
-		UpdateLastReminderSentAt(id string, now *time.Time) error
+		UpdateLastReminderSentAt(tx *gorm.DB, id string, now *time.Time) error
+		UpdatePickupCode(ctx context.Context, tx *gorm.DB, pkgID uuid.UUID, pickupCode string, attempts int) error
+		UpdateNotification(ctx context.Context, tx *gorm.DB, notification entity.NotificationOutbox) error
+		UpdateClaimedNotification(ctx context.Context, tx *gorm.DB, notification entity.NotificationOutbox, leaseUntil time.Time) (bool, error)
+		UpdateRetentionPolicy(ctx context.Context, tx *gorm.DB, policy entity.RetentionPolicy) error
 
 		// Delete
 		DeleteUserByID(ctx context.Context, tx *gorm.DB, userID string) error
 		DeletePackageByID(ctx context.Context, tx *gorm.DB, pkgID string) error
 		DeleteCompanyByID(ctx context.Context, tx *gorm.DB, CompanyID string) error
 		DeleteLockerByID(ctx context.Context, tx *gorm.DB, lockerID string) error
+		DeleteLockerSlotByID(ctx context.Context, tx *gorm.DB, slotID string) error
 		DeleteSenderByID(ctx context.Context, tx *gorm.DB, senderID string) error
 		DeleteUserCompaniesByUserID(ctx context.Context, tx *gorm.DB, userID string) error
+		DeleteRetentionPolicyByID(ctx context.Context, tx *gorm.DB, policyID string) error
+
+		// Role & Permission
+		GetAllRole(ctx context.Context, tx *gorm.DB) ([]entity.Role, error)
+		GetRoleWithPermissionsByID(ctx context.Context, tx *gorm.DB, roleID string) (entity.Role, bool, error)
+		LockRoleByName(ctx context.Context, tx *gorm.DB, roleName string) (entity.Role, bool, error)
+		CountUsersByRole(ctx context.Context, tx *gorm.DB) (map[uuid.UUID]int64, error)
+		CountUsersByRoleID(ctx context.Context, tx *gorm.DB, roleID uuid.UUID) (int64, error)
+		GetPermissionByID(ctx context.Context, tx *gorm.DB, permissionID string) (entity.Permission, bool, error)
+		IsPermissionAttached(ctx context.Context, tx *gorm.DB, roleID uuid.UUID, endpoint, method string) (bool, error)
+		CreateRole(ctx context.Context, tx *gorm.DB, role entity.Role) error
+		CreatePermission(ctx context.Context, tx *gorm.DB, permission entity.Permission) error
+		UpdateRole(ctx context.Context, tx *gorm.DB, role entity.Role) error
+		UpdateUserRole(ctx context.Context, tx *gorm.DB, userID, roleID uuid.UUID) error
+		DeleteRoleByID(ctx context.Context, tx *gorm.DB, roleID uuid.UUID) error
+		DeletePermissionByID(ctx context.Context, tx *gorm.DB, permissionID uuid.UUID) error
+		DeletePermissionsByRoleID(ctx context.Context, tx *gorm.DB, roleID uuid.UUID) error
+
+		// Transaction
+		RunInTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error
 	}
 
 	AdminRepository struct {
@@ -106,37 +177,37 @@ func (ar *AdminRepository) GetRoleByID(ctx context.Context, tx *gorm.DB, roleID
 
 	return role, nil
 }
-func (ar *AdminRepository) GetPermissionsByRoleID(ctx context.Context, tx *gorm.DB, roleID string) ([]string, bool, error) {
+func (ar *AdminRepository) GetUserByEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error) {
 	if tx == nil {
 		tx = ar.db
 	}
 
-	var endpoints []string
-	if err := tx.WithContext(ctx).Table("permissions").Where("role_id = ?", roleID).Pluck("endpoint", &endpoints).Error; err != nil {
-		return []string{}, false, err
+	var user entity.User
+	if err := tx.WithContext(ctx).Preload("UserCompanies.Company").Preload("Role.Permissions").Where("email = ?", email).Take(&user).Error; err != nil {
+		return entity.User{}, false, err
 	}
 
-	return endpoints, true, nil
+	return user, true, nil
 }
-func (ar *AdminRepository) GetUserByEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error) {
+func (ar *AdminRepository) GetUserByID(ctx context.Context, tx *gorm.DB, userID string) (entity.User, bool, error) {
 	if tx == nil {
 		tx = ar.db
 	}
 
 	var user entity.User
-	if err := tx.WithContext(ctx).Preload("UserCompanies.Company").Preload("Role").Where("email = ?", email).Take(&user).Error; err != nil {
+	if err := tx.WithContext(ctx).Preload("UserCompanies.Company").Preload("Role.Permissions").Where("id = ?", userID).Take(&user).Error; err != nil {
 		return entity.User{}, false, err
 	}
 
 	return user, true, nil
 }
-func (ar *AdminRepository) GetUserByID(ctx context.Context, tx *gorm.DB, userID string) (entity.User, bool, error) {
+func (ar *AdminRepository) GetUserByPhoneNumber(ctx context.Context, tx *gorm.DB, phoneNumber string) (entity.User, bool, error) {
 	if tx == nil {
 		tx = ar.db
 	}
 
 	var user entity.User
-	if err := tx.WithContext(ctx).Preload("UserCompanies.Company").Preload("Role").Where("id = ?", userID).Take(&user).Error; err != nil {
+	if err := tx.WithContext(ctx).Preload("UserCompanies.Company").Preload("Role").Where("phone_number = ?", phoneNumber).Take(&user).Error; err != nil {
 		return entity.User{}, false, err
 	}
 
@@ -154,11 +225,40 @@ func (ar *AdminRepository) GetCompanyByID(ctx context.Context, tx *gorm.DB, comp
 
 	return company, true, nil
 }
+func (ar *AdminRepository) GetCompanyMembers(ctx context.Context, tx *gorm.DB, companyID string, contactsOnly bool) ([]entity.User, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	members := tx.Model(&entity.UserCompany{}).Select("user_id").Where("company_id = ?", companyID)
+	if contactsOnly {
+		members = members.Where("is_contact = ?", true)
+	}
+
+	var users []entity.User
+	if err := tx.WithContext(ctx).Where("id IN (?)", members).Order("name ASC").Find(&users).Error; err != nil {
+		return []entity.User{}, err
+	}
+
+	return users, nil
+}
+func (ar *AdminRepository) IsCompanyMember(ctx context.Context, tx *gorm.DB, companyID, userID uuid.UUID) (bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var count int64
+	if err := tx.WithContext(ctx).Model(&entity.UserCompany{}).Where("company_id = ? AND user_id = ?", companyID, userID).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
 func (ar *AdminRepository) GetAllUser(ctx context.Context) ([]entity.User, error) {
 	var users []entity.User
 
 	var adminIDs []uuid.UUID
-	if err := ar.db.WithContext(ctx).Model(&entity.Role{}).Where("name != ?", "admin").Pluck("id", &adminIDs).Error; err != nil {
+	if err := ar.db.WithContext(ctx).Model(&entity.Role{}).Where("name NOT IN ?", []string{entity.RoleAdmin, entity.RoleCompanyAdmin}).Pluck("id", &adminIDs).Error; err != nil {
 		return nil, err
 	}
 
@@ -176,6 +276,25 @@ func (ar *AdminRepository) GetAllUser(ctx context.Context) ([]entity.User, error
 
 	return users, nil
 }
+
+// userListQuery selects the users shown in the paginated user lists, admins
+// are left out.
+func userListQuery(ctx context.Context, tx *gorm.DB, search string) (*gorm.DB, error) {
+	var adminIDs []uuid.UUID
+	if err := tx.WithContext(ctx).Model(&entity.Role{}).Where("name NOT IN ?", []string{entity.RoleAdmin, entity.RoleCompanyAdmin}).Pluck("id", &adminIDs).Error; err != nil {
+		return nil, err
+	}
+
+	query := tx.WithContext(ctx).Model(&entity.User{}).Where("role_id IN (?)", adminIDs)
+
+	if search != "" {
+		searchValue := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(email) LIKE ?", searchValue, searchValue)
+	}
+
+	return query.Preload("UserCompanies.Company").Preload("Role"), nil
+}
+
 func (ar *AdminRepository) GetAllUserWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest) (dto.UserPaginationRepositoryResponse, error) {
 	if tx == nil {
 		tx = ar.db
@@ -193,20 +312,11 @@ func (ar *AdminRepository) GetAllUserWithPagination(ctx context.Context, tx *gor
 		req.Page = 1
 	}
 
-	var adminIDs []uuid.UUID
-	if err := tx.WithContext(ctx).Model(&entity.Role{}).Where("name != ?", "admin").Pluck("id", &adminIDs).Error; err != nil {
+	query, err := userListQuery(ctx, tx, req.Search)
+	if err != nil {
 		return dto.UserPaginationRepositoryResponse{}, err
 	}
 
-	query := tx.WithContext(ctx).Model(&entity.User{}).Where("role_id IN (?)", adminIDs)
-
-	if req.Search != "" {
-		searchValue := "%" + strings.ToLower(req.Search) + "%"
-		query = query.Where("LOWER(name) LIKE ? OR LOWER(email) LIKE ?", searchValue, searchValue)
-	}
-
-	query = query.Preload("UserCompanies.Company").Preload("Role")
-
 	if err := query.Count(&count).Error; err != nil {
 		return dto.UserPaginationRepositoryResponse{}, err
 	}
@@ -227,6 +337,28 @@ func (ar *AdminRepository) GetAllUserWithPagination(ctx context.Context, tx *gor
 		},
 	}, err
 }
+func (ar *AdminRepository) GetAllUserWithCursor(ctx context.Context, tx *gorm.DB, req dto.CursorRequest) (dto.UserCursorRepositoryResponse, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	query, err := userListQuery(ctx, tx, req.Search)
+	if err != nil {
+		return dto.UserCursorRepositoryResponse{}, err
+	}
+
+	users, page, err := paginateCursor(query, "users", req, func(user entity.User) (time.Time, uuid.UUID) {
+		return user.CreatedAt, user.ID
+	})
+	if err != nil {
+		return dto.UserCursorRepositoryResponse{}, err
+	}
+
+	return dto.UserCursorRepositoryResponse{
+		Users:          users,
+		CursorResponse: page,
+	}, nil
+}
 func (ar *AdminRepository) GetPackageByID(ctx context.Context, tx *gorm.DB, pkgID string) (entity.Package, bool, error) {
 	if tx == nil {
 		tx = ar.db
@@ -237,8 +369,10 @@ func (ar *AdminRepository) GetPackageByID(ctx context.Context, tx *gorm.DB, pkgI
 		Model(&entity.Package{}).
 		Preload("User.UserCompanies.Company").
 		Preload("User.Role").
+		Preload("Company").
 		Preload("Sender").
 		Preload("Locker").
+		Preload("Slot").
 		Where("id = ?", pkgID).
 		Take(&pkg).Error; err != nil {
 		return entity.Package{}, false, err
@@ -246,93 +380,123 @@ func (ar *AdminRepository) GetPackageByID(ctx context.Context, tx *gorm.DB, pkgI
 
 	return pkg, true, nil
 }
+func (ar *AdminRepository) LockPackageByID(ctx context.Context, tx *gorm.DB, pkgID string) (entity.Package, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var pkg entity.Package
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", pkgID).Take(&pkg).Error; err != nil {
+		return entity.Package{}, false, err
+	}
+
+	return pkg, true, nil
+}
 func (ar *AdminRepository) GetPackageByTrackingCode(ctx context.Context, tx *gorm.DB, trackingCode string) (entity.Package, bool, error) {
 	if tx == nil {
 		tx = ar.db
 	}
 
 	var pkg entity.Package
-	if err := tx.WithContext(ctx).Preload("User.UserCompanies.Company").Preload("User.Role").Where("tracking_code = ?", trackingCode).Take(&pkg).Error; err != nil {
+	if err := tx.WithContext(ctx).Preload("User.UserCompanies.Company").Preload("User.Role").Preload("Company").Where("tracking_code = ?", trackingCode).Take(&pkg).Error; err != nil {
 		return entity.Package{}, false, err
 	}
 
 	return pkg, true, nil
 }
-func (ar *AdminRepository) GetAllPackage(ctx context.Context, tx *gorm.DB, userID, pkgType string) ([]entity.Package, error) {
+func (ar *AdminRepository) GetPackagesByIDs(ctx context.Context, tx *gorm.DB, pkgIDs []uuid.UUID) ([]entity.Package, error) {
 	if tx == nil {
 		tx = ar.db
 	}
 
 	var packages []entity.Package
-
-	query := tx.WithContext(ctx).
+	if err := tx.WithContext(ctx).
 		Model(&entity.Package{}).
 		Preload("User.UserCompanies.Company").
-		Preload("User.Role").
+		Preload("Company").
 		Preload("Locker").
-		Preload("Sender", func(db *gorm.DB) *gorm.DB {
-			return db.Select("id", "name", "address", "phone_number")
-		})
-	if pkgType != "" {
-		query = query.Where("type = ?", pkgType)
-	}
-
-	if userID != "" {
-		query = query.Where("user_id = ?", userID)
-	}
-
-	if err := query.Order("created_at DESC").Find(&packages).Error; err != nil {
+		Preload("Slot").
+		Where("packages.id IN ?", pkgIDs).
+		Find(&packages).Error; err != nil {
 		return []entity.Package{}, err
 	}
 
 	return packages, nil
 }
-func (ar *AdminRepository) GetAllPackageWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest, userID, pkgType string) (dto.PackagePaginationRepositoryResponse, error) {
+func (ar *AdminRepository) GetAllPackage(ctx context.Context, tx *gorm.DB, filter dto.PackageFilter) ([]entity.Package, error) {
 	if tx == nil {
 		tx = ar.db
 	}
 
 	var packages []entity.Package
-	var count int64
 
-	if req.PerPage == 0 {
-		req.PerPage = 10
-	}
+	query := tx.WithContext(ctx).
+		Model(&entity.Package{}).
+		Preload("User.UserCompanies.Company").
+		Preload("User.Role").
+		Preload("Company").
+		Preload("Locker").
+		Preload("Slot").
+		Preload("Sender", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name", "address", "phone_number")
+		})
 
-	if req.Page == 0 {
-		req.Page = 1
+	query = applyPackageFilter(tx, query, filter)
+
+	if err := orderPackages(query, filter).Find(&packages).Error; err != nil {
+		return []entity.Package{}, err
 	}
 
+	return packages, nil
+}
+
+// packageListQuery selects the packages shown in the paginated package lists.
+func packageListQuery(ctx context.Context, tx *gorm.DB, search string, filter dto.PackageFilter) *gorm.DB {
 	query := tx.WithContext(ctx).
 		Model(&entity.Package{}).
 		Preload("User.UserCompanies.Company").
 		Preload("User.Role").
+		Preload("Company").
 		Preload("Locker").
+		Preload("Slot").
 		Preload("Sender", func(db *gorm.DB) *gorm.DB {
 			return db.Select("id", "name", "address", "phone_number")
 		})
 
-	if req.Search != "" {
-		searchValue := "%" + strings.ToLower(req.Search) + "%"
+	if search != "" {
+		searchValue := "%" + strings.ToLower(search) + "%"
 		query = query.Where(`
 			LOWER(tracking_code) LIKE ? OR
 			LOWER(description) LIKE ?`,
 			searchValue, searchValue)
 	}
 
-	if pkgType != "" {
-		query = query.Where("type = ?", pkgType)
+	return applyPackageFilter(tx, query, filter)
+}
+
+func (ar *AdminRepository) GetAllPackageWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest, filter dto.PackageFilter) (dto.PackagePaginationRepositoryResponse, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var packages []entity.Package
+	var count int64
+
+	if req.PerPage == 0 {
+		req.PerPage = 10
 	}
 
-	if userID != "" {
-		query = query.Where("user_id = ?", userID)
+	if req.Page == 0 {
+		req.Page = 1
 	}
 
+	query := packageListQuery(ctx, tx, req.Search, filter)
+
 	if err := query.Count(&count).Error; err != nil {
 		return dto.PackagePaginationRepositoryResponse{}, err
 	}
 
-	if err := query.Order("created_at DESC").
+	if err := orderPackages(query, filter).
 		Scopes(Paginate(req.Page, req.PerPage)).
 		Find(&packages).Error; err != nil {
 		return dto.PackagePaginationRepositoryResponse{}, err
@@ -350,6 +514,25 @@ func (ar *AdminRepository) GetAllPackageWithPagination(ctx context.Context, tx *
 		},
 	}, nil
 }
+func (ar *AdminRepository) GetAllPackageWithCursor(ctx context.Context, tx *gorm.DB, req dto.CursorRequest, filter dto.PackageFilter) (dto.PackageCursorRepositoryResponse, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	query := packageListQuery(ctx, tx, req.Search, filter)
+
+	packages, page, err := paginateCursor(query, "packages", req, func(pkg entity.Package) (time.Time, uuid.UUID) {
+		return pkg.CreatedAt, pkg.ID
+	})
+	if err != nil {
+		return dto.PackageCursorRepositoryResponse{}, err
+	}
+
+	return dto.PackageCursorRepositoryResponse{
+		Packages:       packages,
+		CursorResponse: page,
+	}, nil
+}
 func (ar *AdminRepository) GetAllPackageHistory(ctx context.Context, tx *gorm.DB, pkgID string) ([]entity.PackageHistory, error) {
 	if tx == nil {
 		tx = ar.db
@@ -368,6 +551,295 @@ func (ar *AdminRepository) GetAllPackageHistory(ctx context.Context, tx *gorm.DB
 
 	return packageHistories, err
 }
+func (ar *AdminRepository) GetAllPackageHistoryWithCursor(ctx context.Context, tx *gorm.DB, pkgID string, req dto.CursorRequest) (dto.PackageHistoryCursorRepositoryResponse, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	query := tx.WithContext(ctx).Model(&entity.PackageHistory{}).Preload("ChangedByUser").Where("package_id = ?", pkgID)
+
+	histories, page, err := paginateCursor(query, "package_histories", req, func(history entity.PackageHistory) (time.Time, uuid.UUID) {
+		return history.CreatedAt, history.ID
+	})
+	if err != nil {
+		return dto.PackageHistoryCursorRepositoryResponse{}, err
+	}
+
+	return dto.PackageHistoryCursorRepositoryResponse{
+		PackageHistories: histories,
+		CursorResponse:   page,
+	}, nil
+}
+
+// exportBatchSize is how many rows StreamPackages and StreamPackageHistories
+// hold in memory at once.
+const exportBatchSize = 500
+
+func applyPackageExportFilter(tx *gorm.DB, query *gorm.DB, filter dto.ExportPackageRequest) *gorm.DB {
+	if filter.CompanyID != nil {
+		query = query.Where("packages.company_id = ? OR packages.user_id IN (?)", filter.CompanyID, tx.Model(&entity.UserCompany{}).Select("user_id").Where("company_id = ?", filter.CompanyID))
+	}
+
+	if filter.LockerID != nil {
+		query = query.Where("packages.locker_id = ?", filter.LockerID)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("packages.status = ?", filter.Status)
+	}
+
+	if filter.Type != "" {
+		query = query.Where("packages.type = ?", filter.Type)
+	}
+
+	return query
+}
+
+// StreamPackages hands the packages matching filter to fn in created_at
+// order, one batch at a time, using keyset pagination so late batches stay
+// as cheap as the first.
+func (ar *AdminRepository) StreamPackages(ctx context.Context, tx *gorm.DB, filter dto.ExportPackageRequest, fn func([]entity.Package) error) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var (
+		lastCreatedAt time.Time
+		lastID        uuid.UUID
+	)
+
+	for {
+		query := tx.WithContext(ctx).
+			Model(&entity.Package{}).
+			Preload("User.UserCompanies.Company").
+			Preload("Company").
+			Preload("Locker").
+			Preload("Slot").
+			Preload("Sender")
+
+		query = applyPackageExportFilter(tx.WithContext(ctx), query, filter)
+		if filter.From != nil {
+			query = query.Where("packages.created_at >= ?", filter.From)
+		}
+		if filter.To != nil {
+			query = query.Where("packages.created_at < ?", filter.To)
+		}
+		if lastID != uuid.Nil {
+			query = query.Where("(packages.created_at, packages.id) > (?, ?)", lastCreatedAt, lastID)
+		}
+
+		var packages []entity.Package
+		if err := query.Order("packages.created_at, packages.id").Limit(exportBatchSize).Find(&packages).Error; err != nil {
+			return err
+		}
+		if len(packages) == 0 {
+			return nil
+		}
+
+		if err := fn(packages); err != nil {
+			return err
+		}
+
+		if len(packages) < exportBatchSize {
+			return nil
+		}
+
+		last := packages[len(packages)-1]
+		lastCreatedAt, lastID = last.CreatedAt, last.ID
+	}
+}
+
+// StreamPackageHistories works like StreamPackages for history entries, the
+// date range applies to the entry while the other filters apply to its
+// package, deleted packages included.
+func (ar *AdminRepository) StreamPackageHistories(ctx context.Context, tx *gorm.DB, filter dto.ExportPackageRequest, fn func([]entity.PackageHistory) error) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var (
+		lastCreatedAt time.Time
+		lastID        uuid.UUID
+	)
+
+	for {
+		query := tx.WithContext(ctx).
+			Model(&entity.PackageHistory{}).
+			Joins("JOIN packages ON packages.id = package_histories.package_id").
+			Preload("Package", func(db *gorm.DB) *gorm.DB {
+				return db.Unscoped()
+			}).
+			Preload("ChangedByUser")
+
+		query = applyPackageExportFilter(tx.WithContext(ctx), query, filter)
+		if filter.From != nil {
+			query = query.Where("package_histories.created_at >= ?", filter.From)
+		}
+		if filter.To != nil {
+			query = query.Where("package_histories.created_at < ?", filter.To)
+		}
+		if lastID != uuid.Nil {
+			query = query.Where("(package_histories.created_at, package_histories.id) > (?, ?)", lastCreatedAt, lastID)
+		}
+
+		var histories []entity.PackageHistory
+		if err := query.Order("package_histories.created_at, package_histories.id").Limit(exportBatchSize).Find(&histories).Error; err != nil {
+			return err
+		}
+		if len(histories) == 0 {
+			return nil
+		}
+
+		if err := fn(histories); err != nil {
+			return err
+		}
+
+		if len(histories) < exportBatchSize {
+			return nil
+		}
+
+		last := histories[len(histories)-1]
+		lastCreatedAt, lastID = last.CreatedAt, last.ID
+	}
+}
+
+// statsQuery is the base of every stats aggregation: live packages received
+// within the requested range.
+func (ar *AdminRepository) statsQuery(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) *gorm.DB {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	query := tx.WithContext(ctx).Model(&entity.Package{})
+	if filter.From != nil {
+		query = query.Where("packages.created_at >= ?", filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("packages.created_at < ?", filter.To)
+	}
+
+	return query
+}
+func (ar *AdminRepository) CountPackages(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) (int64, error) {
+	var count int64
+	if err := ar.statsQuery(ctx, tx, filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+func (ar *AdminRepository) CountOverduePackages(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest, now time.Time) (int64, error) {
+	var count int64
+	if err := ar.statsQuery(ctx, tx, filter).
+		Where("packages.status IN ?", entity.OccupyingStatuses).
+		Where("packages.expired_at < ?", now).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+func (ar *AdminRepository) GetAverageTimeToPickup(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) (float64, error) {
+	var seconds float64
+	if err := ar.statsQuery(ctx, tx, filter).
+		Select("COALESCE(AVG(EXTRACT(EPOCH FROM (packages.completed_at - packages.created_at))), 0)").
+		Where("packages.status = ? AND packages.completed_at IS NOT NULL", entity.Completed).
+		Scan(&seconds).Error; err != nil {
+		return 0, err
+	}
+
+	return seconds, nil
+}
+func (ar *AdminRepository) CountPackagesByStatus(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) ([]dto.StatsBucket, error) {
+	var buckets []dto.StatsBucket
+	if err := ar.statsQuery(ctx, tx, filter).
+		Select("packages.status AS key, packages.status AS label, COUNT(*) AS count").
+		Group("packages.status").
+		Order("count DESC").
+		Scan(&buckets).Error; err != nil {
+		return []dto.StatsBucket{}, err
+	}
+
+	return buckets, nil
+}
+func (ar *AdminRepository) CountPackagesByType(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) ([]dto.StatsBucket, error) {
+	var buckets []dto.StatsBucket
+	if err := ar.statsQuery(ctx, tx, filter).
+		Select("packages.type AS key, packages.type AS label, COUNT(*) AS count").
+		Group("packages.type").
+		Order("count DESC").
+		Scan(&buckets).Error; err != nil {
+		return []dto.StatsBucket{}, err
+	}
+
+	return buckets, nil
+}
+
+// CountPackagesByCompany counts a package once for every company its
+// recipient belongs to.
+func (ar *AdminRepository) CountPackagesByCompany(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) ([]dto.StatsBucket, error) {
+	query := ar.statsQuery(ctx, tx, filter).
+		Select("companies.id AS key, companies.name AS label, COUNT(*) AS count").
+		// a package counts for the company it is addressed to, or for every company of its owner
+		Joins("JOIN companies ON companies.deleted_at IS NULL AND (companies.id = packages.company_id OR companies.id IN " +
+			"(SELECT company_id FROM user_companies WHERE user_companies.user_id = packages.user_id AND user_companies.deleted_at IS NULL))")
+	// the scope only filters packages, their owners may belong to other companies as well
+	if companyIDs, scoped := tenant.Companies(ctx); scoped {
+		query = query.Where("companies.id IN ?", companyIDs)
+	}
+
+	var buckets []dto.StatsBucket
+	if err := query.
+		Group("companies.id, companies.name").
+		Order("count DESC").
+		Scan(&buckets).Error; err != nil {
+		return []dto.StatsBucket{}, err
+	}
+
+	return buckets, nil
+}
+func (ar *AdminRepository) CountPackagesByLocker(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) ([]dto.StatsBucket, error) {
+	var buckets []dto.StatsBucket
+	if err := ar.statsQuery(ctx, tx, filter).
+		Select("lockers.id AS key, lockers.locker_code AS label, COUNT(*) AS count").
+		Joins("JOIN lockers ON lockers.id = packages.locker_id").
+		Group("lockers.id, lockers.locker_code").
+		Order("count DESC").
+		Scan(&buckets).Error; err != nil {
+		return []dto.StatsBucket{}, err
+	}
+
+	return buckets, nil
+}
+
+// CountPackagesReceivedPerPeriod buckets packages by filter.Interval, which
+// must already be one of day, week or month.
+func (ar *AdminRepository) CountPackagesReceivedPerPeriod(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) ([]dto.StatsPoint, error) {
+	var points []dto.StatsPoint
+	if err := ar.statsQuery(ctx, tx, filter).
+		Select("date_trunc(?, packages.created_at) AS period, COUNT(*) AS count", filter.Interval).
+		Group("period").
+		Order("period").
+		Scan(&points).Error; err != nil {
+		return []dto.StatsPoint{}, err
+	}
+
+	return points, nil
+}
+func (ar *AdminRepository) GetTopRecipients(ctx context.Context, tx *gorm.DB, filter dto.StatsRequest) ([]dto.TopRecipientResponse, error) {
+	var recipients []dto.TopRecipientResponse
+	if err := ar.statsQuery(ctx, tx, filter).
+		Select("users.id AS user_id, users.name AS name, users.email AS email, COUNT(*) AS total, COUNT(*) FILTER (WHERE packages.status IN ?) AS pending", entity.OccupyingStatuses).
+		Joins("JOIN users ON users.id = packages.user_id").
+		Group("users.id, users.name, users.email").
+		Order("total DESC").
+		Limit(filter.Limit).
+		Scan(&recipients).Error; err != nil {
+		return []dto.TopRecipientResponse{}, err
+	}
+
+	return recipients, nil
+}
 func (ar *AdminRepository) GetAllCompany(ctx context.Context, tx *gorm.DB) ([]entity.Company, error) {
 	if tx == nil {
 		tx = ar.db
@@ -431,9 +903,9 @@ func (ur *AdminRepository) GetAllUnclaimedPackages() ([]*entity.Package, error)
 	err := ur.db.Where("status = ?", "received").Preload("User").Preload("Sender").Find(&pkgs).Error
 	return pkgs, err
 }
-func (ur *AdminRepository) GetAllExpiredPackagesBefore(cutoff time.Time, out *[]entity.Package) error {
+func (ur *AdminRepository) GetAllExpiredPackages(out *[]entity.Package) error {
 	return ur.db.
-		Where("status = ? AND updated_at < ?", entity.Expired, cutoff).
+		Where("status = ?", entity.Expired).
 		Where("deleted_at IS NULL").
 		Find(out).Error
 }
@@ -482,23 +954,42 @@ func (ar *AdminRepository) UpdateUser(ctx context.Context, tx *gorm.DB, user ent
 
 	return tx.WithContext(ctx).Where("id = ?", user.ID).Updates(&user).Error
 }
-func (ar *AdminRepository) UpdatePackage(ctx context.Context, tx *gorm.DB, pkg entity.Package) error {
+
+// UpdatePackage writes only the given columns, so fields owned by other
+// flows (the pickup code, reminders) are never put back from a stale copy.
+func (ar *AdminRepository) UpdatePackage(ctx context.Context, tx *gorm.DB, pkgID uuid.UUID, updates map[string]interface{}) error {
 	if tx == nil {
 		tx = ar.db
 	}
 
-	return tx.WithContext(ctx).Where("id = ?", pkg.ID).Updates(&pkg).Error
+	if len(updates) == 0 {
+		return nil
+	}
+
+	return tx.WithContext(ctx).Model(&entity.Package{ID: pkgID}).Updates(updates).Error
 }
-func (ar *AdminRepository) UpdateStatusPackage(ctx context.Context, tx *gorm.DB, pkgID string, newStatus string, proofImage string) error {
+func (ar *AdminRepository) UpdateStatusPackage(ctx context.Context, tx *gorm.DB, pkgID uuid.UUID, newStatus entity.Status, proofImage string, completedAt *time.Time) error {
 	if tx == nil {
 		tx = ar.db
 	}
 	return tx.WithContext(ctx).
-		Model(&entity.Package{}).
-		Where("id = ?", pkgID).
+		Model(&entity.Package{ID: pkgID}).
+		Updates(map[string]interface{}{
+			"status":       newStatus,
+			"proof_image":  proofImage,
+			"completed_at": completedAt,
+		}).Error
+}
+func (ar *AdminRepository) UpdatePickupCode(ctx context.Context, tx *gorm.DB, pkgID uuid.UUID, pickupCode string, attempts int) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).
+		Model(&entity.Package{ID: pkgID}).
 		Updates(map[string]interface{}{
-			"status":      newStatus,
-			"proof_image": proofImage,
+			"pickup_code":     pickupCode,
+			"pickup_attempts": attempts,
 		}).Error
 }
 func (ar *AdminRepository) UpdateCompany(ctx context.Context, tx *gorm.DB, company entity.Company) error {
@@ -508,20 +999,48 @@ func (ar *AdminRepository) UpdateCompany(ctx context.Context, tx *gorm.DB, compa
 
 	return tx.WithContext(ctx).Where("id = ?", company.ID).Updates(&company).Error
 }
-func (ar *AdminRepository) UpdatePackageStatusToExpired(id uuid.UUID, status entity.Status, now *time.Time) error {
-	return ar.db.Model(&entity.Package{}).Where("id = ?", id).Updates(map[string]interface{}{
+
+// UpdateCompanyContacts makes userIDs the only contacts among the members of
+// the company.
+func (ar *AdminRepository) UpdateCompanyContacts(ctx context.Context, tx *gorm.DB, companyID uuid.UUID, userIDs []uuid.UUID) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	isContact := gorm.Expr("FALSE")
+	if len(userIDs) > 0 {
+		isContact = gorm.Expr("user_id IN ?", userIDs)
+	}
+
+	return tx.WithContext(ctx).Model(&entity.UserCompany{}).Where("company_id = ?", companyID).Update("is_contact", isContact).Error
+}
+func (ar *AdminRepository) UpdatePackageStatusToExpired(tx *gorm.DB, id uuid.UUID, status entity.Status, now *time.Time) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.Model(&entity.Package{ID: id}).Updates(map[string]interface{}{
 		"status":     status,
 		"updated_at": now,
 		"expired_at": now,
 	}).Error
 }
-func (ar *AdminRepository) UpdateSoftDeletePackage(id uuid.UUID, deletedAt time.Time) error {
-	return ar.db.Model(&entity.Package{}).Where("id = ?", id).Updates(map[string]interface{}{
+func (ar *AdminRepository) UpdateSoftDeletePackage(tx *gorm.DB, id uuid.UUID, deletedAt time.Time) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.Model(&entity.Package{ID: id}).Updates(map[string]interface{}{
+		"status":     entity.Deleted,
 		"deleted_at": deletedAt,
 	}).Error
 }
-func (ar *AdminRepository) UpdateLastReminderSentAt(id string, now *time.Time) error {
-	return ar.db.Model(&entity.Package{}).Where("id = ?", id).Updates(map[string]interface{}{
+func (ar *AdminRepository) UpdateLastReminderSentAt(tx *gorm.DB, id string, now *time.Time) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.Model(&entity.Package{}).Where("id = ?", id).Updates(map[string]interface{}{
 		"last_reminder_sent_at": now,
 	}).Error
 }
@@ -647,30 +1166,157 @@ func (ar *AdminRepository) GetLockerByLockerCode(ctx context.Context, tx *gorm.D
 	}
 
 	var locker entity.Locker
-	if err := tx.WithContext(ctx).Where("locker_code = ?", locker).Take(&lockerCode).Error; err != nil {
+	if err := tx.WithContext(ctx).Where("locker_code = ?", lockerCode).Take(&locker).Error; err != nil {
+		return entity.Locker{}, false, err
+	}
+
+	return locker, true, nil
+}
+func (ar *AdminRepository) LockLockerByID(ctx context.Context, tx *gorm.DB, lockerID string) (entity.Locker, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var locker entity.Locker
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", lockerID).Take(&locker).Error; err != nil {
 		return entity.Locker{}, false, err
 	}
 
 	return locker, true, nil
 }
 
-// Sender
-func (ar *AdminRepository) CreateSender(ctx context.Context, tx *gorm.DB, sender entity.Sender) error {
+// Locker Slot
+func (ar *AdminRepository) CreateLockerSlot(ctx context.Context, tx *gorm.DB, slot entity.LockerSlot) error {
 	if tx == nil {
 		tx = ar.db
 	}
 
-	return tx.WithContext(ctx).Create(&sender).Error
+	return tx.WithContext(ctx).Create(&slot).Error
 }
-func (ar *AdminRepository) GetAllSender(ctx context.Context, tx *gorm.DB) ([]entity.Sender, error) {
+func (ar *AdminRepository) GetAllLockerSlot(ctx context.Context, tx *gorm.DB, lockerID string) ([]entity.LockerSlot, error) {
 	if tx == nil {
 		tx = ar.db
 	}
 
-	var (
-		senders []entity.Sender
-		err     error
-	)
+	query := tx.WithContext(ctx).Model(&entity.LockerSlot{}).Preload("Locker")
+	if lockerID != "" {
+		query = query.Where("locker_id = ?", lockerID)
+	}
+
+	var slots []entity.LockerSlot
+	if err := query.Order("slot_code ASC").Find(&slots).Error; err != nil {
+		return []entity.LockerSlot{}, err
+	}
+
+	return slots, nil
+}
+func (ar *AdminRepository) GetLockerSlotByID(ctx context.Context, tx *gorm.DB, slotID string) (entity.LockerSlot, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var slot entity.LockerSlot
+	if err := tx.WithContext(ctx).Preload("Locker").Where("id = ?", slotID).Take(&slot).Error; err != nil {
+		return entity.LockerSlot{}, false, err
+	}
+
+	return slot, true, nil
+}
+func (ar *AdminRepository) GetLockerSlotByCode(ctx context.Context, tx *gorm.DB, lockerID uuid.UUID, slotCode string) (entity.LockerSlot, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var slot entity.LockerSlot
+	if err := tx.WithContext(ctx).Where("locker_id = ? AND slot_code = ?", lockerID, slotCode).Take(&slot).Error; err != nil {
+		return entity.LockerSlot{}, false, err
+	}
+
+	return slot, true, nil
+}
+
+// GetLockerSlotUsage counts the packages still sitting in each slot, keyed by
+// slot id. An empty lockerID counts the slots of every locker.
+func (ar *AdminRepository) GetLockerSlotUsage(ctx context.Context, tx *gorm.DB, lockerID string, excludePkgID *uuid.UUID) (map[uuid.UUID]int64, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	// lockers are shared between companies, a slot is full no matter whose
+	// packages fill it
+	query := tx.WithContext(tenant.WithoutScope(ctx)).
+		Model(&entity.Package{}).
+		Select("slot_id, COUNT(*) AS used").
+		Where("slot_id IS NOT NULL").
+		Where("status IN ?", entity.OccupyingStatuses)
+
+	if lockerID != "" {
+		query = query.Where("slot_id IN (?)", tx.Model(&entity.LockerSlot{}).Select("id").Where("locker_id = ?", lockerID))
+	}
+
+	if excludePkgID != nil {
+		query = query.Where("id <> ?", excludePkgID)
+	}
+
+	var rows []struct {
+		SlotID uuid.UUID
+		Used   int64
+	}
+	if err := query.Group("slot_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	usage := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		usage[row.SlotID] = row.Used
+	}
+
+	return usage, nil
+}
+func (ar *AdminRepository) UpdateLockerSlot(ctx context.Context, tx *gorm.DB, slot entity.LockerSlot) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.LockerSlot{}).Where("id = ?", slot.ID).Updates(map[string]interface{}{
+		"slot_code": slot.SlotCode,
+		"size":      slot.Size,
+		"capacity":  slot.Capacity,
+	}).Error
+}
+func (ar *AdminRepository) UpdatePackageSlot(ctx context.Context, tx *gorm.DB, pkgID uuid.UUID, slotID *uuid.UUID) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.Package{}).Where("id = ?", pkgID).UpdateColumn("slot_id", slotID).Error
+}
+func (ar *AdminRepository) DeleteLockerSlotByID(ctx context.Context, tx *gorm.DB, slotID string) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	// hard delete so the slot code can be reused
+	return tx.WithContext(ctx).Unscoped().Where("id = ?", slotID).Delete(&entity.LockerSlot{}).Error
+}
+
+// Sender
+func (ar *AdminRepository) CreateSender(ctx context.Context, tx *gorm.DB, sender entity.Sender) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Create(&sender).Error
+}
+func (ar *AdminRepository) GetAllSender(ctx context.Context, tx *gorm.DB) ([]entity.Sender, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var (
+		senders []entity.Sender
+		err     error
+	)
 
 	query := tx.WithContext(ctx).Model(&entity.Sender{})
 
@@ -692,6 +1338,31 @@ func (ar *AdminRepository) GetSenderByID(ctx context.Context, tx *gorm.DB, sende
 
 	return sender, true, nil
 }
+func (ar *AdminRepository) GetSenderByName(ctx context.Context, tx *gorm.DB, name string) (entity.Sender, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var sender entity.Sender
+	if err := tx.WithContext(ctx).Where("LOWER(name) = LOWER(?)", name).Order("created_at").Take(&sender).Error; err != nil {
+		return entity.Sender{}, false, err
+	}
+
+	return sender, true, nil
+}
+
+// senderListQuery selects the senders shown in the paginated sender lists.
+func senderListQuery(ctx context.Context, tx *gorm.DB, search string) *gorm.DB {
+	query := tx.WithContext(ctx).Model(&entity.Sender{})
+
+	if search != "" {
+		searchValue := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(phone_number) LIKE ?", searchValue, searchValue)
+	}
+
+	return query
+}
+
 func (ar *AdminRepository) GetAllSenderWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest) (dto.SenderPaginationRepositoryResponse, error) {
 	if tx == nil {
 		tx = ar.db
@@ -707,12 +1378,7 @@ func (ar *AdminRepository) GetAllSenderWithPagination(ctx context.Context, tx *g
 		req.Page = 1
 	}
 
-	query := tx.WithContext(ctx).Model(&entity.Sender{})
-
-	if req.Search != "" {
-		search := "%" + strings.ToLower(req.Search) + "%"
-		query = query.Where("LOWER(locker_code) LIKE ? OR LOWER(location) LIKE ?", search, search)
-	}
+	query := senderListQuery(ctx, tx, req.Search)
 
 	if err := query.Count(&count).Error; err != nil {
 		return dto.SenderPaginationRepositoryResponse{}, err
@@ -732,6 +1398,23 @@ func (ar *AdminRepository) GetAllSenderWithPagination(ctx context.Context, tx *g
 		},
 	}, nil
 }
+func (ar *AdminRepository) GetAllSenderWithCursor(ctx context.Context, tx *gorm.DB, req dto.CursorRequest) (dto.SenderCursorRepositoryResponse, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	senders, page, err := paginateCursor(senderListQuery(ctx, tx, req.Search), "senders", req, func(sender entity.Sender) (time.Time, uuid.UUID) {
+		return sender.CreatedAt, sender.ID
+	})
+	if err != nil {
+		return dto.SenderCursorRepositoryResponse{}, err
+	}
+
+	return dto.SenderCursorRepositoryResponse{
+		Senders:        senders,
+		CursorResponse: page,
+	}, nil
+}
 func (ar *AdminRepository) UpdateSender(ctx context.Context, tx *gorm.DB, sender entity.Sender) error {
 	if tx == nil {
 		tx = ar.db
@@ -764,3 +1447,479 @@ func (ar *AdminRepository) DeleteUserCompaniesByUserID(ctx context.Context, tx *
 		Delete(&entity.UserCompany{}).
 		Error
 }
+
+// Notification
+func (ar *AdminRepository) CreateNotification(ctx context.Context, tx *gorm.DB, notification entity.NotificationOutbox) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Create(&notification).Error
+}
+func (ar *AdminRepository) GetNotificationByID(ctx context.Context, tx *gorm.DB, notificationID string) (entity.NotificationOutbox, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var notification entity.NotificationOutbox
+	if err := tx.WithContext(ctx).Where("id = ?", notificationID).Take(&notification).Error; err != nil {
+		return entity.NotificationOutbox{}, false, err
+	}
+
+	return notification, true, nil
+}
+func (ar *AdminRepository) LockNotificationByID(ctx context.Context, tx *gorm.DB, notificationID string) (entity.NotificationOutbox, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var notification entity.NotificationOutbox
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", notificationID).Take(&notification).Error; err != nil {
+		return entity.NotificationOutbox{}, false, err
+	}
+
+	return notification, true, nil
+}
+func (ar *AdminRepository) GetAllNotificationWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest, status string) (dto.NotificationPaginationRepositoryResponse, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var notifications []entity.NotificationOutbox
+	var count int64
+
+	if req.PerPage == 0 {
+		req.PerPage = 10
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+
+	query := tx.WithContext(ctx).Model(&entity.NotificationOutbox{})
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if req.Search != "" {
+		search := "%" + strings.ToLower(req.Search) + "%"
+		query = query.Where("LOWER(subject) LIKE ? OR LOWER(phone_number) LIKE ? OR LOWER(email) LIKE ?", search, search, search)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return dto.NotificationPaginationRepositoryResponse{}, err
+	}
+
+	if err := query.Order("created_at DESC").Scopes(Paginate(req.Page, req.PerPage)).Find(&notifications).Error; err != nil {
+		return dto.NotificationPaginationRepositoryResponse{}, err
+	}
+
+	return dto.NotificationPaginationRepositoryResponse{
+		Notifications: notifications,
+		PaginationResponse: dto.PaginationResponse{
+			Page:    req.Page,
+			PerPage: req.PerPage,
+			MaxPage: int64(math.Ceil(float64(count) / float64(req.PerPage))),
+			Count:   count,
+		},
+	}, nil
+}
+func (ar *AdminRepository) GetDueNotifications(ctx context.Context, tx *gorm.DB, now time.Time, limit int) ([]entity.NotificationOutbox, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	// SKIP LOCKED lets overlapping worker runs pick disjoint batches, rows
+	// left in sending by a worker that died are picked up once the lease ends.
+	var notifications []entity.NotificationOutbox
+	if err := tx.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where(tx.Where("status IN ? AND next_attempt_at <= ?", []entity.NotificationStatus{entity.NotificationPending, entity.NotificationFailed}, now).
+			Or("status = ? AND lease_until <= ?", entity.NotificationSending, now)).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+func (ar *AdminRepository) UpdateNotification(ctx context.Context, tx *gorm.DB, notification entity.NotificationOutbox) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.NotificationOutbox{}).Where("id = ?", notification.ID).Updates(map[string]interface{}{
+		"status":          notification.Status,
+		"attempts":        notification.Attempts,
+		"next_attempt_at": notification.NextAttemptAt,
+		"last_error":      notification.LastError,
+		"sent_at":         notification.SentAt,
+	}).Error
+}
+func (ar *AdminRepository) ClaimNotifications(ctx context.Context, tx *gorm.DB, notificationIDs []uuid.UUID, leaseUntil time.Time) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.NotificationOutbox{}).Where("id IN ?", notificationIDs).Updates(map[string]interface{}{
+		"status":      entity.NotificationSending,
+		"lease_until": leaseUntil,
+		"attempts":    gorm.Expr("attempts + 1"),
+	}).Error
+}
+func (ar *AdminRepository) UpdateClaimedNotification(ctx context.Context, tx *gorm.DB, notification entity.NotificationOutbox, leaseUntil time.Time) (bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	// a row whose lease ran out may already belong to another worker
+	result := tx.WithContext(ctx).Model(&entity.NotificationOutbox{}).
+		Where("id = ? AND status = ? AND lease_until = ?", notification.ID, entity.NotificationSending, leaseUntil).
+		Updates(map[string]interface{}{
+			"status":          notification.Status,
+			"body":            notification.Body,
+			"next_attempt_at": notification.NextAttemptAt,
+			"lease_until":     nil,
+			"last_error":      notification.LastError,
+			"sent_at":         notification.SentAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Retention Policy
+func (ar *AdminRepository) CreateRetentionPolicy(ctx context.Context, tx *gorm.DB, policy entity.RetentionPolicy) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Create(&policy).Error
+}
+func (ar *AdminRepository) GetAllRetentionPolicy(ctx context.Context, tx *gorm.DB) ([]entity.RetentionPolicy, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var policies []entity.RetentionPolicy
+	if err := tx.WithContext(ctx).Preload("Company").Order("created_at ASC").Find(&policies).Error; err != nil {
+		return []entity.RetentionPolicy{}, err
+	}
+
+	return policies, nil
+}
+func (ar *AdminRepository) GetRetentionPolicyByID(ctx context.Context, tx *gorm.DB, policyID string) (entity.RetentionPolicy, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var policy entity.RetentionPolicy
+	if err := tx.WithContext(ctx).Preload("Company").Where("id = ?", policyID).Take(&policy).Error; err != nil {
+		return entity.RetentionPolicy{}, false, err
+	}
+
+	return policy, true, nil
+}
+func (ar *AdminRepository) GetRetentionPolicyByScope(ctx context.Context, tx *gorm.DB, companyID *uuid.UUID, pkgType entity.Type) (entity.RetentionPolicy, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	query := tx.WithContext(ctx).Where("package_type = ?", pkgType)
+	if companyID == nil {
+		query = query.Where("company_id IS NULL")
+	} else {
+		query = query.Where("company_id = ?", companyID)
+	}
+
+	var policy entity.RetentionPolicy
+	if err := query.Take(&policy).Error; err != nil {
+		return entity.RetentionPolicy{}, false, err
+	}
+
+	return policy, true, nil
+}
+
+// ResolveRetentionPolicy picks the policy for a package owned by userID or
+// addressed to companyID, falling back to entity.DefaultRetentionPolicy when
+// nothing is configured.
+func (ar *AdminRepository) ResolveRetentionPolicy(ctx context.Context, tx *gorm.DB, userID, companyID *uuid.UUID, pkgType entity.Type) (entity.RetentionPolicy, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var companyIDs []uuid.UUID
+	if companyID != nil {
+		companyIDs = []uuid.UUID{*companyID}
+	} else if userID != nil {
+		if err := tx.WithContext(ctx).Model(&entity.UserCompany{}).
+			Where("user_id = ?", userID).
+			Pluck("company_id", &companyIDs).Error; err != nil {
+			return entity.RetentionPolicy{}, err
+		}
+	}
+
+	query := tx.WithContext(ctx).Model(&entity.RetentionPolicy{}).Where("package_type = '' OR package_type = ?", pkgType)
+	if len(companyIDs) > 0 {
+		query = query.Where("company_id IS NULL OR company_id IN ?", companyIDs)
+	} else {
+		query = query.Where("company_id IS NULL")
+	}
+
+	var policies []entity.RetentionPolicy
+	if err := query.Order("created_at ASC").Find(&policies).Error; err != nil {
+		return entity.RetentionPolicy{}, err
+	}
+
+	policy := entity.DefaultRetentionPolicy()
+	best := -1
+	for _, p := range policies {
+		score := 0
+		if p.CompanyID != nil {
+			score += 2
+		}
+		if p.PackageType != "" {
+			score++
+		}
+
+		if score > best {
+			policy = p
+			best = score
+		}
+	}
+
+	return policy, nil
+}
+func (ar *AdminRepository) UpdateRetentionPolicy(ctx context.Context, tx *gorm.DB, policy entity.RetentionPolicy) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.RetentionPolicy{}).Where("id = ?", policy.ID).Updates(map[string]interface{}{
+		"expiry_days":       policy.ExpiryDays,
+		"reminder_days":     policy.ReminderDays,
+		"grace_period_days": policy.GracePeriodDays,
+	}).Error
+}
+func (ar *AdminRepository) DeleteRetentionPolicyByID(ctx context.Context, tx *gorm.DB, policyID string) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	// hard delete so the scope can be configured again
+	return tx.WithContext(ctx).Unscoped().Where("id = ?", policyID).Delete(&entity.RetentionPolicy{}).Error
+}
+
+// Pickup Delegation
+func (ar *AdminRepository) GetPickupDelegationByID(ctx context.Context, tx *gorm.DB, delegationID string) (entity.PickupDelegation, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var delegation entity.PickupDelegation
+	if err := tx.WithContext(ctx).Preload("Packages").Where("id = ?", delegationID).Take(&delegation).Error; err != nil {
+		return entity.PickupDelegation{}, false, err
+	}
+
+	return delegation, true, nil
+}
+func (ar *AdminRepository) GetAllActivePickupDelegationByPackage(ctx context.Context, tx *gorm.DB, pkg entity.Package, now time.Time) ([]entity.PickupDelegation, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	// a company package may be collected on behalf of any member
+	owners := tx.Where("user_id = ?", pkg.UserID)
+	if pkg.CompanyID != nil {
+		owners = tx.Where("user_id IN (?)", tx.Model(&entity.UserCompany{}).Select("user_id").Where("company_id = ?", pkg.CompanyID))
+	}
+
+	var delegations []entity.PickupDelegation
+	if err := tx.WithContext(ctx).
+		Preload("Packages").
+		Where(owners).
+		Where("revoked_at IS NULL AND valid_from <= ? AND valid_until >= ?", now, now).
+		Where("all_packages = ? OR id IN (?)", true,
+			tx.Table("pickup_delegation_packages").Select("pickup_delegation_id").Where("package_id = ?", pkg.ID)).
+		Order("valid_until ASC").
+		Find(&delegations).Error; err != nil {
+		return []entity.PickupDelegation{}, err
+	}
+
+	return delegations, nil
+}
+
+// Role & Permission
+func (ar *AdminRepository) GetAllRole(ctx context.Context, tx *gorm.DB) ([]entity.Role, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var roles []entity.Role
+	if err := tx.WithContext(ctx).
+		Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("endpoint ASC, method ASC")
+		}).
+		Order("name ASC").
+		Find(&roles).Error; err != nil {
+		return []entity.Role{}, err
+	}
+
+	return roles, nil
+}
+func (ar *AdminRepository) GetRoleWithPermissionsByID(ctx context.Context, tx *gorm.DB, roleID string) (entity.Role, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var role entity.Role
+	if err := tx.WithContext(ctx).
+		Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("endpoint ASC, method ASC")
+		}).
+		Where("id = ?", roleID).
+		Take(&role).Error; err != nil {
+		return entity.Role{}, false, err
+	}
+
+	return role, true, nil
+}
+
+// LockRoleByName locks the role row until the caller's transaction ends,
+// changes that could remove the last admin are serialized on the admin role.
+func (ar *AdminRepository) LockRoleByName(ctx context.Context, tx *gorm.DB, roleName string) (entity.Role, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var role entity.Role
+	if err := tx.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("name = ?", roleName).
+		Take(&role).Error; err != nil {
+		return entity.Role{}, false, err
+	}
+
+	return role, true, nil
+}
+func (ar *AdminRepository) CountUsersByRole(ctx context.Context, tx *gorm.DB) (map[uuid.UUID]int64, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var rows []struct {
+		RoleID uuid.UUID
+		Total  int64
+	}
+	if err := tx.WithContext(ctx).
+		Model(&entity.User{}).
+		Select("role_id, COUNT(*) AS total").
+		Where("role_id IS NOT NULL").
+		Group("role_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.RoleID] = row.Total
+	}
+
+	return counts, nil
+}
+func (ar *AdminRepository) CountUsersByRoleID(ctx context.Context, tx *gorm.DB, roleID uuid.UUID) (int64, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var count int64
+	if err := tx.WithContext(ctx).Model(&entity.User{}).Where("role_id = ?", roleID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+func (ar *AdminRepository) GetPermissionByID(ctx context.Context, tx *gorm.DB, permissionID string) (entity.Permission, bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var permission entity.Permission
+	if err := tx.WithContext(ctx).Preload("Role").Where("id = ?", permissionID).Take(&permission).Error; err != nil {
+		return entity.Permission{}, false, err
+	}
+
+	return permission, true, nil
+}
+func (ar *AdminRepository) IsPermissionAttached(ctx context.Context, tx *gorm.DB, roleID uuid.UUID, endpoint, method string) (bool, error) {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	var count int64
+	if err := tx.WithContext(ctx).
+		Model(&entity.Permission{}).
+		Where("role_id = ? AND endpoint = ? AND method = ?", roleID, endpoint, method).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+func (ar *AdminRepository) CreateRole(ctx context.Context, tx *gorm.DB, role entity.Role) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Create(&role).Error
+}
+func (ar *AdminRepository) CreatePermission(ctx context.Context, tx *gorm.DB, permission entity.Permission) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Create(&permission).Error
+}
+func (ar *AdminRepository) UpdateRole(ctx context.Context, tx *gorm.DB, role entity.Role) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.Role{}).Where("id = ?", role.ID).Update("name", role.Name).Error
+}
+func (ar *AdminRepository) UpdateUserRole(ctx context.Context, tx *gorm.DB, userID, roleID uuid.UUID) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.User{}).Where("id = ?", userID).Update("role_id", roleID).Error
+}
+func (ar *AdminRepository) DeleteRoleByID(ctx context.Context, tx *gorm.DB, roleID uuid.UUID) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Where("id = ?", roleID).Delete(&entity.Role{}).Error
+}
+func (ar *AdminRepository) DeletePermissionByID(ctx context.Context, tx *gorm.DB, permissionID uuid.UUID) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Where("id = ?", permissionID).Delete(&entity.Permission{}).Error
+}
+func (ar *AdminRepository) DeletePermissionsByRoleID(ctx context.Context, tx *gorm.DB, roleID uuid.UUID) error {
+	if tx == nil {
+		tx = ar.db
+	}
+
+	return tx.WithContext(ctx).Where("role_id = ?", roleID).Delete(&entity.Permission{}).Error
+}
+
+// Transaction
+func (ar *AdminRepository) RunInTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return WithTransaction(ctx, ar.db, fn)
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"gorm.io/gorm"
+)
+
+type (
+	IAuditLogRepository interface {
+		// Get
+		GetAuditLogByID(ctx context.Context, tx *gorm.DB, auditID string) (entity.AuditLog, bool, error)
+		GetAllAuditLogWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest, filter dto.AuditLogFilter) (dto.AuditLogPaginationRepositoryResponse, error)
+		GetSnapshot(ctx context.Context, tx *gorm.DB, model any, entityID string) (map[string]any, bool, error)
+		GetRoleNameByID(ctx context.Context, tx *gorm.DB, roleID string) (string, error)
+
+		// Create
+		CreateAuditLog(ctx context.Context, tx *gorm.DB, auditLog entity.AuditLog) error
+	}
+
+	AuditLogRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{
+		db: db,
+	}
+}
+
+// Get
+func (alr *AuditLogRepository) GetAuditLogByID(ctx context.Context, tx *gorm.DB, auditID string) (entity.AuditLog, bool, error) {
+	if tx == nil {
+		tx = alr.db
+	}
+
+	var auditLog entity.AuditLog
+	if err := tx.WithContext(ctx).Preload("Actor").Where("id = ?", auditID).Take(&auditLog).Error; err != nil {
+		return entity.AuditLog{}, false, err
+	}
+
+	return auditLog, true, nil
+}
+func (alr *AuditLogRepository) GetAllAuditLogWithPagination(ctx context.Context, tx *gorm.DB, req dto.PaginationRequest, filter dto.AuditLogFilter) (dto.AuditLogPaginationRepositoryResponse, error) {
+	if tx == nil {
+		tx = alr.db
+	}
+
+	var auditLogs []entity.AuditLog
+	var count int64
+
+	if req.PerPage == 0 {
+		req.PerPage = 10
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+
+	query := tx.WithContext(ctx).Model(&entity.AuditLog{})
+
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+
+	if req.Search != "" {
+		search := "%" + strings.ToLower(req.Search) + "%"
+		actors := tx.Model(&entity.User{}).Select("id").Where("LOWER(name) LIKE ? OR LOWER(email) LIKE ?", search, search)
+		query = query.Where("LOWER(path) LIKE ? OR LOWER(entity_id) LIKE ? OR actor_id IN (?)", search, search, actors)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return dto.AuditLogPaginationRepositoryResponse{}, err
+	}
+
+	if err := query.Preload("Actor").Order("created_at DESC").Scopes(Paginate(req.Page, req.PerPage)).Find(&auditLogs).Error; err != nil {
+		return dto.AuditLogPaginationRepositoryResponse{}, err
+	}
+
+	return dto.AuditLogPaginationRepositoryResponse{
+		AuditLogs: auditLogs,
+		PaginationResponse: dto.PaginationResponse{
+			Page:    req.Page,
+			PerPage: req.PerPage,
+			MaxPage: int64(math.Ceil(float64(count) / float64(req.PerPage))),
+			Count:   count,
+		},
+	}, nil
+}
+
+// GetSnapshot reads the raw columns of the model's row, soft deleted rows
+// included so a delete still has its after state.
+func (alr *AuditLogRepository) GetSnapshot(ctx context.Context, tx *gorm.DB, model any, entityID string) (map[string]any, bool, error) {
+	if tx == nil {
+		tx = alr.db
+	}
+
+	row := map[string]any{}
+	if err := tx.WithContext(ctx).Unscoped().Model(model).Where("id = ?", entityID).Take(&row).Error; err != nil {
+		return nil, false, err
+	}
+
+	return row, true, nil
+}
+func (alr *AuditLogRepository) GetRoleNameByID(ctx context.Context, tx *gorm.DB, roleID string) (string, error) {
+	if tx == nil {
+		tx = alr.db
+	}
+
+	var names []string
+	if err := tx.WithContext(ctx).Model(&entity.Role{}).Where("id = ?", roleID).Pluck("name", &names).Error; err != nil {
+		return "", err
+	}
+
+	if len(names) == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+
+	return names[0], nil
+}
+
+// Create
+func (alr *AuditLogRepository) CreateAuditLog(ctx context.Context, tx *gorm.DB, auditLog entity.AuditLog) error {
+	if tx == nil {
+		tx = alr.db
+	}
+
+	return tx.WithContext(ctx).Create(&auditLog).Error
+}
@@ -1,6 +1,15 @@
 package repository
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
 
 func Paginate(page, perPage int) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
@@ -8,3 +17,67 @@ func Paginate(page, perPage int) func(db *gorm.DB) *gorm.DB {
 		return db.Offset(offset).Limit(perPage)
 	}
 }
+
+// paginateCursor reads one keyset page of query, newest first by the
+// created_at and id of table. One row more than asked for tells whether
+// another page follows, so no COUNT(*) is needed.
+func paginateCursor[T any](query *gorm.DB, table string, req dto.CursorRequest, key func(T) (time.Time, uuid.UUID)) ([]T, dto.CursorResponse, error) {
+	if req.PerPage <= 0 {
+		req.PerPage = 10
+	}
+
+	cursor, err := dto.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, dto.CursorResponse{}, err
+	}
+
+	backward := cursor != nil && cursor.Backward
+	if cursor != nil {
+		operator := "<"
+		if backward {
+			operator = ">"
+		}
+		query = query.Where("("+table+".created_at, "+table+".id) "+operator+" (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	var rows []T
+	if err := query.Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: table + ".created_at", Raw: true}, Desc: !backward},
+		{Column: clause.Column{Name: table + ".id", Raw: true}, Desc: !backward},
+	}}).Limit(req.PerPage + 1).Find(&rows).Error; err != nil {
+		return nil, dto.CursorResponse{}, err
+	}
+
+	more := len(rows) > req.PerPage
+	if more {
+		rows = rows[:req.PerPage]
+	}
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	res := dto.CursorResponse{PerPage: req.PerPage}
+	if len(rows) == 0 {
+		return rows, res, nil
+	}
+
+	// a backward page was reached from an older one, a forward page from a
+	// newer one, so only the far side depends on more
+	if more || backward {
+		createdAt, id := key(rows[len(rows)-1])
+		res.NextCursor = dto.Cursor{CreatedAt: createdAt, ID: id}.Encode()
+	}
+	if cursor != nil && (more || !backward) {
+		createdAt, id := key(rows[0])
+		res.PrevCursor = dto.Cursor{CreatedAt: createdAt, ID: id, Backward: true}.Encode()
+	}
+
+	return rows, res, nil
+}
+
+// WithTransaction is the unit of work shared by the repositories: every
+// repository call made with the tx handed to fn commits together, and any
+// error or panic returned from fn rolls all of them back.
+func WithTransaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return db.WithContext(ctx).Transaction(fn)
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// returnRows hands rows to Find in place of a database and records the
+// statement built for it.
+func returnRows(t *testing.T, db *gorm.DB, rows []entity.Sender) *string {
+	t.Helper()
+
+	var sql string
+	if err := db.Callback().Query().After("gorm:query").Register("test:return_rows", func(tx *gorm.DB) {
+		sql = tx.Statement.SQL.String()
+		if dest, ok := tx.Statement.Dest.(*[]entity.Sender); ok {
+			*dest = append((*dest)[:0], rows...)
+		}
+	}); err != nil {
+		t.Fatalf("register rows: %v", err)
+	}
+
+	return &sql
+}
+
+func TestPaginateCursor(t *testing.T) {
+	base := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	senders := make([]entity.Sender, 5)
+	for i := range senders {
+		senders[i] = entity.Sender{ID: uuid.New(), TimeStamp: entity.TimeStamp{CreatedAt: base.Add(-time.Duration(i) * time.Minute)}}
+	}
+	forward := dto.Cursor{CreatedAt: senders[0].CreatedAt, ID: senders[0].ID}.Encode()
+	backward := dto.Cursor{CreatedAt: senders[3].CreatedAt, ID: senders[3].ID, Backward: true}.Encode()
+	newestFirst := "ORDER BY senders.created_at DESC,senders.id DESC"
+	oldestFirst := "ORDER BY senders.created_at,senders.id"
+
+	tests := []struct {
+		name      string
+		cursor    string
+		returned  []entity.Sender
+		wantRows  []entity.Sender
+		wantWhere string
+		wantOrder string
+		wantNext  bool
+		wantPrev  bool
+	}{
+		{"first page with more", "", senders[0:3], senders[0:2], "", newestFirst, true, false},
+		{"only page", "", senders[0:2], senders[0:2], "", newestFirst, false, false},
+		{"forward page with more", forward, senders[1:4], senders[1:3], "<", newestFirst, true, true},
+		{"last forward page", forward, senders[1:2], senders[1:2], "<", newestFirst, false, true},
+		{"empty forward page", forward, nil, nil, "<", newestFirst, false, false},
+		{"backward page with more", backward, []entity.Sender{senders[2], senders[1], senders[0]}, senders[1:3], ">", oldestFirst, true, true},
+		{"backward page reaching the newest", backward, []entity.Sender{senders[2], senders[1]}, senders[1:3], ">", oldestFirst, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDryRunDB(t).Session(&gorm.Session{Logger: logger.Discard})
+			sql := returnRows(t, db, tt.returned)
+
+			req := dto.CursorRequest{Cursor: tt.cursor, PerPage: 2}
+			rows, page, err := paginateCursor(db.Model(&entity.Sender{}), "senders", req, func(sender entity.Sender) (time.Time, uuid.UUID) {
+				return sender.CreatedAt, sender.ID
+			})
+			if err != nil {
+				t.Fatalf("paginateCursor() error = %v", err)
+			}
+
+			if tt.wantWhere == "" && strings.Contains(*sql, "(senders.created_at, senders.id)") {
+				t.Fatalf("first page is limited by a cursor: %s", *sql)
+			}
+			if tt.wantWhere != "" && !strings.Contains(*sql, "(senders.created_at, senders.id) "+tt.wantWhere+" ($") {
+				t.Fatalf("want rows %s the cursor: %s", tt.wantWhere, *sql)
+			}
+			if !strings.Contains(*sql, tt.wantOrder+" LIMIT") {
+				t.Fatalf("want %s: %s", tt.wantOrder, *sql)
+			}
+
+			if len(rows) != len(tt.wantRows) {
+				t.Fatalf("got %d rows, want %d", len(rows), len(tt.wantRows))
+			}
+			for i := range rows {
+				if rows[i].ID != tt.wantRows[i].ID {
+					t.Fatalf("row %d is not in newest first order", i)
+				}
+			}
+
+			if got := page.NextCursor != ""; got != tt.wantNext {
+				t.Fatalf("next cursor present = %v, want %v", got, tt.wantNext)
+			}
+			if got := page.PrevCursor != ""; got != tt.wantPrev {
+				t.Fatalf("prev cursor present = %v, want %v", got, tt.wantPrev)
+			}
+			if tt.wantNext {
+				next, _ := dto.DecodeCursor(page.NextCursor)
+				if last := rows[len(rows)-1]; next.Backward || next.ID != last.ID || !next.CreatedAt.Equal(last.CreatedAt) {
+					t.Fatalf("next cursor %+v does not continue after the last row", next)
+				}
+			}
+			if tt.wantPrev {
+				prev, _ := dto.DecodeCursor(page.PrevCursor)
+				if !prev.Backward || prev.ID != rows[0].ID || !prev.CreatedAt.Equal(rows[0].CreatedAt) {
+					t.Fatalf("prev cursor %+v does not go back from the first row", prev)
+				}
+			}
+		})
+	}
+}
+
+func TestPaginateCursorRejectsInvalidCursor(t *testing.T) {
+	db := newDryRunDB(t).Session(&gorm.Session{Logger: logger.Discard})
+
+	_, _, err := paginateCursor(db.Model(&entity.Sender{}), "senders", dto.CursorRequest{Cursor: "not a cursor"}, func(sender entity.Sender) (time.Time, uuid.UUID) {
+		return sender.CreatedAt, sender.ID
+	})
+	if !errors.Is(err, dto.ErrInvalidCursor) {
+		t.Fatalf("paginateCursor() error = %v, want %v", err, dto.ErrInvalidCursor)
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/Amierza/TitipanQ/backend/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	// companyMembers selects the users belonging to the scoped companies.
+	companyMembers = "SELECT user_id FROM user_companies WHERE company_id IN ? AND deleted_at IS NULL"
+	// companyPackages selects the packages of the members and the ones
+	// addressed to the scoped companies themselves.
+	companyPackages = "SELECT id FROM packages WHERE user_id IN (" + companyMembers + ") OR company_id IN ?"
+)
+
+// companyScopes holds the condition limiting each tenant owned table to the
+// companies of the request, tables missing here are shared by all companies.
+var companyScopes = map[string]string{
+	"companies":           "companies.id IN ?",
+	"user_companies":      "user_companies.company_id IN ?",
+	"users":               "users.id IN (" + companyMembers + ")",
+	"packages":            "(packages.user_id IN (" + companyMembers + ") OR packages.company_id IN ?)",
+	"package_histories":   "package_histories.package_id IN (" + companyPackages + ")",
+	"pickup_delegations":  "pickup_delegations.user_id IN (" + companyMembers + ")",
+	"notification_outbox": "notification_outbox.user_id IN (" + companyMembers + ")",
+}
+
+// companyScopedModels are the models behind companyScopes, a key that is not
+// the table of one of them would silently leave its table unscoped.
+var companyScopedModels = []any{
+	&entity.Company{},
+	&entity.UserCompany{},
+	&entity.User{},
+	&entity.Package{},
+	&entity.PackageHistory{},
+	&entity.PickupDelegation{},
+	&entity.NotificationOutbox{},
+}
+
+// RegisterCompanyScope makes every query, update and delete on a tenant owned
+// table honour the company scope of its context (see tenant.WithCompanies),
+// so company admins only ever reach their own companies' rows whatever
+// repository method serves them.
+func RegisterCompanyScope(db *gorm.DB) error {
+	if err := validateCompanyScopes(db.NamingStrategy); err != nil {
+		return err
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:company_scope", companyScope); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:company_scope", companyScope); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:company_scope", companyScope); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenant:company_scope", companyScope); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateCompanyScopes checks every companyScopes key is the table of a
+// companyScopedModels model and every model has a condition on its own table.
+func validateCompanyScopes(namer schema.Namer) error {
+	tables := make(map[string]bool, len(companyScopedModels))
+	for _, model := range companyScopedModels {
+		s, err := schema.Parse(model, &sync.Map{}, namer)
+		if err != nil {
+			return err
+		}
+
+		tables[s.Table] = true
+		condition, ok := companyScopes[s.Table]
+		if !ok {
+			return fmt.Errorf("company scope: table %q has no condition", s.Table)
+		}
+		if !strings.Contains(condition, s.Table+".") {
+			return fmt.Errorf("company scope: condition of %q does not filter its own columns", s.Table)
+		}
+	}
+
+	for table := range companyScopes {
+		if !tables[table] {
+			return fmt.Errorf("company scope: %q is not the table of a scoped model", table)
+		}
+	}
+
+	return nil
+}
+
+func companyScope(db *gorm.DB) {
+	companyIDs, ok := tenant.Companies(db.Statement.Context)
+	if !ok || db.Statement.SQL.Len() > 0 {
+		return
+	}
+
+	condition, ok := companyScopes[db.Statement.Table]
+	if !ok {
+		return
+	}
+
+	if len(companyIDs) == 0 {
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "1 = 0"}}})
+		return
+	}
+
+	vars := make([]any, strings.Count(condition, "?"))
+	for i := range vars {
+		vars[i] = companyIDs
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: condition, Vars: vars}}})
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/Amierza/TitipanQ/backend/internal/tenant"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// newDryRunDB builds statements without ever reaching a database.
+func newDryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry run db: %v", err)
+	}
+	if err := RegisterCompanyScope(db); err != nil {
+		t.Fatalf("register company scope: %v", err)
+	}
+
+	return db
+}
+
+// captureRowSQL records the last statement built by Scan or Row, dry run mode
+// builds it in the row callbacks and then refuses to go on.
+func captureRowSQL(t *testing.T, db *gorm.DB) *string {
+	t.Helper()
+
+	var sql string
+	if err := db.Callback().Row().After("gorm:row").Register("test:capture_sql", func(tx *gorm.DB) {
+		sql = tx.Statement.SQL.String()
+	}); err != nil {
+		t.Fatalf("register capture: %v", err)
+	}
+
+	return &sql
+}
+
+func TestCompanyScopesMatchTables(t *testing.T) {
+	if err := validateCompanyScopes(schema.NamingStrategy{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateCompanyScopesRejectsUnknownTable(t *testing.T) {
+	companyScopes["notification_outboxes"] = "notification_outboxes.user_id IN ?"
+	defer delete(companyScopes, "notification_outboxes")
+
+	if err := validateCompanyScopes(schema.NamingStrategy{}); err == nil {
+		t.Fatal("expected an error for a key that is not a table")
+	}
+}
+
+func TestCompanyScopeFiltersScopedTables(t *testing.T) {
+	db := newDryRunDB(t)
+	ctx := tenant.WithCompanies(context.Background(), []uuid.UUID{uuid.New()})
+
+	tests := []struct {
+		name  string
+		model any
+		want  string
+	}{
+		{"companies", &[]entity.Company{}, "companies.id IN"},
+		{"users", &[]entity.User{}, "users.id IN (SELECT user_id FROM user_companies"},
+		{"packages", &[]entity.Package{}, "packages.user_id IN"},
+		{"package histories", &[]entity.PackageHistory{}, "package_histories.package_id IN"},
+		{"pickup delegations", &[]entity.PickupDelegation{}, "pickup_delegations.user_id IN"},
+		{"notification outbox", &[]entity.NotificationOutbox{}, "notification_outbox.user_id IN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql := db.WithContext(ctx).Find(tt.model).Statement.SQL.String()
+			if !strings.Contains(sql, tt.want) {
+				t.Fatalf("query is not scoped, want %q in %s", tt.want, sql)
+			}
+		})
+	}
+}
+
+func TestCompanyScopeSkipsUnscopedContexts(t *testing.T) {
+	db := newDryRunDB(t)
+
+	sql := db.WithContext(context.Background()).Find(&[]entity.NotificationOutbox{}).Statement.SQL.String()
+	if strings.Contains(sql, "user_companies") {
+		t.Fatalf("unscoped query was filtered: %s", sql)
+	}
+}
+
+func TestCompanyScopeWithoutCompaniesMatchesNothing(t *testing.T) {
+	db := newDryRunDB(t)
+	ctx := tenant.WithCompanies(context.Background(), nil)
+
+	sql := db.WithContext(ctx).Find(&[]entity.Package{}).Statement.SQL.String()
+	if !strings.Contains(sql, "1 = 0") {
+		t.Fatalf("want an empty result for a scope without companies, got %s", sql)
+	}
+}
+
+func TestCountPackagesByCompanyKeepsToScopedCompanies(t *testing.T) {
+	db := newDryRunDB(t).Session(&gorm.Session{Logger: logger.Discard})
+	sql := captureRowSQL(t, db)
+	ar := NewAdminRepository(db)
+	ctx := tenant.WithCompanies(context.Background(), []uuid.UUID{uuid.New()})
+
+	if _, err := ar.CountPackagesByCompany(ctx, nil, dto.StatsRequest{}); err != nil && !errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+		t.Fatalf("count packages by company: %v", err)
+	}
+	if !strings.Contains(*sql, "companies.id IN ($") {
+		t.Fatalf("buckets are not limited to the scoped companies: %s", *sql)
+	}
+}
+
+func TestLockerSlotUsageIgnoresCompanyScope(t *testing.T) {
+	db := newDryRunDB(t).Session(&gorm.Session{Logger: logger.Discard})
+	sql := captureRowSQL(t, db)
+	ar := NewAdminRepository(db)
+	ctx := tenant.WithCompanies(context.Background(), []uuid.UUID{uuid.New()})
+
+	if _, err := ar.GetLockerSlotUsage(ctx, nil, uuid.NewString(), nil); err != nil && !errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+		t.Fatalf("get locker slot usage: %v", err)
+	}
+	if *sql == "" {
+		t.Fatal("no statement was built")
+	}
+	if strings.Contains(*sql, "user_companies") || strings.Contains(*sql, "company_id IN") {
+		t.Fatalf("slot usage is limited to the scoped companies: %s", *sql)
+	}
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type (
+	ILoginAttemptRepository interface {
+		// Get
+		GetUserByEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error)
+		LockUserByEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error)
+		CountFailedLoginAttemptsByIP(ctx context.Context, tx *gorm.DB, ip string, since time.Time) (int64, error)
+
+		// Create
+		CreateLoginAttempt(ctx context.Context, tx *gorm.DB, attempt entity.LoginAttempt) error
+
+		// Update
+		UpdateUserLoginFailures(ctx context.Context, tx *gorm.DB, userID uuid.UUID, failedAttempts int, lockedUntil *time.Time) error
+
+		// Delete
+		DeleteLoginAttemptsBefore(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error)
+
+		// Transaction
+		RunInTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error
+	}
+
+	LoginAttemptRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewLoginAttemptRepository(db *gorm.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		db: db,
+	}
+}
+
+// Get
+func (lr *LoginAttemptRepository) GetUserByEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error) {
+	if tx == nil {
+		tx = lr.db
+	}
+
+	var user entity.User
+	if err := tx.WithContext(ctx).Where("email = ?", email).Take(&user).Error; err != nil {
+		return entity.User{}, false, err
+	}
+
+	return user, true, nil
+}
+func (lr *LoginAttemptRepository) LockUserByEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error) {
+	if tx == nil {
+		tx = lr.db
+	}
+
+	var user entity.User
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("email = ?", email).Take(&user).Error; err != nil {
+		return entity.User{}, false, err
+	}
+
+	return user, true, nil
+}
+func (lr *LoginAttemptRepository) CountFailedLoginAttemptsByIP(ctx context.Context, tx *gorm.DB, ip string, since time.Time) (int64, error) {
+	if tx == nil {
+		tx = lr.db
+	}
+
+	var count int64
+	if err := tx.WithContext(ctx).Model(&entity.LoginAttempt{}).Where("ip_address = ? AND success = ? AND created_at >= ?", ip, false, since).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Create
+func (lr *LoginAttemptRepository) CreateLoginAttempt(ctx context.Context, tx *gorm.DB, attempt entity.LoginAttempt) error {
+	if tx == nil {
+		tx = lr.db
+	}
+
+	return tx.WithContext(ctx).Create(&attempt).Error
+}
+
+// Update
+func (lr *LoginAttemptRepository) UpdateUserLoginFailures(ctx context.Context, tx *gorm.DB, userID uuid.UUID, failedAttempts int, lockedUntil *time.Time) error {
+	if tx == nil {
+		tx = lr.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"failed_login_attempts": failedAttempts,
+		"locked_until":          lockedUntil,
+	}).Error
+}
+
+// Delete
+func (lr *LoginAttemptRepository) DeleteLoginAttemptsBefore(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error) {
+	if tx == nil {
+		tx = lr.db
+	}
+
+	result := tx.WithContext(ctx).Unscoped().Where("created_at < ?", before).Delete(&entity.LoginAttempt{})
+
+	return result.RowsAffected, result.Error
+}
+
+// Transaction
+func (lr *LoginAttemptRepository) RunInTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return WithTransaction(ctx, lr.db, fn)
+}
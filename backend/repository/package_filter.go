@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// applyPackageFilter adds the conditions of filter to a packages query, tx is
+// the connection used to build subqueries.
+func applyPackageFilter(tx *gorm.DB, query *gorm.DB, filter dto.PackageFilter) *gorm.DB {
+	if filter.UserID != nil {
+		query = query.Where("packages.user_id = ?", filter.UserID)
+	}
+
+	if filter.CompanyID != nil {
+		query = query.Where("packages.company_id = ? OR packages.user_id IN (?)", filter.CompanyID, tx.Model(&entity.UserCompany{}).Select("user_id").Where("company_id = ?", filter.CompanyID))
+	}
+
+	if filter.LockerID != nil {
+		query = query.Where("packages.locker_id = ?", filter.LockerID)
+	}
+
+	if filter.SenderID != nil {
+		query = query.Where("packages.sender_id = ?", filter.SenderID)
+	}
+
+	if len(filter.Statuses) > 0 {
+		query = query.Where("packages.status IN ?", filter.Statuses)
+	}
+
+	if filter.Type != "" {
+		query = query.Where("packages.type = ?", filter.Type)
+	}
+
+	ranges := []struct {
+		column   string
+		from, to *time.Time
+	}{
+		{"packages.created_at", filter.ReceivedFrom, filter.ReceivedTo},
+		{"packages.completed_at", filter.CompletedFrom, filter.CompletedTo},
+		{"packages.expired_at", filter.ExpiredFrom, filter.ExpiredTo},
+	}
+	for _, r := range ranges {
+		if r.from != nil {
+			query = query.Where(r.column+" >= ?", r.from)
+		}
+		if r.to != nil {
+			query = query.Where(r.column+" < ?", r.to)
+		}
+	}
+
+	if filter.MinQuantity != nil {
+		query = query.Where("packages.quantity >= ?", *filter.MinQuantity)
+	}
+
+	if filter.MaxQuantity != nil {
+		query = query.Where("packages.quantity <= ?", *filter.MaxQuantity)
+	}
+
+	return query
+}
+
+// orderPackages sorts by the filter's fields, newest first by default. The
+// id breaks ties so pages never overlap.
+func orderPackages(query *gorm.DB, filter dto.PackageFilter) *gorm.DB {
+	sort := filter.Sort
+	if len(sort) == 0 {
+		sort = []dto.SortField{{Column: "packages.created_at", Desc: true}}
+	}
+
+	columns := make([]clause.OrderByColumn, 0, len(sort)+1)
+	for _, field := range sort {
+		columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: field.Column, Raw: true}, Desc: field.Desc})
+	}
+	columns = append(columns, clause.OrderByColumn{Column: clause.Column{Name: "packages.id", Raw: true}, Desc: sort[len(sort)-1].Desc})
+
+	return query.Order(clause.OrderBy{Columns: columns})
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type (
+	IPermissionRepository interface {
+		// Get
+		GetPermissionsByRoleID(ctx context.Context, tx *gorm.DB, roleID string) ([]entity.Permission, error)
+		GetRoleByID(ctx context.Context, tx *gorm.DB, roleID string) (entity.Role, bool, error)
+		GetCompanyIDsByUserID(ctx context.Context, tx *gorm.DB, userID string) ([]uuid.UUID, error)
+	}
+
+	PermissionRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewPermissionRepository(db *gorm.DB) *PermissionRepository {
+	return &PermissionRepository{
+		db: db,
+	}
+}
+
+// Get
+func (pr *PermissionRepository) GetPermissionsByRoleID(ctx context.Context, tx *gorm.DB, roleID string) ([]entity.Permission, error) {
+	if tx == nil {
+		tx = pr.db
+	}
+
+	var permissions []entity.Permission
+	if err := tx.WithContext(ctx).Where("role_id = ?", roleID).Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+func (pr *PermissionRepository) GetRoleByID(ctx context.Context, tx *gorm.DB, roleID string) (entity.Role, bool, error) {
+	if tx == nil {
+		tx = pr.db
+	}
+
+	var role entity.Role
+	if err := tx.WithContext(ctx).Where("id = ?", roleID).Take(&role).Error; err != nil {
+		return entity.Role{}, false, err
+	}
+
+	return role, true, nil
+}
+func (pr *PermissionRepository) GetCompanyIDsByUserID(ctx context.Context, tx *gorm.DB, userID string) ([]uuid.UUID, error) {
+	if tx == nil {
+		tx = pr.db
+	}
+
+	var companyIDs []uuid.UUID
+	if err := tx.WithContext(ctx).Model(&entity.UserCompany{}).Where("user_id = ?", userID).Pluck("company_id", &companyIDs).Error; err != nil {
+		return nil, err
+	}
+
+	return companyIDs, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"gorm.io/gorm"
+)
+
+// searchQueryJoin makes the parsed tsquery available as search_query, the
+// search_vector columns are created in migrations.migrateSearch.
+const searchQueryJoin = "CROSS JOIN to_tsquery('simple', ?) AS search_query"
+
+type (
+	ISearchRepository interface {
+		// Get
+		SearchPackages(ctx context.Context, tx *gorm.DB, query dto.SearchQuery) ([]dto.SearchResult, error)
+		SearchUsers(ctx context.Context, tx *gorm.DB, query dto.SearchQuery) ([]dto.SearchResult, error)
+		SearchSenders(ctx context.Context, tx *gorm.DB, query dto.SearchQuery) ([]dto.SearchResult, error)
+		SearchCompanies(ctx context.Context, tx *gorm.DB, query dto.SearchQuery) ([]dto.SearchResult, error)
+	}
+
+	SearchRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewSearchRepository(db *gorm.DB) *SearchRepository {
+	return &SearchRepository{
+		db: db,
+	}
+}
+
+// phoneMatch returns the rank bonus and the extra condition for a partial
+// phone number match on column. Both are left out when the term is not a
+// phone number, an empty pattern would match every row without a number.
+func phoneMatch(column, pattern string) (rank string, where string, args []interface{}) {
+	if pattern == "" {
+		return "", "", nil
+	}
+
+	return " + CASE WHEN " + column + " ILIKE ? THEN 1 ELSE 0 END", " OR " + column + " ILIKE ?", []interface{}{pattern}
+}
+
+// Get
+
+// SearchPackages matches packages on their own text, their recipient and
+// their sender, partial tracking codes are matched through the trigram index.
+func (sr *SearchRepository) SearchPackages(ctx context.Context, tx *gorm.DB, query dto.SearchQuery) ([]dto.SearchResult, error) {
+	if tx == nil {
+		tx = sr.db
+	}
+
+	var results []dto.SearchResult
+	if err := tx.WithContext(ctx).
+		Model(&entity.Package{}).
+		Select(`'package' AS type, packages.id, packages.tracking_code AS title,
+			concat_ws(' - ', coalesce(users.name, companies.name), senders.name) AS subtitle,
+			ts_rank(packages.search_vector, search_query) +
+			0.5 * coalesce(ts_rank(users.search_vector, search_query), 0) +
+			0.5 * coalesce(ts_rank(senders.search_vector, search_query), 0) +
+			similarity(packages.tracking_code, ?) AS rank`, query.Term).
+		Joins(searchQueryJoin, query.TSQuery).
+		Joins("LEFT JOIN users ON users.id = packages.user_id AND users.deleted_at IS NULL").
+		Joins("LEFT JOIN companies ON companies.id = packages.company_id AND companies.deleted_at IS NULL").
+		Joins("LEFT JOIN senders ON senders.id = packages.sender_id AND senders.deleted_at IS NULL").
+		Where("packages.search_vector @@ search_query OR users.search_vector @@ search_query OR senders.search_vector @@ search_query OR packages.tracking_code ILIKE ?", query.Pattern).
+		Order("rank DESC").
+		Limit(query.Limit).
+		Scan(&results).Error; err != nil {
+		return []dto.SearchResult{}, err
+	}
+
+	return results, nil
+}
+func (sr *SearchRepository) SearchUsers(ctx context.Context, tx *gorm.DB, query dto.SearchQuery) ([]dto.SearchResult, error) {
+	if tx == nil {
+		tx = sr.db
+	}
+
+	phoneRank, phoneWhere, phoneArgs := phoneMatch("users.phone_number", query.PhonePattern)
+
+	var results []dto.SearchResult
+	if err := tx.WithContext(ctx).
+		Model(&entity.User{}).
+		Select(`'user' AS type, users.id, users.name AS title, users.email AS subtitle,
+			ts_rank(users.search_vector, search_query)`+phoneRank+` AS rank`, phoneArgs...).
+		Joins(searchQueryJoin, query.TSQuery).
+		Joins("JOIN roles ON roles.id = users.role_id").
+		Where("roles.name NOT IN ?", []string{entity.RoleAdmin, entity.RoleCompanyAdmin}).
+		Where("users.search_vector @@ search_query"+phoneWhere, phoneArgs...).
+		Order("rank DESC").
+		Limit(query.Limit).
+		Scan(&results).Error; err != nil {
+		return []dto.SearchResult{}, err
+	}
+
+	return results, nil
+}
+func (sr *SearchRepository) SearchSenders(ctx context.Context, tx *gorm.DB, query dto.SearchQuery) ([]dto.SearchResult, error) {
+	if tx == nil {
+		tx = sr.db
+	}
+
+	phoneRank, phoneWhere, phoneArgs := phoneMatch("senders.phone_number", query.PhonePattern)
+
+	var results []dto.SearchResult
+	if err := tx.WithContext(ctx).
+		Model(&entity.Sender{}).
+		Select(`'sender' AS type, senders.id, senders.name AS title, senders.phone_number AS subtitle,
+			ts_rank(senders.search_vector, search_query)`+phoneRank+` AS rank`, phoneArgs...).
+		Joins(searchQueryJoin, query.TSQuery).
+		Where("senders.search_vector @@ search_query"+phoneWhere, phoneArgs...).
+		Order("rank DESC").
+		Limit(query.Limit).
+		Scan(&results).Error; err != nil {
+		return []dto.SearchResult{}, err
+	}
+
+	return results, nil
+}
+func (sr *SearchRepository) SearchCompanies(ctx context.Context, tx *gorm.DB, query dto.SearchQuery) ([]dto.SearchResult, error) {
+	if tx == nil {
+		tx = sr.db
+	}
+
+	var results []dto.SearchResult
+	if err := tx.WithContext(ctx).
+		Model(&entity.Company{}).
+		Select(`'company' AS type, companies.id, companies.name AS title, companies.address AS subtitle,
+			ts_rank(companies.search_vector, search_query) AS rank`).
+		Joins(searchQueryJoin, query.TSQuery).
+		Where("companies.search_vector @@ search_query").
+		Order("rank DESC").
+		Limit(query.Limit).
+		Scan(&results).Error; err != nil {
+		return []dto.SearchResult{}, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSearchPhoneMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		search    func(*SearchRepository, dto.SearchQuery) ([]dto.SearchResult, error)
+		pattern   string
+		wantPhone bool
+	}{
+		{"users without phone term", searchUsers, "", false},
+		{"users with phone term", searchUsers, "%6281%", true},
+		{"senders without phone term", searchSenders, "", false},
+		{"senders with phone term", searchSenders, "%6281%", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDryRunDB(t).Session(&gorm.Session{Logger: logger.Discard})
+			sql := captureRowSQL(t, db)
+			sr := NewSearchRepository(db)
+
+			query := dto.SearchQuery{Term: "budi", TSQuery: "budi:*", PhonePattern: tt.pattern, Limit: 5}
+			if _, err := tt.search(sr, query); err != nil && !errors.Is(err, gorm.ErrDryRunModeUnsupported) {
+				t.Fatalf("search: %v", err)
+			}
+			if *sql == "" {
+				t.Fatal("no statement was built")
+			}
+			if got := strings.Contains(*sql, "phone_number ILIKE"); got != tt.wantPhone {
+				t.Fatalf("phone match = %v, want %v: %s", got, tt.wantPhone, *sql)
+			}
+		})
+	}
+}
+
+func searchUsers(sr *SearchRepository, query dto.SearchQuery) ([]dto.SearchResult, error) {
+	return sr.SearchUsers(context.Background(), nil, query)
+}
+
+func searchSenders(sr *SearchRepository, query dto.SearchQuery) ([]dto.SearchResult, error) {
+	return sr.SearchSenders(context.Background(), nil, query)
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type (
+	ISessionRepository interface {
+		// Get
+		GetSessionByID(ctx context.Context, tx *gorm.DB, sessionID string) (entity.UserSession, bool, error)
+		LockSessionByID(ctx context.Context, tx *gorm.DB, sessionID string) (entity.UserSession, bool, error)
+
+		// Create
+		CreateSession(ctx context.Context, tx *gorm.DB, session entity.UserSession) error
+
+		// Update
+		RotateSession(ctx context.Context, tx *gorm.DB, sessionID uuid.UUID, refreshID uuid.UUID, expiresAt, lastUsedAt time.Time) error
+		RevokeSession(ctx context.Context, tx *gorm.DB, sessionID uuid.UUID, revokedAt time.Time, reason string) error
+		RevokeAllUserSessions(ctx context.Context, tx *gorm.DB, userID uuid.UUID, revokedAt time.Time, reason string) error
+
+		// Delete
+		DeleteExpiredSessions(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error)
+
+		// Transaction
+		RunInTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error
+	}
+
+	SessionRepository struct {
+		db *gorm.DB
+	}
+)
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+// Get
+func (sr *SessionRepository) GetSessionByID(ctx context.Context, tx *gorm.DB, sessionID string) (entity.UserSession, bool, error) {
+	if tx == nil {
+		tx = sr.db
+	}
+
+	var session entity.UserSession
+	if err := tx.WithContext(ctx).Where("id = ?", sessionID).Take(&session).Error; err != nil {
+		return entity.UserSession{}, false, err
+	}
+
+	return session, true, nil
+}
+func (sr *SessionRepository) LockSessionByID(ctx context.Context, tx *gorm.DB, sessionID string) (entity.UserSession, bool, error) {
+	if tx == nil {
+		tx = sr.db
+	}
+
+	var session entity.UserSession
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", sessionID).Take(&session).Error; err != nil {
+		return entity.UserSession{}, false, err
+	}
+
+	return session, true, nil
+}
+
+// Create
+func (sr *SessionRepository) CreateSession(ctx context.Context, tx *gorm.DB, session entity.UserSession) error {
+	if tx == nil {
+		tx = sr.db
+	}
+
+	return tx.WithContext(ctx).Create(&session).Error
+}
+
+// Update
+func (sr *SessionRepository) RotateSession(ctx context.Context, tx *gorm.DB, sessionID uuid.UUID, refreshID uuid.UUID, expiresAt, lastUsedAt time.Time) error {
+	if tx == nil {
+		tx = sr.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.UserSession{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
+		"refresh_id":   refreshID,
+		"expires_at":   expiresAt,
+		"last_used_at": lastUsedAt,
+	}).Error
+}
+func (sr *SessionRepository) RevokeSession(ctx context.Context, tx *gorm.DB, sessionID uuid.UUID, revokedAt time.Time, reason string) error {
+	if tx == nil {
+		tx = sr.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.UserSession{}).Where("id = ? AND revoked_at IS NULL", sessionID).Updates(map[string]interface{}{
+		"revoked_at":     revokedAt,
+		"revoked_reason": reason,
+	}).Error
+}
+func (sr *SessionRepository) RevokeAllUserSessions(ctx context.Context, tx *gorm.DB, userID uuid.UUID, revokedAt time.Time, reason string) error {
+	if tx == nil {
+		tx = sr.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.UserSession{}).Where("user_id = ? AND revoked_at IS NULL", userID).Updates(map[string]interface{}{
+		"revoked_at":     revokedAt,
+		"revoked_reason": reason,
+	}).Error
+}
+
+// Delete
+func (sr *SessionRepository) DeleteExpiredSessions(ctx context.Context, tx *gorm.DB, before time.Time) (int64, error) {
+	if tx == nil {
+		tx = sr.db
+	}
+
+	result := tx.WithContext(ctx).Unscoped().Where("expires_at < ? OR revoked_at < ?", before, before).Delete(&entity.UserSession{})
+
+	return result.RowsAffected, result.Error
+}
+
+// Transaction
+func (sr *SessionRepository) RunInTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return WithTransaction(ctx, sr.db, fn)
+}
@@ -2,9 +2,13 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/Amierza/TitipanQ/backend/dto"
 	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type (
@@ -12,25 +16,44 @@ type (
 		// Get
 		GetRoleByName(ctx context.Context, tx *gorm.DB, roleName string) (entity.Role, bool, error)
 		GetRoleByID(ctx context.Context, tx *gorm.DB, roleID string) (entity.Role, bool, error)
-		GetPermissionsByRoleID(ctx context.Context, tx *gorm.DB, roleID string) ([]string, bool, error)
 		GetUserByEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error)
 		GetUserByID(ctx context.Context, tx *gorm.DB, userID string) (entity.User, bool, error)
 		GetCompanyByID(ctx context.Context, tx *gorm.DB, companyID string) (entity.Company, bool, error)
 		GetAllCompany(ctx context.Context, tx *gorm.DB) ([]entity.Company, error)
-		GetAllPackage(ctx context.Context, tx *gorm.DB, userID string) ([]entity.Package, error)
+		GetAllPackage(ctx context.Context, tx *gorm.DB, userID string, filter dto.PackageFilter) ([]entity.Package, error)
 		GetPackageByID(ctx context.Context, tx *gorm.DB, pkgID string) (entity.Package, bool, error)
+		IsCompanyMember(ctx context.Context, tx *gorm.DB, companyID, userID uuid.UUID) (bool, error)
 		GetAllPackageHistory(ctx context.Context, tx *gorm.DB, pkgID string) ([]entity.PackageHistory, error)
+		GetAllPickupDelegationByUserID(ctx context.Context, tx *gorm.DB, userID string) ([]entity.PickupDelegation, error)
+		GetPickupDelegationByID(ctx context.Context, tx *gorm.DB, delegationID string) (entity.PickupDelegation, bool, error)
+		LockUserTokenByHash(ctx context.Context, tx *gorm.DB, purpose entity.TokenPurpose, tokenHash string) (entity.UserToken, bool, error)
+		GetUserByPhoneNumber(ctx context.Context, tx *gorm.DB, phoneNumber string) (entity.User, bool, error)
+		LockLatestLoginOTP(ctx context.Context, tx *gorm.DB, phoneNumber string) (entity.LoginOTP, bool, error)
+		CountLoginOTPsSince(ctx context.Context, tx *gorm.DB, phoneNumber, ipAddress string, since time.Time) (int64, int64, error)
 
 		// Create
 		Register(ctx context.Context, tx *gorm.DB, user entity.User) error
 		CreateUserCompany(ctx context.Context, tx *gorm.DB, userCompany entity.UserCompany) error
+		CreatePickupDelegation(ctx context.Context, tx *gorm.DB, delegation entity.PickupDelegation) error
+		CreateUserToken(ctx context.Context, tx *gorm.DB, token entity.UserToken) error
+		CreateLoginOTP(ctx context.Context, tx *gorm.DB, otp entity.LoginOTP) error
 
 		// Update
 		UpdateUser(ctx context.Context, tx *gorm.DB, user entity.User) error
 		PreloadUserCompanies(ctx context.Context, tx *gorm.DB, user *entity.User) error
+		RevokePickupDelegation(ctx context.Context, tx *gorm.DB, delegationID uuid.UUID, revokedAt time.Time) error
+		UpdateUserPassword(ctx context.Context, tx *gorm.DB, userID uuid.UUID, hashedPassword string) error
+		UpdateUserEmailVerifiedAt(ctx context.Context, tx *gorm.DB, userID uuid.UUID, verifiedAt time.Time) error
+		UseUserToken(ctx context.Context, tx *gorm.DB, tokenID uuid.UUID, usedAt time.Time) error
+		InvalidateUserTokens(ctx context.Context, tx *gorm.DB, userID uuid.UUID, purpose entity.TokenPurpose, usedAt time.Time) error
+		UpdateLoginOTPAttempts(ctx context.Context, tx *gorm.DB, otpID uuid.UUID, attempts int, consumedAt *time.Time) error
+		ConsumeLoginOTPs(ctx context.Context, tx *gorm.DB, phoneNumber string, consumedAt time.Time) error
 
 		// delete 
 		DeleteUserCompaniesByUserID(ctx context.Context, tx *gorm.DB, userID string) error
+
+		// Transaction
+		RunInTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error
 	}
 
 	UserRepository struct {
@@ -69,25 +92,13 @@ func (ur *UserRepository) GetRoleByID(ctx context.Context, tx *gorm.DB, roleID s
 
 	return role, true, nil
 }
-func (ur *UserRepository) GetPermissionsByRoleID(ctx context.Context, tx *gorm.DB, roleID string) ([]string, bool, error) {
-	if tx == nil {
-		tx = ur.db
-	}
-
-	var endpoints []string
-	if err := tx.WithContext(ctx).Table("permissions").Where("role_id = ?", roleID).Pluck("endpoint", &endpoints).Error; err != nil {
-		return []string{}, false, err
-	}
-
-	return endpoints, true, nil
-}
 func (ur *UserRepository) GetUserByEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error) {
 	if tx == nil {
 		tx = ur.db
 	}
 
 	var user entity.User
-	if err := tx.WithContext(ctx).Preload("Company").Preload("Role").Where("email = ?", email).Take(&user).Error; err != nil {
+	if err := tx.WithContext(ctx).Preload("Company").Preload("Role.Permissions").Where("email = ?", email).Take(&user).Error; err != nil {
 		return entity.User{}, false, err
 	}
 
@@ -99,7 +110,7 @@ func (ur *UserRepository) GetUserByID(ctx context.Context, tx *gorm.DB, userID s
 	}
 
 	var user entity.User
-	if err := tx.WithContext(ctx).Preload("Company").Preload("Role").Where("id = ?", userID).Take(&user).Error; err != nil {
+	if err := tx.WithContext(ctx).Preload("Company").Preload("Role.Permissions").Where("id = ?", userID).Take(&user).Error; err != nil {
 		return entity.User{}, false, err
 	}
 
@@ -130,7 +141,7 @@ func (ur *UserRepository) GetAllCompany(ctx context.Context, tx *gorm.DB) ([]ent
 
 	return companies, nil
 }
-func (ur *UserRepository) GetAllPackage(ctx context.Context, tx *gorm.DB, userID string) ([]entity.Package, error) {
+func (ur *UserRepository) GetAllPackage(ctx context.Context, tx *gorm.DB, userID string, filter dto.PackageFilter) ([]entity.Package, error) {
 	if tx == nil {
 		tx = ur.db
 	}
@@ -140,9 +151,14 @@ func (ur *UserRepository) GetAllPackage(ctx context.Context, tx *gorm.DB, userID
 		err      error
 	)
 
-	query := tx.WithContext(ctx).Model(&entity.Package{}).Where("user_id = ? ", userID).Preload("User.Company").Preload("User.Role")
+	// packages addressed to one of the user's companies are shared by all members
+	companies := tx.Model(&entity.UserCompany{}).Select("company_id").Where("user_id = ?", userID)
+	query := tx.WithContext(ctx).Model(&entity.Package{}).
+		Where("user_id = ? OR company_id IN (?)", userID, companies).
+		Preload("User.UserCompanies.Company").Preload("User.Role").Preload("Company")
+	query = applyPackageFilter(tx, query, filter)
 
-	if err := query.Order("created_at DESC").Find(&packages).Error; err != nil {
+	if err := orderPackages(query, filter).Find(&packages).Error; err != nil {
 		return []entity.Package{}, err
 	}
 
@@ -154,12 +170,24 @@ func (ur *UserRepository) GetPackageByID(ctx context.Context, tx *gorm.DB, pkgID
 	}
 
 	var user entity.Package
-	if err := tx.WithContext(ctx).Preload("User.Company").Preload("User.Role").Where("id = ?", pkgID).Take(&user).Error; err != nil {
+	if err := tx.WithContext(ctx).Preload("User.UserCompanies.Company").Preload("User.Role").Preload("Company").Where("id = ?", pkgID).Take(&user).Error; err != nil {
 		return entity.Package{}, false, err
 	}
 
 	return user, true, nil
 }
+func (ur *UserRepository) IsCompanyMember(ctx context.Context, tx *gorm.DB, companyID, userID uuid.UUID) (bool, error) {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	var count int64
+	if err := tx.WithContext(ctx).Model(&entity.UserCompany{}).Where("company_id = ? AND user_id = ?", companyID, userID).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
 func (ur *UserRepository) GetAllPackageHistory(ctx context.Context, tx *gorm.DB, pkgID string) ([]entity.PackageHistory, error) {
 	if tx == nil {
 		tx = ur.db
@@ -229,5 +257,184 @@ func (ur *UserRepository) DeleteUserCompaniesByUserID(ctx context.Context, tx *g
 		Error
 }
 
+// Transaction
+func (ur *UserRepository) RunInTransaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return WithTransaction(ctx, ur.db, fn)
+}
+
+// Pickup Delegation
+func (ur *UserRepository) CreatePickupDelegation(ctx context.Context, tx *gorm.DB, delegation entity.PickupDelegation) error {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	// only link the packages, they already exist
+	return tx.WithContext(ctx).Omit("Packages.*").Create(&delegation).Error
+}
+func (ur *UserRepository) GetAllPickupDelegationByUserID(ctx context.Context, tx *gorm.DB, userID string) ([]entity.PickupDelegation, error) {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	var delegations []entity.PickupDelegation
+	if err := tx.WithContext(ctx).Preload("Packages").Where("user_id = ?", userID).Order("created_at DESC").Find(&delegations).Error; err != nil {
+		return []entity.PickupDelegation{}, err
+	}
+
+	return delegations, nil
+}
+func (ur *UserRepository) GetPickupDelegationByID(ctx context.Context, tx *gorm.DB, delegationID string) (entity.PickupDelegation, bool, error) {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	var delegation entity.PickupDelegation
+	if err := tx.WithContext(ctx).Preload("Packages").Where("id = ?", delegationID).Take(&delegation).Error; err != nil {
+		return entity.PickupDelegation{}, false, err
+	}
+
+	return delegation, true, nil
+}
+func (ur *UserRepository) RevokePickupDelegation(ctx context.Context, tx *gorm.DB, delegationID uuid.UUID, revokedAt time.Time) error {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.PickupDelegation{}).Where("id = ?", delegationID).Update("revoked_at", revokedAt).Error
+}
+
+// User Token
+func (ur *UserRepository) LockUserTokenByHash(ctx context.Context, tx *gorm.DB, purpose entity.TokenPurpose, tokenHash string) (entity.UserToken, bool, error) {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	var token entity.UserToken
+	if err := tx.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("purpose = ? AND token_hash = ?", purpose, tokenHash).
+		Take(&token).Error; err != nil {
+		return entity.UserToken{}, false, err
+	}
+
+	return token, true, nil
+}
+func (ur *UserRepository) CreateUserToken(ctx context.Context, tx *gorm.DB, token entity.UserToken) error {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	return tx.WithContext(ctx).Create(&token).Error
+}
+func (ur *UserRepository) UpdateUserPassword(ctx context.Context, tx *gorm.DB, userID uuid.UUID, hashedPassword string) error {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.User{}).Where("id = ?", userID).Update("password", hashedPassword).Error
+}
+func (ur *UserRepository) UpdateUserEmailVerifiedAt(ctx context.Context, tx *gorm.DB, userID uuid.UUID, verifiedAt time.Time) error {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.User{}).Where("id = ? AND email_verified_at IS NULL", userID).Update("email_verified_at", verifiedAt).Error
+}
+func (ur *UserRepository) UseUserToken(ctx context.Context, tx *gorm.DB, tokenID uuid.UUID, usedAt time.Time) error {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.UserToken{}).Where("id = ?", tokenID).Update("used_at", usedAt).Error
+}
+
+// InvalidateUserTokens marks the user's unused tokens of purpose as used, so
+// only the most recently mailed token works.
+func (ur *UserRepository) InvalidateUserTokens(ctx context.Context, tx *gorm.DB, userID uuid.UUID, purpose entity.TokenPurpose, usedAt time.Time) error {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	return tx.WithContext(ctx).
+		Model(&entity.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", usedAt).Error
+}
+
+// Login OTP
+func (ur *UserRepository) GetUserByPhoneNumber(ctx context.Context, tx *gorm.DB, phoneNumber string) (entity.User, bool, error) {
+	if tx == nil {
+		tx = ur.db
+	}
 
+	var user entity.User
+	if err := tx.WithContext(ctx).Preload("Role.Permissions").Where("phone_number = ?", phoneNumber).Take(&user).Error; err != nil {
+		return entity.User{}, false, err
+	}
+
+	return user, true, nil
+}
+func (ur *UserRepository) LockLatestLoginOTP(ctx context.Context, tx *gorm.DB, phoneNumber string) (entity.LoginOTP, bool, error) {
+	if tx == nil {
+		tx = ur.db
+	}
 
+	var otp entity.LoginOTP
+	if err := tx.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("phone_number = ?", phoneNumber).
+		Order("created_at DESC").
+		Take(&otp).Error; err != nil {
+		return entity.LoginOTP{}, false, err
+	}
+
+	return otp, true, nil
+}
+
+// CountLoginOTPsSince counts the codes requested since since for the phone
+// number and from the ip address, both feed the rate limit.
+func (ur *UserRepository) CountLoginOTPsSince(ctx context.Context, tx *gorm.DB, phoneNumber, ipAddress string, since time.Time) (int64, int64, error) {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	var byPhone, byIP int64
+	if err := tx.WithContext(ctx).Model(&entity.LoginOTP{}).Where("phone_number = ? AND created_at >= ?", phoneNumber, since).Count(&byPhone).Error; err != nil {
+		return 0, 0, err
+	}
+
+	if ipAddress != "" {
+		if err := tx.WithContext(ctx).Model(&entity.LoginOTP{}).Where("ip_address = ? AND created_at >= ?", ipAddress, since).Count(&byIP).Error; err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return byPhone, byIP, nil
+}
+func (ur *UserRepository) CreateLoginOTP(ctx context.Context, tx *gorm.DB, otp entity.LoginOTP) error {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	return tx.WithContext(ctx).Create(&otp).Error
+}
+func (ur *UserRepository) UpdateLoginOTPAttempts(ctx context.Context, tx *gorm.DB, otpID uuid.UUID, attempts int, consumedAt *time.Time) error {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	return tx.WithContext(ctx).Model(&entity.LoginOTP{}).Where("id = ?", otpID).Updates(map[string]interface{}{
+		"attempts":    attempts,
+		"consumed_at": consumedAt,
+	}).Error
+}
+func (ur *UserRepository) ConsumeLoginOTPs(ctx context.Context, tx *gorm.DB, phoneNumber string, consumedAt time.Time) error {
+	if tx == nil {
+		tx = ur.db
+	}
+
+	return tx.WithContext(ctx).
+		Model(&entity.LoginOTP{}).
+		Where("phone_number = ? AND consumed_at IS NULL", phoneNumber).
+		Update("consumed_at", consumedAt).Error
+}
@@ -7,30 +7,52 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func Admin(route *gin.Engine, adminHandler handler.IAdminHandler, jwtService service.IJWTService) {
+func Admin(route *gin.Engine, adminHandler handler.IAdminHandler, jwtService service.IJWTService, sessionService service.ISessionService, permissionService service.IPermissionService, auditService service.IAuditService) {
 	routes := route.Group("/api/v1/admin")
 	{
 		// Authentication
 		routes.POST("/login", adminHandler.Login)
 		routes.POST("/refresh-token", adminHandler.RefreshToken)
 
-		routes.Use(middleware.Authentication(jwtService), middleware.RouteAccessControl(jwtService))
+		routes.Use(middleware.Authentication(jwtService, sessionService), middleware.RouteAccessControl(permissionService), middleware.CompanyScope(permissionService), middleware.AuditTrail(auditService))
 		{
+			// Session
+			routes.POST("/logout", adminHandler.Logout)
+			routes.POST("/logout-all", adminHandler.LogoutAll)
+
 			// User
 			routes.POST("/create-user", adminHandler.CreateUser)
 			routes.GET("/get-all-user", adminHandler.ReadAllUser)
 			routes.GET("/get-detail-user/:id", adminHandler.GetDetailUser)
 			routes.PATCH("/update-user/:id", adminHandler.UpdateUser)
 			routes.DELETE("/delete-user/:id", adminHandler.DeleteUser)
+			routes.PATCH("/unlock-user/:id", adminHandler.UnlockUser)
+			routes.PATCH("/assign-role/:id", adminHandler.AssignRole)
+
+			// Role & Permission
+			routes.POST("/create-role", adminHandler.CreateRole)
+			routes.GET("/get-all-role", adminHandler.ReadAllRole)
+			routes.GET("/get-detail-role/:id", adminHandler.GetDetailRole)
+			routes.PATCH("/update-role/:id", adminHandler.UpdateRole)
+			routes.DELETE("/delete-role/:id", adminHandler.DeleteRole)
+			routes.GET("/get-all-route", adminHandler.ReadAllRoute)
+			routes.POST("/attach-permission/:id", adminHandler.AttachPermission)
+			routes.DELETE("/detach-permission/:id", adminHandler.DetachPermission)
 
 			// Package & Package History
 			routes.POST("/create-package", adminHandler.CreatePackage)
+			routes.POST("/import-package", adminHandler.ImportPackages)
+			routes.GET("/export-package", adminHandler.ExportPackages)
+			routes.GET("/export-package-history", adminHandler.ExportPackageHistories)
+			routes.GET("/print-package-label", adminHandler.PrintPackageLabels)
 			routes.GET("/get-all-package", adminHandler.ReadAllPackage)
 			routes.GET("/get-detail-package/:id", adminHandler.GetDetailPackage)
 			routes.GET("/get-all-package-history/:id", adminHandler.GetAllPackageHistory)
 			routes.PATCH("/update-package/:id", adminHandler.UpdatePackage)
 			routes.PATCH("/update-status-packages", adminHandler.UpdateStatusPackages)
 			routes.DELETE("/delete-package/:id", adminHandler.DeletePackage)
+			routes.PATCH("/regenerate-pickup-code/:id", adminHandler.RegeneratePickupCode)
+			routes.GET("/get-all-pickup-delegation/:id", adminHandler.ReadAllActivePickupDelegation)
 
 			// Cron
 			routes.POST("/trigger-expire-packages", adminHandler.TriggerExpire)
@@ -40,6 +62,7 @@ func Admin(route *gin.Engine, adminHandler handler.IAdminHandler, jwtService ser
 			routes.GET("/get-all-company", adminHandler.ReadAllCompany)
 			routes.GET("/get-detail-company/:id", adminHandler.GetDetailCompany)
 			routes.PATCH("/update-company/:id", adminHandler.UpdateCompany)
+			routes.PATCH("/update-company-contacts/:id", adminHandler.UpdateCompanyContacts)
 			routes.DELETE("/delete-company/:id", adminHandler.DeleteCompany)
 
 			// locker
@@ -48,6 +71,13 @@ func Admin(route *gin.Engine, adminHandler handler.IAdminHandler, jwtService ser
 			routes.GET("/get-detail-locker/:id", adminHandler.GetDetailLocker)
 			routes.PATCH("/update-locker/:id", adminHandler.UpdateLocker)
 			routes.DELETE("/delete-locker/:id", adminHandler.DeleteLocker)
+			routes.GET("/get-locker-occupancy", adminHandler.GetLockerOccupancy)
+
+			// locker slot
+			routes.POST("/create-locker-slot", adminHandler.CreateLockerSlot)
+			routes.GET("/get-all-locker-slot/:id", adminHandler.ReadAllLockerSlot)
+			routes.PATCH("/update-locker-slot/:id", adminHandler.UpdateLockerSlot)
+			routes.DELETE("/delete-locker-slot/:id", adminHandler.DeleteLockerSlot)
 
 			// sender 
 			routes.POST("/create-sender", adminHandler.CreateSender)
@@ -55,6 +85,30 @@ func Admin(route *gin.Engine, adminHandler handler.IAdminHandler, jwtService ser
 			routes.GET("/get-detail-sender/:id", adminHandler.GetDetailSender)
 			routes.PATCH("/update-sender/:id", adminHandler.UpdateSender)
 			routes.DELETE("/delete-sender/:id", adminHandler.DeleteSender)
+
+			// Notification
+			routes.GET("/get-all-notification", adminHandler.ReadAllNotification)
+			routes.PATCH("/retry-notification/:id", adminHandler.RetryNotification)
+			routes.PATCH("/cancel-notification/:id", adminHandler.CancelNotification)
+
+			// Stats
+			routes.GET("/stats", adminHandler.GetPackageStats)
+			routes.GET("/stats/packages-received", adminHandler.GetPackageReceivedStats)
+			routes.GET("/stats/top-recipients", adminHandler.GetTopRecipients)
+
+			// Retention Policy
+			routes.POST("/create-retention-policy", adminHandler.CreateRetentionPolicy)
+			routes.GET("/get-all-retention-policy", adminHandler.ReadAllRetentionPolicy)
+			routes.GET("/get-detail-retention-policy/:id", adminHandler.GetDetailRetentionPolicy)
+			routes.PATCH("/update-retention-policy/:id", adminHandler.UpdateRetentionPolicy)
+			routes.DELETE("/delete-retention-policy/:id", adminHandler.DeleteRetentionPolicy)
+
+			// Audit Log
+			routes.GET("/get-all-audit-log", adminHandler.ReadAllAuditLog)
+			routes.GET("/get-detail-audit-log/:id", adminHandler.GetDetailAuditLog)
+
+			// Search
+			routes.GET("/search", adminHandler.Search)
 		}
 	}
 }
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// Registered lists the API routes of the server sorted by path, they are what
+// permissions can be granted on.
+func Registered(server *gin.Engine) []dto.RouteResponse {
+	var routes []dto.RouteResponse
+	for _, info := range server.Routes() {
+		if !strings.HasPrefix(info.Path, "/api/") {
+			continue
+		}
+
+		routes = append(routes, dto.RouteResponse{
+			Method: info.Method,
+			Path:   info.Path,
+		})
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes
+}
@@ -7,18 +7,28 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func User(route *gin.Engine, userHandler handler.IUserHandler, jwtService service.IJWTService) {
+func User(route *gin.Engine, userHandler handler.IUserHandler, jwtService service.IJWTService, sessionService service.ISessionService, permissionService service.IPermissionService) {
 	routes := route.Group("/api/v1/user")
 	{
 		// Authentiation
 		routes.POST("/register", userHandler.Register)
 		routes.POST("/login", userHandler.Login)
 		routes.POST("/refresh-token", userHandler.RefreshToken)
+		routes.POST("/verify-email", userHandler.VerifyEmail)
+		routes.POST("/resend-verification", userHandler.ResendVerification)
+		routes.POST("/forgot-password", userHandler.ForgotPassword)
+		routes.POST("/reset-password", userHandler.ResetPassword)
+		routes.POST("/request-otp", userHandler.RequestLoginOTP)
+		routes.POST("/verify-otp", userHandler.VerifyLoginOTP)
 
 		routes.GET("/get-all-company", userHandler.ReadAllCompany)
 
-		routes.Use(middleware.Authentication(jwtService), middleware.RouteAccessControl(jwtService))
+		routes.Use(middleware.Authentication(jwtService, sessionService), middleware.RouteAccessControl(permissionService))
 		{
+			// Session
+			routes.POST("/logout", userHandler.Logout)
+			routes.POST("/logout-all", userHandler.LogoutAll)
+
 			// User
 			routes.GET("/get-detail-user", userHandler.GetDetailUser)
 			routes.PATCH("/update-user", userHandler.UpdateUser)
@@ -27,6 +37,11 @@ func User(route *gin.Engine, userHandler handler.IUserHandler, jwtService servic
 			routes.GET("/get-all-package", userHandler.ReadAllPackage)
 			routes.GET("/get-detail-package/:id", userHandler.GetDetailPackage)
 			routes.GET("/get-all-package-history/:id", userHandler.GetAllPackageHistory)
+
+			// Pickup Delegation
+			routes.POST("/create-pickup-delegation", userHandler.CreatePickupDelegation)
+			routes.GET("/get-all-pickup-delegation", userHandler.ReadAllPickupDelegation)
+			routes.PATCH("/revoke-pickup-delegation/:id", userHandler.RevokePickupDelegation)
 		}
 	}
 }
@@ -2,52 +2,114 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Amierza/TitipanQ/backend/dto"
 	"github.com/Amierza/TitipanQ/backend/entity"
 	"github.com/Amierza/TitipanQ/backend/helpers"
-	"github.com/Amierza/TitipanQ/backend/internal/whatsapp"
+	"github.com/Amierza/TitipanQ/backend/internal/cache"
+	"github.com/Amierza/TitipanQ/backend/internal/label"
+	"github.com/Amierza/TitipanQ/backend/internal/notification"
+	"github.com/Amierza/TitipanQ/backend/internal/report"
+	"github.com/Amierza/TitipanQ/backend/internal/tenant"
 	"github.com/Amierza/TitipanQ/backend/repository"
 	"github.com/Amierza/TitipanQ/backend/utils"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+const (
+	notificationBatchSize   = 50
+	notificationMaxAttempts = 5
+	notificationBaseBackoff = 30 * time.Second
+	notificationMaxBackoff  = 6 * time.Hour
+	// a claimed batch must be sent within the lease or it is claimed again
+	notificationLease = 10 * time.Minute
+
+	pickupCodeMaxAttempts = 5
+
+	importPackageMaxRows = 1000
+
+	statsDefaultCacheTTL = time.Minute
+	statsDefaultTopLimit = 10
+	statsMaxTopLimit     = 100
+)
+
+// permissionManagementMethod and permissionManagementRoute identify
+// AttachPermission, the admin role can never lose access to it.
+const (
+	permissionManagementMethod = "POST"
+	permissionManagementRoute  = "/api/v1/admin/attach-permission/:id"
+)
+
+var permissionMethods = map[string]bool{
+	entity.PermissionMethodAny: true,
+	"GET":                      true,
+	"POST":                     true,
+	"PUT":                      true,
+	"PATCH":                    true,
+	"DELETE":                   true,
+}
+
+// importPackageColumns are the header names an import file must contain, at
+// least one of recipient_email and recipient_phone is required as well.
+var importPackageColumns = []string{"tracking_code", "description", "type", "quantity", "sender", "locker_code"}
+
+// errImportDryRun rolls back the import transaction of a dry run once every
+// row went through slot assignment.
+var errImportDryRun = errors.New("import dry run")
+
 type (
 	IAdminService interface {
 		// Authentication
 		Login(ctx context.Context, req dto.LoginRequest) (dto.LoginResponse, error)
 		RefreshToken(ctx context.Context, req dto.RefreshTokenRequest) (dto.RefreshTokenResponse, error)
+		Logout(ctx context.Context) error
+		LogoutAll(ctx context.Context) error
 
 		// User
 		CreateUser(ctx context.Context, req dto.CreateUserRequest) (dto.UserResponse, error)
 		ReadAllUserNoPagination(ctx context.Context) ([]dto.UserResponse, error)
 		ReadAllUserWithPagination(ctx context.Context, req dto.PaginationRequest) (dto.UserPaginationResponse, error)
+		ReadAllUserWithCursor(ctx context.Context, req dto.CursorRequest) (dto.UserCursorResponse, error)
 		GetDetailUser(ctx context.Context, userID string) (dto.UserResponse, error)
 		UpdateUser(ctx context.Context, req dto.UpdateUserRequest) (dto.UserResponse, error)
 		DeleteUser(ctx context.Context, req dto.DeleteUserRequest) (dto.UserResponse, error)
+		UnlockUser(ctx context.Context, userID string) (dto.UserResponse, error)
 
 		// cron
 		MonthlyReminderPackages() error
 		AutoSoftDeletePackages() error
+		DeliverPendingNotifications() error
 		LogSuccess(jobName, message string)
 		LogError(jobName, message string)
 
 		// Package
 		CreatePackage(ctx context.Context, req dto.CreatePackageRequest) (dto.PackageResponse, error)
-		ReadAllPackageNoPagination(ctx context.Context, userID, pkgType string) ([]dto.PackageResponse, error)
-		ReadAllPackageWithPagination(ctx context.Context, req dto.PaginationRequest, userIDStr, pkgType string) (dto.PackagePaginationResponse, error)
+		ImportPackages(ctx context.Context, req dto.ImportPackageRequest) (dto.ImportPackageResponse, error)
+		ExportPackages(ctx context.Context, req dto.ExportPackageRequest, w io.Writer) error
+		PrintPackageLabels(ctx context.Context, req dto.PackageLabelRequest, w io.Writer) error
+		ExportPackageHistories(ctx context.Context, req dto.ExportPackageRequest, w io.Writer) error
+		ReadAllPackageNoPagination(ctx context.Context, filter dto.PackageFilter) ([]dto.PackageResponse, error)
+		ReadAllPackageWithPagination(ctx context.Context, req dto.PaginationRequest, filter dto.PackageFilter) (dto.PackagePaginationResponse, error)
+		ReadAllPackageWithCursor(ctx context.Context, req dto.CursorRequest, filter dto.PackageFilter) (dto.PackageCursorResponse, error)
 		GetDetailPackage(ctx context.Context, identifier string) (dto.PackageResponse, error)
 		ReadAllPackageHistory(ctx context.Context, pkgID string) ([]dto.PackageHistoryResponse, error)
+		ReadAllPackageHistoryWithCursor(ctx context.Context, pkgID string, req dto.CursorRequest) (dto.PackageHistoryCursorResponse, error)
+		ReadPackageTimeline(ctx context.Context, pkgID string) ([]dto.PackageTimelineDay, error)
 		UpdatePackage(ctx context.Context, req dto.UpdatePackageRequest) (dto.UpdatePackageResponse, error)
 		UpdateStatusPackages(ctx context.Context, req dto.UpdateStatusPackages) error
 		DeletePackage(ctx context.Context, req dto.DeletePackageRequest) (dto.PackageResponse, error)
+		RegeneratePickupCode(ctx context.Context, pkgID string) (dto.PickupCodeResponse, error)
+		ReadAllActivePickupDelegation(ctx context.Context, pkgID string) ([]dto.PickupDelegationResponse, error)
 
 		// Company
 		CreateCompany(ctx context.Context, req dto.CreateCompanyRequest) (dto.CompanyResponse, error)
@@ -55,6 +117,7 @@ type (
 		ReadAllCompanyWithPagination(ctx context.Context, req dto.PaginationRequest) (dto.CompanyPaginationResponse, error)
 		GetDetailCompany(ctx context.Context, companyID string) (dto.CompanyResponse, error)
 		UpdateCompany(ctx context.Context, req dto.UpdateCompanyRequest) (dto.UpdateCompanyResponse, error)
+		UpdateCompanyContacts(ctx context.Context, req dto.UpdateCompanyContactsRequest) (dto.CompanyResponse, error)
 		DeleteCompany(ctx context.Context, companyID string) (dto.CompanyResponse, error)
 
 		// Locker
@@ -64,27 +127,139 @@ type (
 		GetLockerByID(ctx context.Context, lockerID string) (dto.LockerResponse, error)
 		UpdateLocker(ctx context.Context, req dto.UpdateLockerRequest) (dto.LockerResponse, error)
 		DeleteLocker(ctx context.Context, req dto.DeleteLockerRequest) (dto.LockerResponse, error)
+		GetLockerOccupancy(ctx context.Context, lockerID string) ([]dto.LockerOccupancyResponse, error)
+
+		// Locker Slot
+		CreateLockerSlot(ctx context.Context, req dto.CreateLockerSlotRequest) (dto.LockerSlotResponse, error)
+		ReadAllLockerSlot(ctx context.Context, lockerID string) ([]dto.LockerSlotOccupancyResponse, error)
+		UpdateLockerSlot(ctx context.Context, req dto.UpdateLockerSlotRequest) (dto.LockerSlotResponse, error)
+		DeleteLockerSlot(ctx context.Context, slotID string) (dto.LockerSlotResponse, error)
 
 		// Sender
 		CreateSender(ctx context.Context, req dto.CreateSenderRequest) (dto.SenderResponse, error)
 		GetAllSender(ctx context.Context) ([]dto.SenderResponse, error)
 		GetAllSenderWithPagination(ctx context.Context, req dto.PaginationRequest) (dto.SenderPaginationResponse, error)
+		GetAllSenderWithCursor(ctx context.Context, req dto.CursorRequest) (dto.SenderCursorResponse, error)
 		GetSenderByID(ctx context.Context, senderID string) (dto.SenderResponse, error)
 		UpdateSender(ctx context.Context, req dto.UpdateSenderRequest) (dto.SenderResponse, error)
 		DeleteSender(ctx context.Context, req dto.DeleteSenderRequest) (dto.SenderResponse, error)
+
+		// Notification
+		ReadAllNotificationWithPagination(ctx context.Context, req dto.PaginationRequest, status string) (dto.NotificationPaginationResponse, error)
+		RetryNotification(ctx context.Context, notificationID string) (dto.NotificationResponse, error)
+		CancelNotification(ctx context.Context, notificationID string) (dto.NotificationResponse, error)
+
+		// Stats
+		GetPackageStats(ctx context.Context, req dto.StatsRequest) (dto.PackageStatsResponse, error)
+		GetPackageReceivedStats(ctx context.Context, req dto.StatsRequest) (dto.PackageReceivedStatsResponse, error)
+		GetTopRecipients(ctx context.Context, req dto.StatsRequest) ([]dto.TopRecipientResponse, error)
+		StatsCacheTTL() time.Duration
+
+		// Retention Policy
+		CreateRetentionPolicy(ctx context.Context, req dto.CreateRetentionPolicyRequest) (dto.RetentionPolicyResponse, error)
+		ReadAllRetentionPolicy(ctx context.Context) ([]dto.RetentionPolicyResponse, error)
+		GetDetailRetentionPolicy(ctx context.Context, policyID string) (dto.RetentionPolicyResponse, error)
+		UpdateRetentionPolicy(ctx context.Context, req dto.UpdateRetentionPolicyRequest) (dto.RetentionPolicyResponse, error)
+		DeleteRetentionPolicy(ctx context.Context, policyID string) (dto.RetentionPolicyResponse, error)
+
+		// Role & Permission
+		RegisterRoutes(routes []dto.RouteResponse)
+		CreateRole(ctx context.Context, req dto.CreateRoleRequest) (dto.RoleDetailResponse, error)
+		ReadAllRole(ctx context.Context) ([]dto.RoleDetailResponse, error)
+		GetDetailRole(ctx context.Context, roleID string) (dto.RoleDetailResponse, error)
+		UpdateRole(ctx context.Context, req dto.UpdateRoleRequest) (dto.RoleDetailResponse, error)
+		DeleteRole(ctx context.Context, roleID string) (dto.RoleDetailResponse, error)
+		ReadAllRoute(ctx context.Context, roleID string) ([]dto.RouteResponse, error)
+		AttachPermission(ctx context.Context, req dto.AttachPermissionRequest) (dto.PermissionResponse, error)
+		DetachPermission(ctx context.Context, permissionID string) (dto.PermissionResponse, error)
+		AssignRole(ctx context.Context, req dto.AssignRoleRequest) (dto.AssignRoleResponse, error)
 	}
 
 	AdminService struct {
-		adminRepo  repository.IAdminRepository
-		jwtService IJWTService
+		adminRepo         repository.IAdminRepository
+		jwtService        IJWTService
+		sessionService    ISessionService
+		permissionService IPermissionService
+		loginGuard        ILoginGuardService
+		notifier          notification.INotifier
+		statsCache        *cache.TTL
+		routes            []dto.RouteResponse
 	}
 )
 
-func NewAdminService(adminRepo repository.IAdminRepository, jwtService IJWTService) *AdminService {
+func NewAdminService(adminRepo repository.IAdminRepository, jwtService IJWTService, sessionService ISessionService, permissionService IPermissionService, loginGuard ILoginGuardService, notifier notification.INotifier) *AdminService {
+	statsCacheTTL := statsDefaultCacheTTL
+	if ttl, err := time.ParseDuration(os.Getenv("STATS_CACHE_TTL")); err == nil {
+		statsCacheTTL = ttl
+	}
+
 	return &AdminService{
-		adminRepo:  adminRepo,
-		jwtService: jwtService,
+		adminRepo:         adminRepo,
+		jwtService:        jwtService,
+		sessionService:    sessionService,
+		permissionService: permissionService,
+		loginGuard:        loginGuard,
+		notifier:          notifier,
+		statsCache:        cache.NewTTL(statsCacheTTL),
+	}
+}
+
+// enqueueNotification writes the message to the outbox using the caller's
+// transaction, DeliverPendingNotifications sends it afterwards.
+func (as *AdminService) enqueueNotification(ctx context.Context, tx *gorm.DB, user entity.User, pkgID *uuid.UUID, subject, body, imagePath string) error {
+	var channels []string
+	for _, channel := range user.GetNotificationChannels() {
+		channels = append(channels, string(channel))
+	}
+
+	outbox := entity.NotificationOutbox{
+		ID:            uuid.New(),
+		Channels:      strings.Join(channels, ","),
+		PhoneNumber:   user.PhoneNumber,
+		Email:         user.Email,
+		Subject:       subject,
+		Body:          body,
+		ImagePath:     imagePath,
+		Status:        entity.NotificationPending,
+		NextAttemptAt: time.Now(),
+		PackageID:     pkgID,
+	}
+	if user.ID != uuid.Nil {
+		outbox.UserID = &user.ID
+	}
+
+	if err := as.adminRepo.CreateNotification(ctx, tx, outbox); err != nil {
+		return dto.ErrCreateNotification
+	}
+
+	return nil
+}
+
+// notifyPackage notifies the recipients of a package: its owner, or for a
+// company package the company's contacts, every member when none is set.
+func (as *AdminService) notifyPackage(ctx context.Context, tx *gorm.DB, pkg entity.Package, subject, body, imagePath string) error {
+	if pkg.CompanyID == nil {
+		return as.enqueueNotification(ctx, tx, pkg.User, &pkg.ID, subject, body, imagePath)
+	}
+
+	recipients, err := as.adminRepo.GetCompanyMembers(ctx, tx, pkg.CompanyID.String(), true)
+	if err != nil {
+		return dto.ErrGetCompanyMembers
+	}
+	if len(recipients) == 0 {
+		recipients, err = as.adminRepo.GetCompanyMembers(ctx, tx, pkg.CompanyID.String(), false)
+		if err != nil {
+			return dto.ErrGetCompanyMembers
+		}
+	}
+
+	for _, recipient := range recipients {
+		if err := as.enqueueNotification(ctx, tx, recipient, &pkg.ID, subject, body, imagePath); err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
 // Authentication
@@ -97,26 +272,35 @@ func (as *AdminService) Login(ctx context.Context, req dto.LoginRequest) (dto.Lo
 		return dto.LoginResponse{}, dto.ErrInvalidPassword
 	}
 
+	if err := as.loginGuard.CheckLogin(ctx, req.Email); err != nil {
+		return dto.LoginResponse{}, err
+	}
+
 	user, flag, err := as.adminRepo.GetUserByEmail(ctx, nil, req.Email)
 	if !flag || err != nil {
+		as.loginGuard.RecordFailure(ctx, req.Email, entity.LoginAttemptEmailNotFound)
 		return dto.LoginResponse{}, dto.ErrEmailNotFound
 	}
 
-	if user.Role.Name != "admin" {
+	if !user.Role.CanSignInTo(entity.AdminAPIPrefix) {
+		as.loginGuard.RecordFailure(ctx, req.Email, entity.LoginAttemptDeniedAccess)
 		return dto.LoginResponse{}, dto.ErrDeniedAccess
 	}
 
 	checkPassword, err := helpers.CheckPassword(user.Password, []byte(req.Password))
 	if err != nil || !checkPassword {
+		as.loginGuard.RecordFailure(ctx, req.Email, entity.LoginAttemptPasswordNotMatch)
 		return dto.LoginResponse{}, dto.ErrPasswordNotMatch
 	}
 
-	permissions, _, err := as.adminRepo.GetPermissionsByRoleID(ctx, nil, user.RoleID.String())
+	as.loginGuard.RecordSuccess(ctx, user)
+
+	session, err := as.sessionService.StartSession(ctx, user.ID)
 	if err != nil {
-		return dto.LoginResponse{}, dto.ErrGetPermissionsByRoleID
+		return dto.LoginResponse{}, err
 	}
 
-	accessToken, refreshToken, err := as.jwtService.GenerateToken(user.ID.String(), user.RoleID.String(), permissions)
+	accessToken, refreshToken, err := as.jwtService.GenerateToken(user.ID.String(), user.RoleID.String(), session)
 	if err != nil {
 		return dto.LoginResponse{}, err
 	}
@@ -127,41 +311,55 @@ func (as *AdminService) Login(ctx context.Context, req dto.LoginRequest) (dto.Lo
 	}, nil
 }
 func (as *AdminService) RefreshToken(ctx context.Context, req dto.RefreshTokenRequest) (dto.RefreshTokenResponse, error) {
-	_, err := as.jwtService.ValidateToken(req.RefreshToken)
-	if err != nil {
-		return dto.RefreshTokenResponse{}, dto.ErrValidateToken
-	}
-
 	userID, err := as.jwtService.GetUserIDByToken(req.RefreshToken)
 	if err != nil {
 		return dto.RefreshTokenResponse{}, dto.ErrGetUserIDFromToken
 	}
 
-	roleID, err := as.jwtService.GetRoleIDByToken(req.RefreshToken)
-	if err != nil {
-		return dto.RefreshTokenResponse{}, dto.ErrGetRoleFromToken
+	// the role is read again so demoted or deleted users cannot refresh
+	user, flag, err := as.adminRepo.GetUserByID(ctx, nil, userID)
+	if err != nil || !flag {
+		return dto.RefreshTokenResponse{}, dto.ErrUserNotFound
+	}
+
+	if !user.Role.CanSignInTo(entity.AdminAPIPrefix) {
+		return dto.RefreshTokenResponse{}, dto.ErrDeniedAccess
 	}
 
-	role, err := as.adminRepo.GetRoleByID(ctx, nil, roleID)
+	session, err := as.sessionService.RotateSession(ctx, req.RefreshToken)
 	if err != nil {
-		return dto.RefreshTokenResponse{}, dto.ErrGetRoleFromID
+		return dto.RefreshTokenResponse{}, err
 	}
 
-	if role.Name != "admin" {
-		return dto.RefreshTokenResponse{}, dto.ErrDeniedAccess
+	accessToken, refreshToken, err := as.jwtService.GenerateToken(userID, user.RoleID.String(), session)
+	if err != nil {
+		return dto.RefreshTokenResponse{}, err
 	}
 
-	permissions, flag, err := as.adminRepo.GetPermissionsByRoleID(ctx, nil, roleID)
-	if err != nil || !flag {
-		return dto.RefreshTokenResponse{}, dto.ErrGetPermissionsByRoleID
+	return dto.RefreshTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+func (as *AdminService) Logout(ctx context.Context) error {
+	token := ctx.Value("Authorization").(string)
+
+	sessionID, err := as.jwtService.GetSessionIDByToken(token)
+	if err != nil {
+		return err
 	}
 
-	accessToken, _, err := as.jwtService.GenerateToken(userID, roleID, permissions)
+	return as.sessionService.RevokeSession(ctx, sessionID, entity.SessionRevokedLogout)
+}
+func (as *AdminService) LogoutAll(ctx context.Context) error {
+	token := ctx.Value("Authorization").(string)
+
+	userID, err := as.jwtService.GetUserIDByToken(token)
 	if err != nil {
-		return dto.RefreshTokenResponse{}, dto.ErrGenerateAccessToken
+		return dto.ErrGetUserIDFromToken
 	}
 
-	return dto.RefreshTokenResponse{AccessToken: accessToken}, nil
+	return as.sessionService.RevokeAllUserSessions(ctx, userID, entity.SessionRevokedLogoutAll)
 }
 
 // User
@@ -170,7 +368,8 @@ func (as *AdminService) CreateUser(ctx context.Context, req dto.CreateUserReques
 		return dto.UserResponse{}, dto.ErrInvalidName
 	}
 
-	_, flag, err := as.adminRepo.GetUserByEmail(ctx, nil, req.Email)
+	// emails are unique over all companies, not only the ones in scope
+	_, flag, err := as.adminRepo.GetUserByEmail(tenant.WithoutScope(ctx), nil, req.Email)
 	if flag || err == nil {
 		return dto.UserResponse{}, dto.ErrEmailAlreadyExists
 	}
@@ -183,37 +382,51 @@ func (as *AdminService) CreateUser(ctx context.Context, req dto.CreateUserReques
 		return dto.UserResponse{}, dto.ErrInvalidPassword
 	}
 
+	// a company admin could not see a user outside its companies
+	if _, scoped := tenant.Companies(ctx); scoped && len(req.CompanyIDs) == 0 {
+		return dto.UserResponse{}, dto.ErrCompanyRequired
+	}
+
 	phoneNumberFormatted, err := helpers.StandardizePhoneNumber(req.PhoneNumber)
 	if err != nil {
 		return dto.UserResponse{}, dto.ErrFormatPhoneNumber
 	}
 
+	notificationChannels, err := joinNotificationChannels(req.NotificationChannels)
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+
 	role, _, err := as.adminRepo.GetRoleByName(ctx, nil, "user")
 	if err != nil {
 		return dto.UserResponse{}, dto.ErrGetRoleFromName
 	}
 
+	// accounts created by an admin do not go through email verification
+	now := time.Now()
 	user := entity.User{
-		ID:          uuid.New(),
-		Name:        req.Name,
-		Email:       req.Email,
-		Password:    req.Password,
-		PhoneNumber: phoneNumberFormatted,
-		Address:     req.Address,
-		RoleID:      &role.ID,
-		Role:        role,
-	}
-
-	if err := as.adminRepo.CreateUser(ctx, nil, user); err != nil {
-		return dto.UserResponse{}, dto.ErrRegisterUser
+		ID:                   uuid.New(),
+		Name:                 req.Name,
+		Email:                req.Email,
+		Password:             req.Password,
+		PhoneNumber:          phoneNumberFormatted,
+		Address:              req.Address,
+		NotificationChannels: notificationChannels,
+		EmailVerifiedAt:      &now,
+		RoleID:               &role.ID,
+		Role:                 role,
 	}
 
 	var companies []dto.CompanyResponse
-	if len(req.CompanyIDs) > 0 {
+	err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		if err := as.adminRepo.CreateUser(ctx, tx, user); err != nil {
+			return dto.ErrRegisterUser
+		}
+
 		for _, cid := range req.CompanyIDs {
-			c, found, err := as.adminRepo.GetCompanyByID(ctx, nil, cid.String())
+			c, found, err := as.adminRepo.GetCompanyByID(ctx, tx, cid.String())
 			if err != nil || !found {
-				return dto.UserResponse{}, dto.ErrGetCompanyByID
+				return dto.ErrGetCompanyByID
 			}
 
 			userCompany := entity.UserCompany{
@@ -222,8 +435,8 @@ func (as *AdminService) CreateUser(ctx context.Context, req dto.CreateUserReques
 				CompanyID: cid,
 			}
 
-			if err := as.adminRepo.CreateUserCompany(ctx, nil, userCompany); err != nil {
-				return dto.UserResponse{}, dto.ErrRegisterUser
+			if err := as.adminRepo.CreateUserCompany(ctx, tx, userCompany); err != nil {
+				return dto.ErrRegisterUser
 			}
 
 			companies = append(companies, dto.CompanyResponse{
@@ -232,16 +445,22 @@ func (as *AdminService) CreateUser(ctx context.Context, req dto.CreateUserReques
 				Address: c.Address,
 			})
 		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.UserResponse{}, err
 	}
 
 	res := dto.UserResponse{
-		ID:          user.ID,
-		Name:        user.Name,
-		Email:       user.Email,
-		Password:    user.Password,
-		PhoneNumber: user.PhoneNumber,
-		Address:     user.Address,
-		Companies:   companies,
+		ID:                   user.ID,
+		Name:                 user.Name,
+		Email:                user.Email,
+		Password:             user.Password,
+		PhoneNumber:          user.PhoneNumber,
+		Address:              user.Address,
+		NotificationChannels: user.GetNotificationChannels(),
+		Companies:            companies,
 		Role: dto.RoleResponse{
 			ID:   user.RoleID,
 			Name: user.Role.Name,
@@ -293,27 +512,7 @@ func (as *AdminService) ReadAllUserWithPagination(ctx context.Context, req dto.P
 
 	var datas []dto.UserResponse
 	for _, user := range dataWithPaginate.Users {
-		var companies []dto.CompanyResponse
-		for _, uc := range user.UserCompanies {
-			companies = append(companies, dto.CompanyResponse{
-				ID:      &uc.Company.ID,
-				Name:    uc.Company.Name,
-				Address: uc.Company.Address,
-			})
-		}
-		datas = append(datas, dto.UserResponse{
-			ID:          user.ID,
-			Name:        user.Name,
-			Email:       user.Email,
-			Password:    user.Password,
-			PhoneNumber: user.PhoneNumber,
-			Address:     user.Address,
-			Companies:   companies,
-			Role: dto.RoleResponse{
-				ID:   user.RoleID,
-				Name: user.Role.Name,
-			},
-		})
+		datas = append(datas, toUserListResponse(user))
 	}
 
 	return dto.UserPaginationResponse{
@@ -326,6 +525,26 @@ func (as *AdminService) ReadAllUserWithPagination(ctx context.Context, req dto.P
 		},
 	}, nil
 }
+func (as *AdminService) ReadAllUserWithCursor(ctx context.Context, req dto.CursorRequest) (dto.UserCursorResponse, error) {
+	if err := req.Validate(); err != nil {
+		return dto.UserCursorResponse{}, err
+	}
+
+	dataWithCursor, err := as.adminRepo.GetAllUserWithCursor(ctx, nil, req)
+	if err != nil {
+		return dto.UserCursorResponse{}, dto.ErrGetAllUserWithCursor
+	}
+
+	datas := []dto.UserResponse{}
+	for _, user := range dataWithCursor.Users {
+		datas = append(datas, toUserListResponse(user))
+	}
+
+	return dto.UserCursorResponse{
+		Data:           datas,
+		CursorResponse: dataWithCursor.CursorResponse,
+	}, nil
+}
 
 func (as *AdminService) GetDetailUser(ctx context.Context, userID string) (dto.UserResponse, error) {
 	user, _, err := as.adminRepo.GetUserByID(ctx, nil, userID)
@@ -343,27 +562,48 @@ func (as *AdminService) GetDetailUser(ctx context.Context, userID string) (dto.U
 	}
 
 	return dto.UserResponse{
-		ID:          user.ID,
-		Name:        user.Name,
-		Email:       user.Email,
-		Password:    user.Password,
-		PhoneNumber: user.PhoneNumber,
-		Address:     user.Address,
-		Companies:   companies,
+		ID:                   user.ID,
+		Name:                 user.Name,
+		Email:                user.Email,
+		Password:             user.Password,
+		PhoneNumber:          user.PhoneNumber,
+		Address:              user.Address,
+		NotificationChannels: user.GetNotificationChannels(),
+		Companies:            companies,
 		Role: dto.RoleResponse{
 			ID:   user.RoleID,
 			Name: user.Role.Name,
 		},
+		FailedLoginAttempts: user.FailedLoginAttempts,
+		LockedUntil:         user.LockedUntil,
 	}, nil
 
 }
 
+// checkManageableUser keeps company admins to the residents of their
+// companies, anyone who can sign in to the admin API may share a company with
+// them but must stay out of their reach.
+func checkManageableUser(ctx context.Context, user entity.User) error {
+	if _, scoped := tenant.Companies(ctx); !scoped {
+		return nil
+	}
+
+	if user.Role.CanSignInTo(entity.AdminAPIPrefix) {
+		return dto.ErrUserNotManageable
+	}
+
+	return nil
+}
 func (as *AdminService) UpdateUser(ctx context.Context, req dto.UpdateUserRequest) (dto.UserResponse, error) {
 	user, _, err := as.adminRepo.GetUserByID(ctx, nil, req.ID)
 	if err != nil {
 		return dto.UserResponse{}, dto.ErrGetUserByID
 	}
 
+	if err := checkManageableUser(ctx, user); err != nil {
+		return dto.UserResponse{}, err
+	}
+
 	if req.Name != "" {
 		if len(req.Name) < 5 {
 			return dto.UserResponse{}, dto.ErrInvalidName
@@ -377,7 +617,8 @@ func (as *AdminService) UpdateUser(ctx context.Context, req dto.UpdateUserReques
 			return dto.UserResponse{}, dto.ErrInvalidEmail
 		}
 
-		_, flag, err := as.adminRepo.GetUserByEmail(ctx, nil, req.Email)
+		// emails are unique over all companies, not only the ones in scope
+		_, flag, err := as.adminRepo.GetUserByEmail(tenant.WithoutScope(ctx), nil, req.Email)
 		if flag || err == nil {
 			return dto.UserResponse{}, dto.ErrEmailAlreadyExists
 		}
@@ -411,32 +652,46 @@ func (as *AdminService) UpdateUser(ctx context.Context, req dto.UpdateUserReques
 		user.Address = req.Address
 	}
 
-	if len(req.CompanyIDs) > 0 {
-		err = as.adminRepo.DeleteUserCompaniesByUserID(ctx, nil, user.ID.String())
+	if len(req.NotificationChannels) > 0 {
+		notificationChannels, err := joinNotificationChannels(req.NotificationChannels)
 		if err != nil {
 			return dto.UserResponse{}, err
 		}
-		for _, compID := range req.CompanyIDs {
-			_, flag, err := as.adminRepo.GetCompanyByID(ctx, nil, compID.String())
-			if err != nil || !flag {
-				return dto.UserResponse{}, dto.ErrGetCompanyByID
-			}
 
-			userCompany := entity.UserCompany{
-				ID:        uuid.New(),
-				UserID:    &user.ID,
-				CompanyID: compID,
+		user.NotificationChannels = notificationChannels
+	}
+
+	err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		if len(req.CompanyIDs) > 0 {
+			if err := as.adminRepo.DeleteUserCompaniesByUserID(ctx, tx, user.ID.String()); err != nil {
+				return dto.ErrDeletedUserCompanies
 			}
-			err = as.adminRepo.CreateUserCompany(ctx, nil, userCompany)
-			if err != nil {
-				return dto.UserResponse{}, err
+
+			for _, compID := range req.CompanyIDs {
+				_, flag, err := as.adminRepo.GetCompanyByID(ctx, tx, compID.String())
+				if err != nil || !flag {
+					return dto.ErrGetCompanyByID
+				}
+
+				userCompany := entity.UserCompany{
+					ID:        uuid.New(),
+					UserID:    &user.ID,
+					CompanyID: compID,
+				}
+				if err := as.adminRepo.CreateUserCompany(ctx, tx, userCompany); err != nil {
+					return dto.ErrFailedCreateUserCompany
+				}
 			}
 		}
-	}
 
-	err = as.adminRepo.UpdateUser(ctx, nil, user)
+		if err := as.adminRepo.UpdateUser(ctx, tx, user); err != nil {
+			return dto.ErrUpdateUser
+		}
+
+		return nil
+	})
 	if err != nil {
-		return dto.UserResponse{}, dto.ErrUpdateUser
+		return dto.UserResponse{}, err
 	}
 
 	user, _, err = as.adminRepo.GetUserByID(ctx, nil, req.ID)
@@ -454,13 +709,14 @@ func (as *AdminService) UpdateUser(ctx context.Context, req dto.UpdateUserReques
 	}
 
 	res := dto.UserResponse{
-		ID:          user.ID,
-		Name:        user.Name,
-		Email:       user.Email,
-		Password:    user.Password,
-		PhoneNumber: user.PhoneNumber,
-		Address:     user.Address,
-		Companies:   companies,
+		ID:                   user.ID,
+		Name:                 user.Name,
+		Email:                user.Email,
+		Password:             user.Password,
+		PhoneNumber:          user.PhoneNumber,
+		Address:              user.Address,
+		NotificationChannels: user.GetNotificationChannels(),
+		Companies:            companies,
 		Role: dto.RoleResponse{
 			ID:   user.RoleID,
 			Name: user.Role.Name,
@@ -470,21 +726,68 @@ func (as *AdminService) UpdateUser(ctx context.Context, req dto.UpdateUserReques
 	return res, nil
 }
 
+// UnlockUser lifts a lockout early and forgets the user's wrong passwords.
+func (as *AdminService) UnlockUser(ctx context.Context, userID string) (dto.UserResponse, error) {
+	user, flag, err := as.adminRepo.GetUserByID(ctx, nil, userID)
+	if err != nil || !flag {
+		return dto.UserResponse{}, dto.ErrUserNotFound
+	}
+
+	if err := checkManageableUser(ctx, user); err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	if err := as.loginGuard.UnlockUser(ctx, user.ID); err != nil {
+		return dto.UserResponse{}, err
+	}
+
+	return dto.UserResponse{
+		ID:    user.ID,
+		Name:  user.Name,
+		Email: user.Email,
+	}, nil
+}
 func (as *AdminService) DeleteUser(ctx context.Context, req dto.DeleteUserRequest) (dto.UserResponse, error) {
 	deletedUser, _, err := as.adminRepo.GetUserByID(ctx, nil, req.UserID)
 	if err != nil {
 		return dto.UserResponse{}, dto.ErrGetUserByID
 	}
 
-	// Hapus relasi many-to-many di pivot table UserCompany
-	if err := as.adminRepo.DeleteUserCompaniesByUserID(ctx, nil, req.UserID); err != nil {
+	if err := checkManageableUser(ctx, deletedUser); err != nil {
 		return dto.UserResponse{}, err
 	}
 
-	// Hapus user
-	err = as.adminRepo.DeleteUserByID(ctx, nil, req.UserID)
+	err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		if deletedUser.Role.Name == entity.RoleAdmin {
+			count, err := as.lockedAdminCount(ctx, tx)
+			if err != nil {
+				return err
+			}
+
+			if count <= 1 {
+				return dto.ErrLastAdmin
+			}
+		}
+
+		// Hapus relasi many-to-many di pivot table UserCompany
+		if err := as.adminRepo.DeleteUserCompaniesByUserID(ctx, tx, req.UserID); err != nil {
+			return dto.ErrDeletedUserCompanies
+		}
+
+		// Hapus user
+		if err := as.adminRepo.DeleteUserByID(ctx, tx, req.UserID); err != nil {
+			return dto.ErrDeleteUserByID
+		}
+
+		return nil
+	})
 	if err != nil {
-		return dto.UserResponse{}, dto.ErrDeleteUserByID
+		return dto.UserResponse{}, err
+	}
+
+	// tokens of a deleted user must stop working right away
+	if err := as.sessionService.RevokeAllUserSessions(ctx, req.UserID, entity.SessionRevokedUserDeleted); err != nil {
+		return dto.UserResponse{}, err
 	}
 
 	var companies []dto.CompanyResponse
@@ -531,6 +834,11 @@ func (as *AdminService) CreatePackage(ctx context.Context, req dto.CreatePackage
 		return dto.PackageResponse{}, dto.ErrMissingRequiredField
 	}
 
+	// tracking codes are unique over all companies, not only the ones in scope
+	if _, found, _ := as.adminRepo.GetPackageByTrackingCode(tenant.WithoutScope(ctx), nil, req.TrackingCode); found {
+		return dto.PackageResponse{}, dto.ErrTrackingCodeExists
+	}
+
 	locker, found, err := as.adminRepo.GetLockerByID(ctx, nil, req.LockerID.String())
 	if err != nil || !found {
 		return dto.PackageResponse{}, dto.ErrLockerNotFound
@@ -569,9 +877,32 @@ func (as *AdminService) CreatePackage(ctx context.Context, req dto.CreatePackage
 		return dto.PackageResponse{}, dto.ErrInvalidPackageType
 	}
 
-	user, flag, err := as.adminRepo.GetUserByID(ctx, nil, req.UserID.String())
-	if !flag || err != nil {
-		return dto.PackageResponse{}, dto.ErrUserNotFound
+	if req.Size == "" {
+		req.Size = entity.SmallSlot
+	}
+	if !entity.IsValidSlotSize(req.Size) {
+		return dto.PackageResponse{}, dto.ErrInvalidSlotSize
+	}
+
+	// a package goes either to one user or to a whole company
+	if (req.UserID == uuid.Nil) == (req.CompanyID == nil) {
+		return dto.PackageResponse{}, dto.ErrPackageRecipient
+	}
+
+	var (
+		user    entity.User
+		company entity.Company
+	)
+	if req.CompanyID != nil {
+		company, found, err = as.adminRepo.GetCompanyByID(ctx, nil, req.CompanyID.String())
+		if err != nil || !found {
+			return dto.PackageResponse{}, dto.ErrCompanyNotFound
+		}
+	} else {
+		user, found, err = as.adminRepo.GetUserByID(ctx, nil, req.UserID.String())
+		if err != nil || !found {
+			return dto.PackageResponse{}, dto.ErrUserNotFound
+		}
 	}
 
 	pkg := entity.Package{
@@ -582,17 +913,18 @@ func (as *AdminService) CreatePackage(ctx context.Context, req dto.CreatePackage
 		Type:         req.Type,
 		Status:       entity.Received,
 		Quantity:     req.Quantity,
+		Size:         req.Size,
 		SenderID:     req.SenderID,
-		UserID:       &user.ID,
 		LockerID:     req.LockerID,
 		TimeStamp: entity.TimeStamp{
 			CreatedAt: now,
 			UpdatedAt: now,
 		},
 	}
-
-	if err := as.adminRepo.CreatePackage(ctx, nil, pkg); err != nil {
-		return dto.PackageResponse{}, dto.ErrCreatePackage
+	if req.CompanyID != nil {
+		pkg.CompanyID, pkg.Company = &company.ID, company
+	} else {
+		pkg.UserID, pkg.User = &user.ID, user
 	}
 
 	sender, found, err := as.adminRepo.GetSenderByID(ctx, nil, req.SenderID.String())
@@ -602,22 +934,59 @@ func (as *AdminService) CreatePackage(ctx context.Context, req dto.CreatePackage
 
 	pkg.Sender = sender
 
-	message := utils.BuildReceivedMessage(&pkg)
-	imagePath := "assets/package/" + pkg.Image
-	if err := whatsapp.SendTextMessage(user.PhoneNumber, message, imagePath, "image/png"); err != nil {
-		log.Println("Failed to send WhatsApp notification:", err)
+	policy, err := as.adminRepo.ResolveRetentionPolicy(ctx, nil, pkg.UserID, pkg.CompanyID, pkg.Type)
+	if err != nil {
+		return dto.PackageResponse{}, dto.ErrGetRetentionPolicy
+	}
+	pkg.ExpiredAt = helpers.PtrTime(now.AddDate(0, 0, policy.ExpiryDays))
+
+	pickupCode, err := helpers.GeneratePickupCode()
+	if err != nil {
+		return dto.PackageResponse{}, dto.ErrGeneratePickupCode
+	}
+
+	pkg.PickupCode, err = helpers.HashPassword(pickupCode)
+	if err != nil {
+		return dto.PackageResponse{}, dto.ErrGeneratePickupCode
 	}
 
-	history := entity.PackageHistory{
+	history := as.withActor(ctx, entity.PackageHistory{
 		ID:          uuid.New(),
+		EventType:   entity.HistoryReceived,
 		Status:      entity.Received,
 		Description: "package received",
 		PackageID:   &pkg.ID,
 		ChangedBy:   &IDChanger,
+	})
+
+	message := utils.BuildReceivedMessage(&pkg, pickupCode)
+	imagePath := ""
+	if pkg.Image != "" {
+		imagePath = "assets/package/" + pkg.Image
 	}
 
-	if err := as.adminRepo.CreatePackageHistory(ctx, nil, history); err != nil {
-		return dto.PackageResponse{}, dto.ErrCreatePackageHistory
+	var slot *entity.LockerSlot
+	err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		slot, err = as.assignSlot(ctx, tx, locker.ID, pkg.Size, nil)
+		if err != nil {
+			return err
+		}
+		if slot != nil {
+			pkg.SlotID = &slot.ID
+		}
+
+		if err := as.adminRepo.CreatePackage(ctx, tx, pkg); err != nil {
+			return dto.ErrCreatePackage
+		}
+
+		if err := as.adminRepo.CreatePackageHistory(ctx, tx, history); err != nil {
+			return dto.ErrCreatePackageHistory
+		}
+
+		return as.notifyPackage(ctx, tx, pkg, "Package received", message, imagePath)
+	})
+	if err != nil {
+		return dto.PackageResponse{}, err
 	}
 
 	var companies []dto.CompanyResponse
@@ -650,6 +1019,9 @@ func (as *AdminService) CreatePackage(ctx context.Context, req dto.CreatePackage
 			LockerCode: locker.LockerCode,
 			Location:   locker.Location,
 		},
+		Size:    pkg.Size,
+		Slot:    toPackageSlotResponse(slot),
+		Company: toPackageCompanyResponse(pkg),
 		User: dto.UserResponse{
 			ID:          user.ID,
 			Name:        user.Name,
@@ -670,77 +1042,598 @@ func (as *AdminService) CreatePackage(ctx context.Context, req dto.CreatePackage
 	}, nil
 }
 
-func (as *AdminService) ReadAllPackageNoPagination(ctx context.Context, userID, pkgType string) ([]dto.PackageResponse, error) {
-	packages, err := as.adminRepo.GetAllPackage(ctx, nil, userID, pkgType)
+type importPackageRow struct {
+	result     dto.ImportPackageRowResult
+	pkg        entity.Package
+	user       entity.User
+	pickupCode string
+}
+
+func (r *importPackageRow) fail(err error) {
+	r.result.Errors = append(r.result.Errors, err.Error())
+}
+
+// ImportPackages creates the packages listed in a CSV or XLSX file. Rows are
+// validated first, recipients, senders and lockers are resolved by email or
+// phone, name and locker code. Nothing is written unless every row is valid,
+// and each recipient gets one notification listing all of their packages.
+func (as *AdminService) ImportPackages(ctx context.Context, req dto.ImportPackageRequest) (dto.ImportPackageResponse, error) {
+	token := ctx.Value("Authorization").(string)
+
+	userId, err := as.jwtService.GetUserIDByToken(token)
 	if err != nil {
-		return nil, err
+		return dto.ImportPackageResponse{}, dto.ErrGetUserIDFromToken
 	}
 
-	var datas []dto.PackageResponse
-	for _, pkg := range packages {
-		var companies []dto.CompanyResponse
-		for _, uc := range pkg.User.UserCompanies {
-			companies = append(companies, dto.CompanyResponse{
-				ID:      &uc.Company.ID,
-				Name:    uc.Company.Name,
-				Address: uc.Company.Address,
-			})
+	IDChanger, err := uuid.Parse(userId)
+	if err != nil {
+		return dto.ImportPackageResponse{}, dto.ErrParseUUID
+	}
+
+	if req.FileReader == nil || req.FileHeader == nil {
+		return dto.ImportPackageResponse{}, dto.ErrImportFileRequired
+	}
+
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(req.FileHeader.Filename), "."))
+	if ext != "csv" && ext != "xlsx" {
+		return dto.ImportPackageResponse{}, dto.ErrInvalidImportFile
+	}
+
+	records, err := helpers.ReadSpreadsheet(req.FileReader, ext)
+	if err != nil {
+		return dto.ImportPackageResponse{}, dto.ErrReadImportFile
+	}
+	if len(records) < 2 {
+		return dto.ImportPackageResponse{}, dto.ErrImportFileEmpty
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range importPackageColumns {
+		if _, ok := columns[name]; !ok {
+			return dto.ImportPackageResponse{}, fmt.Errorf("%w: %s", dto.ErrImportMissingColumn, name)
+		}
+	}
+	_, hasEmail := columns["recipient_email"]
+	_, hasPhone := columns["recipient_phone"]
+	if !hasEmail && !hasPhone {
+		return dto.ImportPackageResponse{}, fmt.Errorf("%w: recipient_email or recipient_phone", dto.ErrImportMissingColumn)
+	}
+
+	var (
+		now          = time.Now()
+		users        = make(map[string]*entity.User)
+		senders      = make(map[string]*entity.Sender)
+		lockers      = make(map[string]*entity.Locker)
+		trackingRows = make(map[string]int)
+		rows         []importPackageRow
+	)
+
+	for i, record := range records[1:] {
+		cell := func(name string) string {
+			idx, ok := columns[name]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
 		}
 
-		data := dto.PackageResponse{
-			ID:           pkg.ID,
-			TrackingCode: pkg.TrackingCode,
-			Description:  pkg.Description,
-			Image:        pkg.Image,
-			Type:         pkg.Type,
-			Status:       pkg.Status,
-			Quantity:     pkg.Quantity,
-			CompletedAt:  pkg.CompletedAt,
-			ExpiredAt:    pkg.ExpiredAt,
-			Sender: dto.SenderResponse{
-				ID:          pkg.Sender.ID,
-				Name:        pkg.Sender.Name,
-				Address:     pkg.Sender.Address,
-				PhoneNumber: pkg.Sender.PhoneNumber,
-			},
-			Locker: dto.LockerResponse{
-				ID:         pkg.Locker.ID,
-				LockerCode: pkg.Locker.LockerCode,
-				Location:   pkg.Locker.Location,
-			},
-			User: dto.UserResponse{
-				ID:          pkg.User.ID,
-				Name:        pkg.User.Name,
-				Email:       pkg.User.Email,
-				Password:    pkg.User.Password,
-				PhoneNumber: pkg.User.PhoneNumber,
-				Address:     pkg.User.Address,
-				Companies:   companies,
-				Role: dto.RoleResponse{
-					ID:   pkg.User.RoleID,
-					Name: pkg.User.Role.Name,
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		if len(rows) == importPackageMaxRows {
+			return dto.ImportPackageResponse{}, fmt.Errorf("%w: maximum is %d", dto.ErrImportTooManyRows, importPackageMaxRows)
+		}
+
+		row := importPackageRow{
+			result: dto.ImportPackageRowResult{Row: i + 2, TrackingCode: cell("tracking_code")},
+			pkg: entity.Package{
+				ID:           uuid.New(),
+				TrackingCode: cell("tracking_code"),
+				Description:  cell("description"),
+				Type:         entity.Type(strings.ToLower(cell("type"))),
+				Status:       entity.Received,
+				Size:         entity.SlotSize(strings.ToLower(cell("size"))),
+				TimeStamp: entity.TimeStamp{
+					CreatedAt: now,
+					UpdatedAt: now,
 				},
 			},
-			TimeStamp: entity.TimeStamp{
-				CreatedAt: pkg.CreatedAt,
-				UpdatedAt: pkg.UpdatedAt,
-				DeletedAt: pkg.DeletedAt,
-			},
 		}
-		datas = append(datas, data)
+
+		if row.pkg.TrackingCode == "" || row.pkg.Description == "" {
+			row.fail(dto.ErrMissingRequiredField)
+		}
+
+		if row.pkg.TrackingCode != "" {
+			if _, repeated := trackingRows[row.pkg.TrackingCode]; repeated {
+				row.fail(dto.ErrImportDuplicateTracking)
+			} else if _, found, _ := as.adminRepo.GetPackageByTrackingCode(tenant.WithoutScope(ctx), nil, row.pkg.TrackingCode); found {
+				row.fail(dto.ErrTrackingCodeExists)
+			}
+			trackingRows[row.pkg.TrackingCode] = row.result.Row
+		}
+
+		if !entity.IsValidType(row.pkg.Type) {
+			row.fail(dto.ErrInvalidPackageType)
+		}
+
+		quantity, err := strconv.Atoi(cell("quantity"))
+		if err != nil || quantity <= 0 {
+			row.fail(dto.ErrInvalidQuantityPackage)
+		}
+		row.pkg.Quantity = quantity
+
+		if row.pkg.Size == "" {
+			row.pkg.Size = entity.SmallSlot
+		}
+		if !entity.IsValidSlotSize(row.pkg.Size) {
+			row.fail(dto.ErrInvalidSlotSize)
+		}
+
+		if user, err := as.resolveImportRecipient(ctx, users, cell("recipient_email"), cell("recipient_phone")); err != nil {
+			row.fail(err)
+		} else {
+			row.user = *user
+			row.pkg.UserID = &user.ID
+			row.result.UserID = &user.ID
+		}
+
+		senderName := cell("sender")
+		sender, cached := senders[strings.ToLower(senderName)]
+		if !cached && senderName != "" {
+			if found, ok, err := as.adminRepo.GetSenderByName(ctx, nil, senderName); err == nil && ok {
+				sender = &found
+			}
+			senders[strings.ToLower(senderName)] = sender
+		}
+		if sender == nil {
+			row.fail(dto.ErrSenderNotFound)
+		} else {
+			row.pkg.SenderID = &sender.ID
+			row.pkg.Sender = *sender
+		}
+
+		lockerCode := cell("locker_code")
+		locker, cached := lockers[lockerCode]
+		if !cached && lockerCode != "" {
+			if found, ok, err := as.adminRepo.GetLockerByLockerCode(ctx, nil, lockerCode); err == nil && ok {
+				locker = &found
+			}
+			lockers[lockerCode] = locker
+		}
+		if locker == nil {
+			row.fail(dto.ErrLockerNotFound)
+		} else {
+			row.pkg.LockerID = &locker.ID
+		}
+
+		rows = append(rows, row)
 	}
 
-	return datas, nil
+	if len(rows) == 0 {
+		return dto.ImportPackageResponse{}, dto.ErrImportFileEmpty
+	}
+
+	res := dto.ImportPackageResponse{DryRun: req.DryRun}
+	if summarizeImport(&res, rows) > 0 {
+		return res, dto.ErrImportHasInvalidRows
+	}
+
+	// hashing is slow, so the codes are prepared before any locker is locked,
+	// a dry run never hands codes out
+	if !req.DryRun {
+		for i := range rows {
+			code, err := helpers.GeneratePickupCode()
+			if err != nil {
+				return dto.ImportPackageResponse{}, dto.ErrGeneratePickupCode
+			}
+
+			hash, err := helpers.HashPassword(code)
+			if err != nil {
+				return dto.ImportPackageResponse{}, dto.ErrGeneratePickupCode
+			}
+
+			rows[i].pickupCode = code
+			rows[i].pkg.PickupCode = hash
+		}
+	}
+
+	err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		for i := range rows {
+			row := &rows[i]
+
+			slot, err := as.assignSlot(ctx, tx, *row.pkg.LockerID, row.pkg.Size, nil)
+			if err != nil {
+				var lockerFullErr *dto.LockerFullError
+				if errors.As(err, &lockerFullErr) {
+					row.fail(err)
+					continue
+				}
+				return err
+			}
+			if slot != nil {
+				row.pkg.SlotID = &slot.ID
+				row.result.SlotCode = slot.SlotCode
+			}
+
+			policy, err := as.adminRepo.ResolveRetentionPolicy(ctx, tx, row.pkg.UserID, row.pkg.CompanyID, row.pkg.Type)
+			if err != nil {
+				return dto.ErrGetRetentionPolicy
+			}
+			row.pkg.ExpiredAt = helpers.PtrTime(now.AddDate(0, 0, policy.ExpiryDays))
+
+			if err := as.adminRepo.CreatePackage(ctx, tx, row.pkg); err != nil {
+				return dto.ErrImportPackage
+			}
+
+			history := as.withActor(ctx, entity.PackageHistory{
+				ID:          uuid.New(),
+				EventType:   entity.HistoryReceived,
+				Status:      entity.Received,
+				Description: "package received via bulk import",
+				PackageID:   &row.pkg.ID,
+				ChangedBy:   &IDChanger,
+			})
+			if err := as.adminRepo.CreatePackageHistory(ctx, tx, history); err != nil {
+				return dto.ErrCreatePackageHistory
+			}
+		}
+
+		if summarizeImport(&res, rows) > 0 {
+			return dto.ErrImportHasInvalidRows
+		}
+
+		var recipients []uuid.UUID
+		grouped := make(map[uuid.UUID][]*importPackageRow)
+		for i := range rows {
+			userID := rows[i].user.ID
+			if _, ok := grouped[userID]; !ok {
+				recipients = append(recipients, userID)
+			}
+			grouped[userID] = append(grouped[userID], &rows[i])
+		}
+		res.Notifications = len(recipients)
+
+		if req.DryRun {
+			return errImportDryRun
+		}
+
+		for _, userID := range recipients {
+			var (
+				packages    []entity.Package
+				pickupCodes []string
+			)
+			for _, row := range grouped[userID] {
+				packages = append(packages, row.pkg)
+				pickupCodes = append(pickupCodes, row.pickupCode)
+			}
+
+			message := utils.BuildReceivedBatchMessage(packages, pickupCodes)
+			if len(packages) == 1 {
+				message = utils.BuildReceivedMessage(&packages[0], pickupCodes[0])
+			}
+
+			var pkgID *uuid.UUID
+			if len(packages) == 1 {
+				pkgID = &packages[0].ID
+			}
+
+			if err := as.enqueueNotification(ctx, tx, grouped[userID][0].user, pkgID, "Package received", message, ""); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		if errors.Is(err, dto.ErrImportHasInvalidRows) {
+			return res, err
+		}
+		return dto.ImportPackageResponse{}, err
+	}
+
+	if !req.DryRun {
+		for i := range rows {
+			rows[i].result.PackageID = &rows[i].pkg.ID
+		}
+		summarizeImport(&res, rows)
+		res.ImportedRows = len(rows)
+	}
+
+	return res, nil
+}
+
+// resolveImportRecipient finds the recipient by email, falling back to the
+// phone number, lookups are cached per import.
+func (as *AdminService) resolveImportRecipient(ctx context.Context, cache map[string]*entity.User, email, phone string) (*entity.User, error) {
+	key := strings.ToLower(email)
+	if key == "" {
+		if phone == "" {
+			return nil, dto.ErrImportRecipientRequired
+		}
+
+		formatted, err := helpers.StandardizePhoneNumber(phone)
+		if err != nil {
+			return nil, dto.ErrFormatPhoneNumber
+		}
+		key = formatted
+	}
+
+	user, cached := cache[key]
+	if !cached {
+		var (
+			found entity.User
+			ok    bool
+			err   error
+		)
+		if email != "" {
+			found, ok, err = as.adminRepo.GetUserByEmail(ctx, nil, key)
+		} else {
+			found, ok, err = as.adminRepo.GetUserByPhoneNumber(ctx, nil, key)
+		}
+		if err == nil && ok {
+			user = &found
+		}
+		cache[key] = user
+	}
+
+	if user == nil {
+		return nil, dto.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+// summarizeImport refreshes the row report and counters, it returns the
+// number of invalid rows.
+func summarizeImport(res *dto.ImportPackageResponse, rows []importPackageRow) int {
+	res.TotalRows = len(rows)
+	res.ValidRows, res.InvalidRows = 0, 0
+	res.Rows = res.Rows[:0]
+
+	for _, row := range rows {
+		row.result.Valid = len(row.result.Errors) == 0
+		if row.result.Valid {
+			res.ValidRows++
+		} else {
+			res.InvalidRows++
+		}
+		res.Rows = append(res.Rows, row.result)
+	}
+
+	return res.InvalidRows
+}
+
+var (
+	packageExportColumns = []string{
+		"Tracking Code", "Description", "Type", "Status", "Size", "Quantity", "Recipient", "Recipient Email",
+		"Company", "Sender", "Locker", "Slot", "Received At", "Completed At", "Expired At",
+	}
+	packageHistoryExportColumns = []string{
+		"Date", "Tracking Code", "Event", "Status", "Actor", "Actor Role", "Collected By", "Changes", "Description",
+	}
+)
+
+func validateExportRequest(req dto.ExportPackageRequest) error {
+	if !report.IsValidFormat(report.Format(req.Format)) {
+		return dto.ErrInvalidExportFormat
+	}
+
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return dto.ErrInvalidDateRange
+	}
+
+	if req.Status != "" && !entity.IsValidStatus(req.Status) {
+		return dto.ErrInvalidPackageStatus
+	}
+
+	if req.Type != "" && !entity.IsValidType(req.Type) {
+		return dto.ErrInvalidPackageType
+	}
+
+	return nil
+}
+
+func exportTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04")
 }
-func (as *AdminService) ReadAllPackageWithPagination(ctx context.Context, req dto.PaginationRequest, userID, pkgType string) (dto.PackagePaginationResponse, error) {
-	dataWithPaginate, err := as.adminRepo.GetAllPackageWithPagination(ctx, nil, req, userID, pkgType)
+
+// ExportPackages writes the packages matching req to w in the requested
+// format, reading them from the database in batches.
+func (as *AdminService) ExportPackages(ctx context.Context, req dto.ExportPackageRequest, w io.Writer) error {
+	if err := validateExportRequest(req); err != nil {
+		return err
+	}
+
+	writer, err := report.NewWriter(report.Format(req.Format), w, "Packages")
 	if err != nil {
-		return dto.PackagePaginationResponse{}, dto.ErrGetAllPackageWithPagination
+		return dto.ErrInvalidExportFormat
 	}
 
-	var datas []dto.PackageResponse
+	if err := writer.WriteHeader(packageExportColumns); err != nil {
+		return dto.ErrExportPackage
+	}
 
-	for _, pkg := range dataWithPaginate.Packages {
+	err = as.adminRepo.StreamPackages(ctx, nil, req, func(packages []entity.Package) error {
+		for _, pkg := range packages {
+			var companies []string
+			if pkg.CompanyID != nil {
+				companies = append(companies, pkg.Company.Name)
+			}
+			for _, uc := range pkg.User.UserCompanies {
+				companies = append(companies, uc.Company.Name)
+			}
+
+			if err := writer.WriteRow([]string{
+				pkg.TrackingCode,
+				pkg.Description,
+				string(pkg.Type),
+				string(pkg.Status),
+				string(pkg.Size),
+				strconv.Itoa(pkg.Quantity),
+				pkg.User.Name,
+				pkg.User.Email,
+				strings.Join(companies, ", "),
+				pkg.Sender.Name,
+				pkg.Locker.LockerCode,
+				pkg.Slot.SlotCode,
+				exportTime(&pkg.CreatedAt),
+				exportTime(pkg.CompletedAt),
+				exportTime(pkg.ExpiredAt),
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.ErrExportPackage
+	}
+
+	if err := writer.Close(); err != nil {
+		return dto.ErrExportPackage
+	}
+
+	return nil
+}
+
+// ExportPackageHistories writes the history entries matching req to w in the
+// requested format, reading them from the database in batches.
+func (as *AdminService) ExportPackageHistories(ctx context.Context, req dto.ExportPackageRequest, w io.Writer) error {
+	if err := validateExportRequest(req); err != nil {
+		return err
+	}
+
+	writer, err := report.NewWriter(report.Format(req.Format), w, "Package History")
+	if err != nil {
+		return dto.ErrInvalidExportFormat
+	}
+
+	if err := writer.WriteHeader(packageHistoryExportColumns); err != nil {
+		return dto.ErrExportPackageHistory
+	}
+
+	err = as.adminRepo.StreamPackageHistories(ctx, nil, req, func(histories []entity.PackageHistory) error {
+		for _, history := range histories {
+			if err := writer.WriteRow([]string{
+				exportTime(&history.CreatedAt),
+				history.Package.TrackingCode,
+				string(history.EventType),
+				string(history.Status),
+				history.ChangedByUser.Name,
+				history.ActorRole,
+				history.CollectedBy,
+				historyChanges(history.Changes),
+				history.Description,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.ErrExportPackageHistory
+	}
+
+	if err := writer.Close(); err != nil {
+		return dto.ErrExportPackageHistory
+	}
+
+	return nil
+}
+
+// maxPackageLabels caps one print batch, the PDF is built in memory.
+const maxPackageLabels = 100
+
+// PrintPackageLabels writes a printable label for each requested package to w,
+// in the order the ids were given.
+func (as *AdminService) PrintPackageLabels(ctx context.Context, req dto.PackageLabelRequest, w io.Writer) error {
+	if !label.IsValidFormat(label.Format(req.Format)) {
+		return dto.ErrInvalidLabelFormat
+	}
+
+	if len(req.PackageIDs) == 0 {
+		return dto.ErrPackageLabelEmpty
+	}
+
+	if len(req.PackageIDs) > maxPackageLabels {
+		return dto.ErrTooManyPackageLabels
+	}
+
+	packages, err := as.adminRepo.GetPackagesByIDs(ctx, nil, req.PackageIDs)
+	if err != nil {
+		return dto.ErrPrintPackageLabel
+	}
+
+	byID := make(map[uuid.UUID]entity.Package, len(packages))
+	for _, pkg := range packages {
+		byID[pkg.ID] = pkg
+	}
+
+	labels := make([]label.Label, 0, len(req.PackageIDs))
+	for _, id := range req.PackageIDs {
+		pkg, ok := byID[id]
+		if !ok {
+			return dto.ErrPackageNotFound
+		}
+
+		labels = append(labels, toPackageLabel(pkg))
+	}
+
+	if err := label.Write(label.Format(req.Format), w, labels); err != nil {
+		return dto.ErrPrintPackageLabel
+	}
+
+	return nil
+}
+
+func toPackageLabel(pkg entity.Package) label.Label {
+	l := label.Label{
+		TrackingCode: pkg.TrackingCode,
+		Recipient:    pkg.User.Name,
+		LockerCode:   pkg.Locker.LockerCode,
+		ReceivedAt:   pkg.CreatedAt,
+	}
+
+	if slot := packageSlot(pkg); slot != nil {
+		l.LockerCode += " / " + slot.SlotCode
+	}
+
+	if pkg.CompanyID != nil {
+		l.Recipient = pkg.Company.Name
+		l.Company = pkg.Company.Name
+		return l
+	}
+
+	companies := make([]string, 0, len(pkg.User.UserCompanies))
+	for _, uc := range pkg.User.UserCompanies {
+		companies = append(companies, uc.Company.Name)
+	}
+	l.Company = strings.Join(companies, ", ")
+
+	return l
+}
+
+func (as *AdminService) ReadAllPackageNoPagination(ctx context.Context, filter dto.PackageFilter) ([]dto.PackageResponse, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	packages, err := as.adminRepo.GetAllPackage(ctx, nil, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var datas []dto.PackageResponse
+	for _, pkg := range packages {
 		var companies []dto.CompanyResponse
 		for _, uc := range pkg.User.UserCompanies {
 			companies = append(companies, dto.CompanyResponse{
@@ -749,6 +1642,7 @@ func (as *AdminService) ReadAllPackageWithPagination(ctx context.Context, req dt
 				Address: uc.Company.Address,
 			})
 		}
+
 		data := dto.PackageResponse{
 			ID:           pkg.ID,
 			TrackingCode: pkg.TrackingCode,
@@ -770,6 +1664,9 @@ func (as *AdminService) ReadAllPackageWithPagination(ctx context.Context, req dt
 				LockerCode: pkg.Locker.LockerCode,
 				Location:   pkg.Locker.Location,
 			},
+			Size:    pkg.Size,
+			Slot:    toPackageSlotResponse(packageSlot(pkg)),
+			Company: toPackageCompanyResponse(pkg),
 			User: dto.UserResponse{
 				ID:          pkg.User.ID,
 				Name:        pkg.User.Name,
@@ -792,6 +1689,23 @@ func (as *AdminService) ReadAllPackageWithPagination(ctx context.Context, req dt
 		datas = append(datas, data)
 	}
 
+	return datas, nil
+}
+func (as *AdminService) ReadAllPackageWithPagination(ctx context.Context, req dto.PaginationRequest, filter dto.PackageFilter) (dto.PackagePaginationResponse, error) {
+	if err := filter.Validate(); err != nil {
+		return dto.PackagePaginationResponse{}, err
+	}
+
+	dataWithPaginate, err := as.adminRepo.GetAllPackageWithPagination(ctx, nil, req, filter)
+	if err != nil {
+		return dto.PackagePaginationResponse{}, dto.ErrGetAllPackageWithPagination
+	}
+
+	var datas []dto.PackageResponse
+	for _, pkg := range dataWithPaginate.Packages {
+		datas = append(datas, toPackageListResponse(pkg))
+	}
+
 	return dto.PackagePaginationResponse{
 		Data: datas,
 		PaginationResponse: dto.PaginationResponse{
@@ -802,6 +1716,35 @@ func (as *AdminService) ReadAllPackageWithPagination(ctx context.Context, req dt
 		},
 	}, nil
 }
+func (as *AdminService) ReadAllPackageWithCursor(ctx context.Context, req dto.CursorRequest, filter dto.PackageFilter) (dto.PackageCursorResponse, error) {
+	if err := filter.Validate(); err != nil {
+		return dto.PackageCursorResponse{}, err
+	}
+
+	// keyset pages follow the (created_at, id) order only
+	if len(filter.Sort) > 0 {
+		return dto.PackageCursorResponse{}, dto.ErrCursorSortUnsupported
+	}
+
+	if err := req.Validate(); err != nil {
+		return dto.PackageCursorResponse{}, err
+	}
+
+	dataWithCursor, err := as.adminRepo.GetAllPackageWithCursor(ctx, nil, req, filter)
+	if err != nil {
+		return dto.PackageCursorResponse{}, dto.ErrGetAllPackageWithCursor
+	}
+
+	datas := []dto.PackageResponse{}
+	for _, pkg := range dataWithCursor.Packages {
+		datas = append(datas, toPackageListResponse(pkg))
+	}
+
+	return dto.PackageCursorResponse{
+		Data:           datas,
+		CursorResponse: dataWithCursor.CursorResponse,
+	}, nil
+}
 func (as *AdminService) GetDetailPackage(ctx context.Context, identifier string) (dto.PackageResponse, error) {
 	var pkg entity.Package
 	var err error
@@ -846,6 +1789,9 @@ func (as *AdminService) GetDetailPackage(ctx context.Context, identifier string)
 			LockerCode: pkg.Locker.LockerCode,
 			Location:   pkg.Locker.Location,
 		},
+		Size:    pkg.Size,
+		Slot:    toPackageSlotResponse(packageSlot(pkg)),
+		Company: toPackageCompanyResponse(pkg),
 		User: dto.UserResponse{
 			ID:          pkg.User.ID,
 			Name:        pkg.User.Name,
@@ -874,25 +1820,41 @@ func (as *AdminService) ReadAllPackageHistory(ctx context.Context, pkgID string)
 
 	var datas []dto.PackageHistoryResponse
 	for _, pkgH := range dataWithPaginate {
-		data := dto.PackageHistoryResponse{
-			ID:          pkgH.ID,
-			Status:      pkgH.Status,
-			Description: pkgH.Description,
-			ChangedBy: dto.UserResponseCustom{
-				ID:    pkgH.ChangedByUser.ID,
-				Name:  pkgH.ChangedByUser.Name,
-				Email: pkgH.ChangedByUser.Email,
-			},
-			CreatedAt: pkgH.CreatedAt,
-		}
-
-		datas = append(datas, data)
+		datas = append(datas, toPackageHistoryResponse(pkgH, true))
 	}
 
 	return datas, nil
 }
-func (as *AdminService) UpdatePackage(ctx context.Context, req dto.UpdatePackageRequest) (dto.UpdatePackageResponse, error) {
-	token := ctx.Value("Authorization").(string)
+func (as *AdminService) ReadAllPackageHistoryWithCursor(ctx context.Context, pkgID string, req dto.CursorRequest) (dto.PackageHistoryCursorResponse, error) {
+	if err := req.Validate(); err != nil {
+		return dto.PackageHistoryCursorResponse{}, err
+	}
+
+	dataWithCursor, err := as.adminRepo.GetAllPackageHistoryWithCursor(ctx, nil, pkgID, req)
+	if err != nil {
+		return dto.PackageHistoryCursorResponse{}, dto.ErrGetAllPackageHistoryCursor
+	}
+
+	datas := []dto.PackageHistoryResponse{}
+	for _, pkgH := range dataWithCursor.PackageHistories {
+		datas = append(datas, toPackageHistoryResponse(pkgH, true))
+	}
+
+	return dto.PackageHistoryCursorResponse{
+		Data:           datas,
+		CursorResponse: dataWithCursor.CursorResponse,
+	}, nil
+}
+func (as *AdminService) ReadPackageTimeline(ctx context.Context, pkgID string) ([]dto.PackageTimelineDay, error) {
+	histories, err := as.ReadAllPackageHistory(ctx, pkgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildPackageTimeline(histories), nil
+}
+func (as *AdminService) UpdatePackage(ctx context.Context, req dto.UpdatePackageRequest) (dto.UpdatePackageResponse, error) {
+	token := ctx.Value("Authorization").(string)
 
 	userId, err := as.jwtService.GetUserIDByToken(token)
 	if err != nil {
@@ -916,11 +1878,22 @@ func (as *AdminService) UpdatePackage(ctx context.Context, req dto.UpdatePackage
 
 	now := time.Now()
 	var descriptionChanges []string
+	var changes entity.HistoryChanges
+	// only the edited columns are written, p was read outside the transaction
+	// and must not put back a pickup code regenerated in the meantime
+	updates := map[string]interface{}{}
+	eventType := entity.HistoryUpdated
 
 	if req.TrackingCode != "" {
 		if p.TrackingCode != req.TrackingCode {
+			if _, found, _ := as.adminRepo.GetPackageByTrackingCode(tenant.WithoutScope(ctx), nil, req.TrackingCode); found {
+				return dto.UpdatePackageResponse{}, dto.ErrTrackingCodeExists
+			}
+
 			descriptionChanges = append(descriptionChanges, "package code changed")
+			changes = append(changes, entity.FieldChange{Field: "package_tracking_code", Old: p.TrackingCode, New: req.TrackingCode})
 			p.TrackingCode = req.TrackingCode
+			updates["tracking_code"] = p.TrackingCode
 		}
 	}
 
@@ -931,7 +1904,9 @@ func (as *AdminService) UpdatePackage(ctx context.Context, req dto.UpdatePackage
 
 		if p.Description != req.Description {
 			descriptionChanges = append(descriptionChanges, "package description changed")
+			changes = append(changes, entity.FieldChange{Field: "package_description", Old: p.Description, New: req.Description})
 			p.Description = req.Description
+			updates["description"] = p.Description
 		}
 	}
 
@@ -966,7 +1941,9 @@ func (as *AdminService) UpdatePackage(ctx context.Context, req dto.UpdatePackage
 
 		if p.Image != fileName {
 			descriptionChanges = append(descriptionChanges, "package image changed")
+			changes = append(changes, entity.FieldChange{Field: "package_image", Old: p.Image, New: fileName})
 			p.Image = fileName
+			updates["image"] = p.Image
 		}
 	}
 
@@ -977,7 +1954,9 @@ func (as *AdminService) UpdatePackage(ctx context.Context, req dto.UpdatePackage
 
 		if p.Type != req.Type {
 			descriptionChanges = append(descriptionChanges, "package type changed")
+			changes = append(changes, entity.FieldChange{Field: "package_type", Old: p.Type, New: req.Type})
 			p.Type = req.Type
+			updates["type"] = p.Type
 		}
 	}
 
@@ -986,8 +1965,12 @@ func (as *AdminService) UpdatePackage(ctx context.Context, req dto.UpdatePackage
 			return dto.UpdatePackageResponse{}, dto.ErrInvalidQuantityPackage
 		}
 
-		descriptionChanges = append(descriptionChanges, "quantity changed")
-		p.Quantity = *req.Quantity
+		if p.Quantity != *req.Quantity {
+			descriptionChanges = append(descriptionChanges, "quantity changed")
+			changes = append(changes, entity.FieldChange{Field: "package_quantity", Old: p.Quantity, New: *req.Quantity})
+			p.Quantity = *req.Quantity
+			updates["quantity"] = p.Quantity
+		}
 	}
 
 	if req.SenderID != nil {
@@ -998,22 +1981,22 @@ func (as *AdminService) UpdatePackage(ctx context.Context, req dto.UpdatePackage
 
 		if p.SenderID == nil || *p.SenderID != sender.ID {
 			descriptionChanges = append(descriptionChanges, "sender changed")
+			changes = append(changes, entity.FieldChange{Field: "sender_id", Old: p.SenderID, New: sender.ID})
 			p.SenderID = &sender.ID
+			updates["sender_id"] = p.SenderID
 		}
 	}
 
+	var correctionMessage string
 	if len(descriptionChanges) > 0 {
-		message := fmt.Sprintf(
+		correctionMessage = fmt.Sprintf(
 			"🙏 Mohon maaf, terdapat pembaruan data pada paket Anda dengan kode paket *%s* karena kesalahan input sebelumnya.\n\nPerubahan yang dilakukan:\n- %s\n\nSilakan cek aplikasi untuk melihat detail terbaru. Terima kasih atas pengertiannya.",
 			p.TrackingCode,
 			strings.Join(descriptionChanges, "\n- "),
 		)
-
-		if err := whatsapp.SendTextMessage(p.User.PhoneNumber, message, "", ""); err != nil {
-			log.Println("Failed to send WhatsApp notification:", err)
-		}
 	}
 
+	reassignSlot := false
 	if req.LockerID != nil {
 		locker, found, err := as.adminRepo.GetLockerByID(ctx, nil, req.LockerID.String())
 		if err != nil || !found {
@@ -1022,14 +2005,33 @@ func (as *AdminService) UpdatePackage(ctx context.Context, req dto.UpdatePackage
 
 		if p.LockerID == nil || *p.LockerID != locker.ID {
 			descriptionChanges = append(descriptionChanges, "locker changed")
+			changes = append(changes, entity.FieldChange{Field: "locker_id", Old: p.LockerID, New: locker.ID})
 			p.LockerID = &locker.ID
+			p.Locker = locker
+			updates["locker_id"] = p.LockerID
+			reassignSlot = true
 		}
 	}
 
-	var validStatusOrder = map[entity.Status]entity.Status{
-		entity.Received: entity.Completed,
+	if req.Size != "" {
+		if !entity.IsValidSlotSize(req.Size) {
+			return dto.UpdatePackageResponse{}, dto.ErrInvalidSlotSize
+		}
+
+		if p.Size != req.Size {
+			descriptionChanges = append(descriptionChanges, "package size changed")
+			changes = append(changes, entity.FieldChange{Field: "package_size", Old: p.Size, New: req.Size})
+			p.Size = req.Size
+			updates["size"] = p.Size
+			reassignSlot = true
+		}
 	}
 
+	var (
+		delegation *entity.PickupDelegation
+		collector  *entity.User
+	)
+	previousStatus := p.Status
 	if req.Status != "" {
 		if !entity.IsValidStatus(entity.Status(req.Status)) {
 			return dto.UpdatePackageResponse{}, dto.ErrInvalidPackageStatus
@@ -1040,48 +2042,155 @@ func (as *AdminService) UpdatePackage(ctx context.Context, req dto.UpdatePackage
 		}
 
 		if p.Status != entity.Status(req.Status) {
-			expectedNext, ok := validStatusOrder[p.Status]
-			if !ok || expectedNext != entity.Status(req.Status) {
-				return dto.UpdatePackageResponse{}, dto.ErrInvalidStatusTransition
+			if err := entity.ValidateStatusTransition(p.Status, entity.Status(req.Status)); err != nil {
+				return dto.UpdatePackageResponse{}, err
 			}
 
-			descriptionChanges = append(descriptionChanges, "package status changed")
+			switch entity.Status(req.Status) {
+			case entity.Completed:
+				delegation, err = as.resolveDelegation(ctx, nil, req.DelegationID, p, now)
+				if err != nil {
+					return dto.UpdatePackageResponse{}, err
+				}
+
+				collector, err = as.resolveCollector(ctx, nil, req.CollectorID, p)
+				if err != nil {
+					return dto.UpdatePackageResponse{}, err
+				}
 
-			if entity.Status(req.Status) == entity.Completed {
+				descriptionChanges = append(descriptionChanges, "package status changed, pickup code verified")
+				if delegation != nil {
+					descriptionChanges = append(descriptionChanges, fmt.Sprintf("taken by %s on behalf of the owner", delegation.Name))
+				} else if collector != nil {
+					descriptionChanges = append(descriptionChanges, fmt.Sprintf("taken by %s for the company", collector.Name))
+				}
+				eventType = entity.HistoryCompleted
 				p.CompletedAt = &now
+				updates["completed_at"] = p.CompletedAt
+			case entity.Received:
+				// appeal: the package gets a fresh storage period
+				descriptionChanges = append(descriptionChanges, "package received on appeal")
+				policy, err := as.adminRepo.ResolveRetentionPolicy(ctx, nil, p.UserID, p.CompanyID, p.Type)
+				if err != nil {
+					return dto.UpdatePackageResponse{}, dto.ErrGetRetentionPolicy
+				}
+				expiredAt := now.AddDate(0, 0, policy.ExpiryDays)
+				changes = append(changes, entity.FieldChange{Field: "package_expired_at", Old: p.ExpiredAt, New: expiredAt})
+				eventType = entity.HistoryAppealed
+				p.ExpiredAt = &expiredAt
+				p.LastReminderSentAt = nil
+				updates["expired_at"] = p.ExpiredAt
+				updates["last_reminder_sent_at"] = nil
+			case entity.Deleted:
+				descriptionChanges = append(descriptionChanges, "package deleted after expiration")
+				eventType = entity.HistoryDeleted
+				p.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+				updates["deleted_at"] = p.DeletedAt
 			}
 
+			changes = append(changes, entity.FieldChange{Field: "package_status", Old: p.Status, New: req.Status})
 			p.Status = entity.Status(req.Status)
+			updates["status"] = p.Status
 		}
 	}
 
-	if err := as.adminRepo.UpdatePackage(ctx, nil, p); err != nil {
-		return dto.UpdatePackageResponse{}, dto.ErrUpdatePackage
-	}
-
-	message := utils.BuildCompletedMessage(&p)
-	if err := whatsapp.SendTextMessage(p.User.PhoneNumber, message, "", ""); err != nil {
-		log.Println("Failed to send WhatsApp notification:", err)
-	}
-
 	descriptionPkgH := strings.Join(descriptionChanges, ", ")
 
-	history := entity.PackageHistory{
+	history := as.withActor(ctx, entity.PackageHistory{
 		ID:          uuid.New(),
+		EventType:   eventType,
 		Status:      p.Status,
 		Description: descriptionPkgH,
+		Changes:     changes,
 		PackageID:   &p.ID,
 		ChangedBy:   &IDChanger,
-	}
+	})
+	if delegation != nil {
+		history.CollectedBy = delegation.Name
+		history.DelegationID = &delegation.ID
+	} else if collector != nil {
+		history.CollectedBy = collector.Name
+	}
+
+	var rejected *entity.Package
+	err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		if previousStatus != entity.Completed && p.Status == entity.Completed {
+			locked, err := as.verifyPickupCode(ctx, tx, p.ID, req.PickupCode)
+			if errors.Is(err, dto.ErrInvalidPickupCode) {
+				rejected = &locked
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := as.adminRepo.UpdatePackage(ctx, tx, p.ID, updates); err != nil {
+			var transitionErr *entity.StatusTransitionError
+			if errors.As(err, &transitionErr) {
+				return transitionErr
+			}
+
+			return dto.ErrUpdatePackage
+		}
+
+		if reassignSlot && p.LockerID != nil && isOccupyingStatus(p.Status) {
+			slot, err := as.assignSlot(ctx, tx, *p.LockerID, p.Size, &p.ID)
+			if err != nil {
+				return err
+			}
+
+			previousSlotID := p.SlotID
+			p.SlotID, p.Slot = nil, entity.LockerSlot{}
+			if slot != nil {
+				p.SlotID, p.Slot = &slot.ID, *slot
+			}
+			history.Changes = append(history.Changes, entity.FieldChange{Field: "slot_id", Old: previousSlotID, New: p.SlotID})
+			if err := as.adminRepo.UpdatePackageSlot(ctx, tx, p.ID, p.SlotID); err != nil {
+				return dto.ErrAssignLockerSlot
+			}
+		}
+
+		if err := as.adminRepo.CreatePackageHistory(ctx, tx, history); err != nil {
+			return dto.ErrCreatePackageHistory
+		}
+
+		if correctionMessage != "" {
+			if err := as.notifyPackage(ctx, tx, p, "Package data updated", correctionMessage, ""); err != nil {
+				return err
+			}
+		}
+
+		if previousStatus != entity.Completed && p.Status == entity.Completed {
+			// the code is single use
+			if err := as.adminRepo.UpdatePickupCode(ctx, tx, p.ID, "", 0); err != nil {
+				return dto.ErrUpdatePickupCode
+			}
+
+			message := utils.BuildCompletedMessage(&p, delegation)
+			if p.CompanyID != nil {
+				message = utils.BuildCompanyCompletedMessage(&p, history.CollectedBy)
+			}
+			if err := as.notifyPackage(ctx, tx, p, "Package picked up", message, ""); err != nil {
+				return err
+			}
+		}
 
-	if err := as.adminRepo.CreatePackageHistory(ctx, nil, history); err != nil {
-		return dto.UpdatePackageResponse{}, dto.ErrCreatePackageHistory
+		return nil
+	})
+	if rejected != nil {
+		return dto.UpdatePackageResponse{}, as.recordRejectedPickupCode(ctx, *rejected, IDChanger)
+	}
+	if err != nil {
+		return dto.UpdatePackageResponse{}, err
 	}
 
-	client := dto.UserResponseCustom{
-		ID:    *p.UserID,
-		Name:  p.User.Name,
-		Email: p.User.Email,
+	var client dto.UserResponseCustom
+	if p.UserID != nil {
+		client = dto.UserResponseCustom{
+			ID:    *p.UserID,
+			Name:  p.User.Name,
+			Email: p.User.Email,
+		}
 	}
 
 	admin := dto.UserResponseCustom{
@@ -1113,6 +2222,9 @@ func (as *AdminService) UpdatePackage(ctx context.Context, req dto.UpdatePackage
 			Location:   p.Locker.Location,
 		},
 		ChangedBy: admin,
+		Size:      p.Size,
+		Slot:      toPackageSlotResponse(packageSlot(p)),
+		Company:   toPackageCompanyResponse(p),
 		TimeStamp: entity.TimeStamp{
 			CreatedAt: p.CreatedAt,
 			UpdatedAt: p.UpdatedAt,
@@ -1159,55 +2271,421 @@ func (as *AdminService) UpdateStatusPackages(ctx context.Context, req dto.Update
 		proofImagePath = fileName
 	}
 
+	if len(req.PickupCodes) != len(req.PackageIDs) {
+		return dto.ErrPickupCodeCountInvalid
+	}
+
+	delegations := make(map[uuid.UUID]*entity.PickupDelegation)
+	collectors := make(map[uuid.UUID]*entity.User)
 	for _, pkgID := range req.PackageIDs {
 		p, _, err := as.adminRepo.GetPackageByID(ctx, nil, pkgID.String())
 		if err != nil {
 			return dto.ErrPackageNotFound
 		}
 
-		err = as.adminRepo.UpdateStatusPackage(
-			ctx, nil,
-			pkgID.String(),
-			string(entity.Completed),
-			proofImagePath,
-		)
+		delegation, err := as.resolveDelegation(ctx, nil, req.DelegationID, p, now)
+		if err != nil {
+			return err
+		}
+		delegations[pkgID] = delegation
+
+		collector, err := as.resolveCollector(ctx, nil, req.CollectorID, p)
 		if err != nil {
-			return dto.ErrUpdateStatusPackage
+			return err
+		}
+		collectors[pkgID] = collector
+	}
+
+	// the whole batch is one pickup, so either every package is completed or
+	// none is, a rejected code is counted after the rollback
+	var rejected *entity.Package
+	err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		for i, pkgID := range req.PackageIDs {
+			locked, err := as.verifyPickupCode(ctx, tx, pkgID, req.PickupCodes[i])
+			if errors.Is(err, dto.ErrInvalidPickupCode) {
+				rejected = &locked
+			}
+			if err != nil {
+				return err
+			}
+
+			p, _, err := as.adminRepo.GetPackageByID(ctx, tx, pkgID.String())
+			if err != nil {
+				return dto.ErrPackageNotFound
+			}
+
+			if err := entity.ValidateStatusTransition(p.Status, entity.Completed); err != nil {
+				return err
+			}
+
+			err = as.adminRepo.UpdateStatusPackage(
+				ctx, tx,
+				pkgID,
+				entity.Completed,
+				proofImagePath,
+				&now,
+			)
+			if err != nil {
+				var transitionErr *entity.StatusTransitionError
+				if errors.As(err, &transitionErr) {
+					return transitionErr
+				}
+
+				return dto.ErrUpdateStatusPackage
+			}
+
+			if err := as.adminRepo.UpdatePickupCode(ctx, tx, pkgID, "", 0); err != nil {
+				return dto.ErrUpdatePickupCode
+			}
+
+			previousStatus := p.Status
+			p.Status = entity.Completed
+			p.CompletedAt = &now
+
+			history := as.withActor(ctx, entity.PackageHistory{
+				ID:          uuid.New(),
+				EventType:   entity.HistoryCompleted,
+				Status:      entity.Completed,
+				Description: "package status changed, taken by owner, pickup code verified",
+				Changes: entity.HistoryChanges{
+					{Field: "package_status", Old: previousStatus, New: entity.Completed},
+					{Field: "package_proof_image", Old: p.ProofImage, New: proofImagePath},
+				},
+				PackageID: &pkgID,
+				ChangedBy: &idChanger,
+			})
+
+			delegation := delegations[pkgID]
+			if delegation != nil {
+				history.Description = fmt.Sprintf("package status changed, taken by %s on behalf of the owner, pickup code verified", delegation.Name)
+				history.CollectedBy = delegation.Name
+				history.DelegationID = &delegation.ID
+			} else if collector := collectors[pkgID]; collector != nil {
+				history.Description = fmt.Sprintf("package status changed, taken by %s for the company, pickup code verified", collector.Name)
+				history.CollectedBy = collector.Name
+			}
+			if err := as.adminRepo.CreatePackageHistory(ctx, tx, history); err != nil {
+				return dto.ErrCreatePackageHistory
+			}
+
+			message := utils.BuildCompletedMessage(&p, delegation)
+			if p.CompanyID != nil {
+				message = utils.BuildCompanyCompletedMessage(&p, history.CollectedBy)
+			}
+			if message == "" {
+				continue
+			}
+
+			if err := as.notifyPackage(ctx, tx, p, "Package picked up", message, ""); err != nil {
+				return err
+			}
 		}
 
-		p.CompletedAt = &now
+		return nil
+	})
+	if rejected != nil {
+		return as.recordRejectedPickupCode(ctx, *rejected, idChanger)
+	}
+
+	return err
+}
 
-		message := utils.BuildCompletedMessage(&p)
-		if message != "" {
-			if err := whatsapp.SendTextMessage(p.User.PhoneNumber, message, "", ""); err != nil {
-				log.Println("Failed to send WhatsApp notification:", err)
+// withActor fills the actor metadata of a history entry from the request
+// context. Entries written without a logged in user are attributed to the system.
+func (as *AdminService) withActor(ctx context.Context, history entity.PackageHistory) entity.PackageHistory {
+	history.ActorRole = entity.SystemActor
+	if token, ok := ctx.Value("Authorization").(string); ok && token != "" {
+		history.ActorRole = ""
+		if roleID, err := as.jwtService.GetRoleIDByToken(token); err == nil {
+			if role, err := as.adminRepo.GetRoleByID(ctx, nil, roleID); err == nil {
+				history.ActorRole = role.Name
 			}
 		}
+	}
 
-		history := entity.PackageHistory{
+	history.IPAddress, history.UserAgent = requestMeta(ctx)
+
+	return history
+}
+
+// resolveDelegation loads the delegation an admin picked for a pickup and makes
+// sure it belongs to the package owner, covers the package and is active now.
+func (as *AdminService) resolveDelegation(ctx context.Context, tx *gorm.DB, delegationID *uuid.UUID, pkg entity.Package, now time.Time) (*entity.PickupDelegation, error) {
+	if delegationID == nil {
+		return nil, nil
+	}
+
+	delegation, flag, err := as.adminRepo.GetPickupDelegationByID(ctx, tx, delegationID.String())
+	if err != nil || !flag {
+		return nil, dto.ErrPickupDelegationNotFound
+	}
+
+	if delegation.UserID == nil || !delegation.IsActive(now) || !delegation.Covers(pkg.ID) {
+		return nil, dto.ErrDelegationNotValidForPackage
+	}
+
+	// any member may delegate the pickup of a company package
+	owned := pkg.UserID != nil && *delegation.UserID == *pkg.UserID
+	if !owned && pkg.CompanyID != nil {
+		owned, err = as.adminRepo.IsCompanyMember(ctx, tx, *pkg.CompanyID, *delegation.UserID)
+		if err != nil {
+			return nil, dto.ErrGetCompanyMembers
+		}
+	}
+	if !owned {
+		return nil, dto.ErrDelegationNotValidForPackage
+	}
+
+	return &delegation, nil
+}
+
+// resolveCollector loads the member an admin picked as the one collecting a
+// company package in person.
+func (as *AdminService) resolveCollector(ctx context.Context, tx *gorm.DB, collectorID *uuid.UUID, pkg entity.Package) (*entity.User, error) {
+	if collectorID == nil {
+		return nil, nil
+	}
+
+	if pkg.CompanyID == nil {
+		return nil, dto.ErrCollectorNotCompanyMember
+	}
+
+	member, err := as.adminRepo.IsCompanyMember(ctx, tx, *pkg.CompanyID, *collectorID)
+	if err != nil || !member {
+		return nil, dto.ErrCollectorNotCompanyMember
+	}
+
+	collector, found, err := as.adminRepo.GetUserByID(ctx, tx, collectorID.String())
+	if err != nil || !found {
+		return nil, dto.ErrUserNotFound
+	}
+
+	return &collector, nil
+}
+
+func (as *AdminService) ReadAllActivePickupDelegation(ctx context.Context, pkgID string) ([]dto.PickupDelegationResponse, error) {
+	pkg, flag, err := as.adminRepo.GetPackageByID(ctx, nil, pkgID)
+	if err != nil || !flag {
+		return nil, dto.ErrPackageNotFound
+	}
+
+	now := time.Now()
+	delegations, err := as.adminRepo.GetAllActivePickupDelegationByPackage(ctx, nil, pkg, now)
+	if err != nil {
+		return nil, dto.ErrGetAllPickupDelegation
+	}
+
+	var datas []dto.PickupDelegationResponse
+	for _, delegation := range delegations {
+		datas = append(datas, toPickupDelegationResponse(delegation, now))
+	}
+
+	return datas, nil
+}
+
+// checkPickupCode compares code with the owner's one-time code. Packages
+// received before pickup codes existed have no code.
+func checkPickupCode(pkg entity.Package, code string) error {
+	if pkg.PickupCode == "" {
+		return nil
+	}
+
+	if pkg.PickupAttempts >= pickupCodeMaxAttempts {
+		return dto.ErrPickupCodeLocked
+	}
+
+	if code == "" {
+		return dto.ErrPickupCodeRequired
+	}
+
+	if ok, _ := helpers.CheckPassword(pkg.PickupCode, []byte(code)); !ok {
+		return dto.ErrInvalidPickupCode
+	}
+
+	return nil
+}
+
+// pickupCodeRejectedError tells whether a rejected code has used up the
+// last attempt.
+func pickupCodeRejectedError(attempts int) error {
+	if attempts >= pickupCodeMaxAttempts {
+		return dto.ErrPickupCodeLocked
+	}
+
+	return dto.ErrInvalidPickupCode
+}
+
+// verifyPickupCode locks the package row in the completing transaction and
+// checks its code, so concurrent pickups can not both spend the same code.
+// The locked package is returned even when the code is rejected.
+func (as *AdminService) verifyPickupCode(ctx context.Context, tx *gorm.DB, pkgID uuid.UUID, code string) (entity.Package, error) {
+	pkg, found, err := as.adminRepo.LockPackageByID(ctx, tx, pkgID.String())
+	if err != nil || !found {
+		return entity.Package{}, dto.ErrPackageNotFound
+	}
+
+	return pkg, checkPickupCode(pkg, code)
+}
+
+// recordRejectedPickupCode counts a rejected code and writes it to the
+// package history once the completing transaction has rolled back, after
+// pickupCodeMaxAttempts the code stays locked until it is regenerated.
+// Nothing is counted when the code was regenerated in the meantime.
+func (as *AdminService) recordRejectedPickupCode(ctx context.Context, rejected entity.Package, changedBy uuid.UUID) error {
+	var attempts int
+	err := as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		pkg, found, err := as.adminRepo.LockPackageByID(ctx, tx, rejected.ID.String())
+		if err != nil || !found {
+			return dto.ErrPackageNotFound
+		}
+
+		if pkg.PickupCode != rejected.PickupCode {
+			return nil
+		}
+
+		attempts = pkg.PickupAttempts + 1
+		history := as.withActor(ctx, entity.PackageHistory{
 			ID:          uuid.New(),
-			Status:      entity.Completed,
-			Description: fmt.Sprintf("package status changed, taken by %s", "user"),
-			PackageID:   &pkgID,
-			ChangedBy:   &idChanger,
+			EventType:   entity.HistoryPickupCodeRejected,
+			Status:      pkg.Status,
+			Description: fmt.Sprintf("pickup code rejected, attempt %d of %d", attempts, pickupCodeMaxAttempts),
+			Changes:     entity.HistoryChanges{{Field: "package_pickup_attempts", Old: pkg.PickupAttempts, New: attempts}},
+			PackageID:   &pkg.ID,
+			ChangedBy:   &changedBy,
+		})
+
+		if err := as.adminRepo.UpdatePickupCode(ctx, tx, pkg.ID, pkg.PickupCode, attempts); err != nil {
+			return dto.ErrUpdatePickupCode
 		}
-		if err := as.adminRepo.CreatePackageHistory(ctx, nil, history); err != nil {
+
+		if err := as.adminRepo.CreatePackageHistory(ctx, tx, history); err != nil {
 			return dto.ErrCreatePackageHistory
 		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return pickupCodeRejectedError(attempts)
+}
+func (as *AdminService) RegeneratePickupCode(ctx context.Context, pkgID string) (dto.PickupCodeResponse, error) {
+	token := ctx.Value("Authorization").(string)
+
+	userId, err := as.jwtService.GetUserIDByToken(token)
+	if err != nil {
+		return dto.PickupCodeResponse{}, dto.ErrGetUserIDFromToken
+	}
+
+	IDChanger, err := uuid.Parse(userId)
+	if err != nil {
+		return dto.PickupCodeResponse{}, dto.ErrParseUUID
+	}
+
+	pkg, flag, err := as.adminRepo.GetPackageByID(ctx, nil, pkgID)
+	if err != nil || !flag {
+		return dto.PickupCodeResponse{}, dto.ErrPackageNotFound
+	}
+
+	if !isOccupyingStatus(pkg.Status) {
+		return dto.PickupCodeResponse{}, dto.ErrPickupCodeNotAvailable
+	}
+
+	pickupCode, err := helpers.GeneratePickupCode()
+	if err != nil {
+		return dto.PickupCodeResponse{}, dto.ErrGeneratePickupCode
+	}
+
+	hash, err := helpers.HashPassword(pickupCode)
+	if err != nil {
+		return dto.PickupCodeResponse{}, dto.ErrGeneratePickupCode
+	}
+
+	history := as.withActor(ctx, entity.PackageHistory{
+		ID:          uuid.New(),
+		EventType:   entity.HistoryPickupCodeRegenerated,
+		Status:      pkg.Status,
+		Description: "pickup code regenerated",
+		Changes:     entity.HistoryChanges{{Field: "package_pickup_attempts", Old: pkg.PickupAttempts, New: 0}},
+		PackageID:   &pkg.ID,
+		ChangedBy:   &IDChanger,
+	})
+
+	err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		if err := as.adminRepo.UpdatePickupCode(ctx, tx, pkg.ID, hash, 0); err != nil {
+			return dto.ErrUpdatePickupCode
+		}
+
+		if err := as.adminRepo.CreatePackageHistory(ctx, tx, history); err != nil {
+			return dto.ErrCreatePackageHistory
+		}
+
+		message := utils.BuildPickupCodeMessage(&pkg, pickupCode)
+		return as.notifyPackage(ctx, tx, pkg, "New pickup code", message, "")
+	})
+	if err != nil {
+		return dto.PickupCodeResponse{}, err
+	}
+
+	return dto.PickupCodeResponse{
+		PackageID:      pkg.ID,
+		TrackingCode:   pkg.TrackingCode,
+		PickupAttempts: 0,
+	}, nil
 }
 func (as *AdminService) DeletePackage(ctx context.Context, req dto.DeletePackageRequest) (dto.PackageResponse, error) {
+	token := ctx.Value("Authorization").(string)
+
+	userId, err := as.jwtService.GetUserIDByToken(token)
+	if err != nil {
+		return dto.PackageResponse{}, dto.ErrGetUserIDFromToken
+	}
+
+	IDChanger, err := uuid.Parse(userId)
+	if err != nil {
+		return dto.PackageResponse{}, dto.ErrParseUUID
+	}
+
 	deletedPackage, _, err := as.adminRepo.GetPackageByID(ctx, nil, req.PackageID)
 	if err != nil {
 		return dto.PackageResponse{}, dto.ErrPackageNotFound
 	}
 
-	err = as.adminRepo.DeletePackageByID(ctx, nil, req.PackageID)
+	if err := entity.ValidateStatusTransition(deletedPackage.Status, entity.Deleted); err != nil {
+		return dto.PackageResponse{}, err
+	}
+
+	history := as.withActor(ctx, entity.PackageHistory{
+		ID:          uuid.New(),
+		EventType:   entity.HistoryDeleted,
+		Status:      entity.Deleted,
+		Description: "package deleted",
+		Changes:     entity.HistoryChanges{{Field: "package_status", Old: deletedPackage.Status, New: entity.Deleted}},
+		PackageID:   &deletedPackage.ID,
+		ChangedBy:   &IDChanger,
+	})
+
+	err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		if err := as.adminRepo.CreatePackageHistory(ctx, tx, history); err != nil {
+			return dto.ErrCreatePackageHistory
+		}
+
+		if err := as.adminRepo.UpdateSoftDeletePackage(tx, deletedPackage.ID, time.Now()); err != nil {
+			var transitionErr *entity.StatusTransitionError
+			if errors.As(err, &transitionErr) {
+				return transitionErr
+			}
+
+			return dto.ErrDeletePackage
+		}
+
+		return nil
+	})
 	if err != nil {
-		return dto.PackageResponse{}, dto.ErrDeleteUserByID
+		return dto.PackageResponse{}, err
 	}
+	deletedPackage.Status = entity.Deleted
 
 	var companies []dto.CompanyResponse
 	for _, uc := range deletedPackage.User.UserCompanies {
@@ -1239,6 +2717,9 @@ func (as *AdminService) DeletePackage(ctx context.Context, req dto.DeletePackage
 			LockerCode: deletedPackage.Locker.LockerCode,
 			Location:   deletedPackage.Locker.Location,
 		},
+		Size:    deletedPackage.Size,
+		Slot:    toPackageSlotResponse(packageSlot(deletedPackage)),
+		Company: toPackageCompanyResponse(deletedPackage),
 		User: dto.UserResponse{
 			ID:          deletedPackage.User.ID,
 			Name:        deletedPackage.User.Name,
@@ -1263,12 +2744,28 @@ func (as *AdminService) DeletePackage(ctx context.Context, req dto.DeletePackage
 }
 
 // Cron
-func sameDay(t1, t2 time.Time) bool {
-	y1, m1, d1 := t1.Date()
-	y2, m2, d2 := t2.Date()
-	return y1 == y2 && m1 == m2 && d1 == d2
+// dueReminder returns the most recent reminder that is already due for a
+// package expiring at expiredAt, and whether it is the last one before expiry.
+func dueReminder(policy entity.RetentionPolicy, expiredAt, now time.Time) (time.Time, int, bool) {
+	var (
+		dueAt  time.Time
+		dueDay int
+	)
+
+	days := policy.GetReminderDays()
+	for _, day := range days {
+		remindAt := expiredAt.AddDate(0, 0, -day)
+		if remindAt.After(now) {
+			continue
+		}
+
+		dueAt, dueDay = remindAt, day
+	}
+
+	return dueAt, dueDay, len(days) > 0 && dueDay == days[len(days)-1]
 }
 func (as *AdminService) MonthlyReminderPackages() error {
+	ctx := context.Background()
 	now := time.Now()
 
 	packages, err := as.adminRepo.GetAllUnclaimedPackages()
@@ -1277,126 +2774,177 @@ func (as *AdminService) MonthlyReminderPackages() error {
 	}
 
 	for _, pkg := range packages {
+		policy, err := as.adminRepo.ResolveRetentionPolicy(ctx, nil, pkg.UserID, pkg.CompanyID, pkg.Type)
+		if err != nil {
+			log.Printf("Failed to resolve retention policy for package %s: %v", pkg.ID, err)
+			continue
+		}
+
 		receivedAt := pkg.CreatedAt
+		expiredAt := receivedAt.AddDate(0, 0, policy.ExpiryDays)
+		if pkg.ExpiredAt != nil {
+			expiredAt = *pkg.ExpiredAt
+		}
+
 		// Cek expired duluan
-		if now.After(receivedAt.AddDate(0, 3, 0)) {
-			err := as.adminRepo.UpdatePackageStatusToExpired(pkg.ID, entity.Expired, &now)
-			if err != nil {
-				log.Printf("Failed to expire package %d: %v", pkg.ID, err)
-			} else {
-				log.Printf("Package %d expired after 3 months", pkg.ID)
-			}
-
-			err = as.adminRepo.UpdateLastReminderSentAt(pkg.ID.String(), &now)
-			if err != nil {
-				log.Printf("Gagal update reminder_sent_at untuk package %d: %v", pkg.ID, err)
-			}
-
-			msg := fmt.Sprintf("Paket Anda yang diterima pada %s telah melewati batas penyimpanan selama 3 bulan dan dinyatakan *kadaluarsa*. Mulai hari ini, paket tersebut *bukan lagi menjadi tanggung jawab kami*. Terima kasih atas pengertiannya.",
-				receivedAt.Format("02 Jan 2006"))
-			err = whatsapp.SendTextMessage(pkg.User.PhoneNumber, msg, "", "")
-			if err != nil {
-				log.Printf("Gagal kirim reminder ke %s: %v", pkg.User.PhoneNumber, err)
-				continue
+		if now.After(expiredAt) {
+			if err := entity.ValidateStatusTransition(pkg.Status, entity.Expired); err != nil {
+				log.Printf("Failed to expire package %s: %v", pkg.ID, err)
+				continue
 			}
 
 			history := entity.PackageHistory{
 				ID:          uuid.New(),
+				EventType:   entity.HistoryExpired,
 				Status:      entity.Expired,
 				Description: "package expired automatically",
+				Changes:     entity.HistoryChanges{{Field: "package_status", Old: pkg.Status, New: entity.Expired}},
 				PackageID:   &pkg.ID,
 				ChangedBy:   nil,
+				ActorRole:   entity.SystemActor,
 				TimeStamp: entity.TimeStamp{
 					CreatedAt: now,
 					UpdatedAt: now,
 				},
 			}
-			_ = as.adminRepo.CreatePackageHistory(nil, nil, history)
-			continue
-		}
 
-		// Reminder kalau belum expired
-		monthsPassed := int(now.Sub(receivedAt).Hours() / 24 / 30)
-		if monthsPassed >= 1 && monthsPassed <= 2 &&
-			now.Day() == receivedAt.Day() &&
-			(pkg.LastReminderSentAt == nil || !sameDay(*pkg.LastReminderSentAt, now)) {
-
-			if monthsPassed == 2 {
-				msg := fmt.Sprintf(
-					"Pemberitahuan: Paket Anda yang diterima pada %s hingga saat ini belum diambil. Kami mohon agar paket tersebut dapat segera diambil selambat-lambatnya tanggal %s. Setelah tanggal tersebut, kami tidak lagi bertanggung jawab atas keberadaan paket tersebut.",
-					receivedAt.Format("02 Jan 2006"),
-					now.AddDate(0, 1, 0).Format("02 Jan 2006"),
-				)
-				err := whatsapp.SendTextMessage(pkg.User.PhoneNumber, msg, "", "")
-				if err != nil {
-					log.Printf("Gagal kirim reminder ke %s: %v", pkg.User.PhoneNumber, err)
-					continue
+			msg := fmt.Sprintf("Paket Anda yang diterima pada %s telah melewati batas penyimpanan pada %s dan dinyatakan *kadaluarsa*. Mulai hari ini, paket tersebut *bukan lagi menjadi tanggung jawab kami*. Terima kasih atas pengertiannya.",
+				receivedAt.Format("02 Jan 2006"),
+				expiredAt.Format("02 Jan 2006"))
+
+			err := as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+				if err := as.adminRepo.UpdatePackageStatusToExpired(tx, pkg.ID, entity.Expired, &now); err != nil {
+					return err
 				}
-			} else {
-				msg := fmt.Sprintf(
-					"Pemberitahuan: Paket Anda yang diterima pada tanggal %s hingga saat ini belum diambil. Mohon segera diambil.",
-					receivedAt.Format("02 Jan 2006"),
-				)
-				err := whatsapp.SendTextMessage(pkg.User.PhoneNumber, msg, "", "")
-				if err != nil {
-					log.Printf("Gagal kirim reminder ke %s: %v", pkg.User.PhoneNumber, err)
-					continue
+
+				if err := as.adminRepo.UpdateLastReminderSentAt(tx, pkg.ID.String(), &now); err != nil {
+					return err
 				}
-			}
 
-			err = as.adminRepo.UpdateLastReminderSentAt(pkg.ID.String(), &now)
+				if err := as.adminRepo.CreatePackageHistory(ctx, tx, history); err != nil {
+					return err
+				}
+
+				return as.notifyPackage(ctx, tx, *pkg, "Package expired", msg, "")
+			})
 			if err != nil {
-				log.Printf("Gagal update reminder_sent_at untuk package %d: %v", pkg.ID, err)
+				log.Printf("Failed to expire package %s: %v", pkg.ID, err)
+				continue
 			}
+			log.Printf("Package %s expired after its retention period", pkg.ID)
+			continue
+		}
 
-			desc := fmt.Sprintf("package alert - %d", monthsPassed)
-			history := entity.PackageHistory{
-				ID:          uuid.New(),
-				Status:      entity.Received,
-				Description: desc,
-				PackageID:   &pkg.ID,
-				ChangedBy:   nil,
-				TimeStamp: entity.TimeStamp{
-					CreatedAt: now,
-					UpdatedAt: now,
-				},
+		// Reminder kalau belum expired
+		dueAt, daysLeft, last := dueReminder(policy, expiredAt, now)
+		if daysLeft == 0 || (pkg.LastReminderSentAt != nil && !pkg.LastReminderSentAt.Before(dueAt)) {
+			continue
+		}
+
+		var msg string
+		if last {
+			msg = fmt.Sprintf(
+				"Pemberitahuan: Paket Anda yang diterima pada %s hingga saat ini belum diambil. Kami mohon agar paket tersebut dapat segera diambil selambat-lambatnya tanggal %s. Setelah tanggal tersebut, kami tidak lagi bertanggung jawab atas keberadaan paket tersebut.",
+				receivedAt.Format("02 Jan 2006"),
+				expiredAt.Format("02 Jan 2006"),
+			)
+		} else {
+			msg = fmt.Sprintf(
+				"Pemberitahuan: Paket Anda yang diterima pada tanggal %s hingga saat ini belum diambil. Mohon segera diambil.",
+				receivedAt.Format("02 Jan 2006"),
+			)
+		}
+
+		desc := fmt.Sprintf("package alert - %d days before expiry", daysLeft)
+		history := entity.PackageHistory{
+			ID:          uuid.New(),
+			EventType:   entity.HistoryReminderSent,
+			Status:      entity.Received,
+			Description: desc,
+			PackageID:   &pkg.ID,
+			ChangedBy:   nil,
+			ActorRole:   entity.SystemActor,
+			TimeStamp: entity.TimeStamp{
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+		}
+
+		err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+			if err := as.adminRepo.UpdateLastReminderSentAt(tx, pkg.ID.String(), &now); err != nil {
+				return err
+			}
+
+			if err := as.adminRepo.CreatePackageHistory(ctx, tx, history); err != nil {
+				return err
 			}
-			_ = as.adminRepo.CreatePackageHistory(nil, nil, history)
+
+			return as.notifyPackage(ctx, tx, *pkg, "Package pickup reminder", msg, "")
+		})
+		if err != nil {
+			log.Printf("Gagal kirim reminder ke %s: %v", pkg.User.PhoneNumber, err)
 		}
 	}
 
 	return nil
 }
 func (as *AdminService) AutoSoftDeletePackages() error {
+	ctx := context.Background()
 	now := time.Now()
-	cutoff := now.AddDate(0, 0, -14)
 
 	var expiredPackages []entity.Package
-	err := as.adminRepo.GetAllExpiredPackagesBefore(cutoff, &expiredPackages)
+	err := as.adminRepo.GetAllExpiredPackages(&expiredPackages)
 	if err != nil {
 		return err
 	}
 
 	for _, pkg := range expiredPackages {
-		err := as.adminRepo.UpdateSoftDeletePackage(pkg.ID, now)
+		policy, err := as.adminRepo.ResolveRetentionPolicy(ctx, nil, pkg.UserID, pkg.CompanyID, pkg.Type)
 		if err != nil {
+			log.Printf("[AutoDelete] failed to resolve retention policy for package %s: %v", pkg.ID, err)
+			continue
+		}
+
+		expiredAt := pkg.UpdatedAt
+		if pkg.ExpiredAt != nil {
+			expiredAt = *pkg.ExpiredAt
+		}
+		if now.Before(expiredAt.AddDate(0, 0, policy.GracePeriodDays)) {
+			continue
+		}
+
+		if err := entity.ValidateStatusTransition(pkg.Status, entity.Deleted); err != nil {
 			log.Printf("[AutoDelete] failed to update package %s: %v", pkg.ID, err)
 			continue
 		}
-		log.Printf("[AutoDelete] success to delete package %s: %v", pkg.ID, err)
 
 		history := entity.PackageHistory{
 			ID:          uuid.New(),
+			EventType:   entity.HistoryDeleted,
 			Status:      entity.Deleted,
-			Description: "package auto soft-deleted after 14 days of expiration",
+			Description: fmt.Sprintf("package auto soft-deleted after %d days of expiration", policy.GracePeriodDays),
+			Changes:     entity.HistoryChanges{{Field: "package_status", Old: pkg.Status, New: entity.Deleted}},
 			PackageID:   &pkg.ID,
 			ChangedBy:   nil,
+			ActorRole:   entity.SystemActor,
 			TimeStamp: entity.TimeStamp{
 				CreatedAt: now,
 				UpdatedAt: now,
 			},
 		}
-		_ = as.adminRepo.CreatePackageHistory(nil, nil, history)
+
+		err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+			if err := as.adminRepo.UpdateSoftDeletePackage(tx, pkg.ID, now); err != nil {
+				return err
+			}
+
+			return as.adminRepo.CreatePackageHistory(ctx, tx, history)
+		})
+		if err != nil {
+			log.Printf("[AutoDelete] failed to update package %s: %v", pkg.ID, err)
+			continue
+		}
+		log.Printf("[AutoDelete] success to delete package %s", pkg.ID)
 	}
 
 	return nil
@@ -1422,6 +2970,101 @@ func (as *AdminService) LogError(jobName, message string) {
 	_ = as.adminRepo.CreateLog(nil, cron)
 }
 
+// notificationBackoff doubles the wait after every failed attempt.
+func notificationBackoff(attempts int) time.Duration {
+	backoff := notificationBaseBackoff
+	for i := 1; i < attempts && backoff < notificationMaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, notificationMaxBackoff)
+}
+
+// applyDeliveryResult records the outcome of one send on a claimed row,
+// failures are retried with backoff until the attempts run out. The body of
+// a sent message is dropped, it may carry a pickup code.
+func applyDeliveryResult(outbox *entity.NotificationOutbox, sendErr error, now time.Time) {
+	outbox.LeaseUntil = nil
+
+	if sendErr == nil {
+		outbox.Status = entity.NotificationSent
+		outbox.Body = ""
+		outbox.LastError = ""
+		outbox.SentAt = &now
+		return
+	}
+
+	outbox.LastError = sendErr.Error()
+	if outbox.Attempts >= notificationMaxAttempts {
+		outbox.Status = entity.NotificationDead
+		return
+	}
+
+	outbox.Status = entity.NotificationFailed
+	outbox.NextAttemptAt = now.Add(notificationBackoff(outbox.Attempts))
+}
+
+// DeliverPendingNotifications claims a batch in a short transaction and sends
+// it outside of one, so a slow provider never holds row locks. Every result
+// is written back on its own.
+func (as *AdminService) DeliverPendingNotifications() error {
+	ctx := context.Background()
+	now := time.Now()
+	// postgres keeps microseconds and the lease is matched on write back
+	leaseUntil := now.Add(notificationLease).Truncate(time.Microsecond)
+
+	var outboxes []entity.NotificationOutbox
+	err := as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		var err error
+		outboxes, err = as.adminRepo.GetDueNotifications(ctx, tx, now, notificationBatchSize)
+		if err != nil || len(outboxes) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, 0, len(outboxes))
+		for _, outbox := range outboxes {
+			ids = append(ids, outbox.ID)
+		}
+
+		return as.adminRepo.ClaimNotifications(ctx, tx, ids, leaseUntil)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, outbox := range outboxes {
+		msg := notification.Message{
+			PhoneNumber: outbox.PhoneNumber,
+			Email:       outbox.Email,
+			Subject:     outbox.Subject,
+			Body:        outbox.Body,
+			Channels:    outbox.GetChannels(),
+		}
+		if outbox.ImagePath != "" {
+			msg.ImagePath = outbox.ImagePath
+			msg.MimeType = "image/png"
+		}
+
+		outbox.Attempts++
+		sendErr := as.notifier.Send(ctx, msg)
+		applyDeliveryResult(&outbox, sendErr, time.Now())
+		if outbox.Status == entity.NotificationDead {
+			log.Printf("[Notification] %s moved to dead letter after %d attempts: %v", outbox.ID, outbox.Attempts, sendErr)
+		}
+
+		updated, err := as.adminRepo.UpdateClaimedNotification(ctx, nil, outbox, leaseUntil)
+		if err != nil {
+			log.Printf("[Notification] failed to save result of %s: %v", outbox.ID, err)
+			continue
+		}
+		if !updated {
+			log.Printf("[Notification] lease on %s expired before its result was saved", outbox.ID)
+		}
+	}
+
+	return nil
+}
+
 // Company
 func (as *AdminService) CreateCompany(ctx context.Context, req dto.CreateCompanyRequest) (dto.CompanyResponse, error) {
 	if len(req.Name) < 3 {
@@ -1496,11 +3139,12 @@ func (as *AdminService) GetDetailCompany(ctx context.Context, companyID string)
 		return dto.CompanyResponse{}, dto.ErrCompanyNotFound
 	}
 
-	return dto.CompanyResponse{
-		ID:      &company.ID,
-		Name:    company.Name,
-		Address: company.Address,
-	}, nil
+	contacts, err := as.adminRepo.GetCompanyMembers(ctx, nil, companyID, true)
+	if err != nil {
+		return dto.CompanyResponse{}, dto.ErrGetCompanyMembers
+	}
+
+	return toCompanyResponseWithContacts(company, contacts), nil
 }
 func (as *AdminService) UpdateCompany(ctx context.Context, req dto.UpdateCompanyRequest) (dto.UpdateCompanyResponse, error) {
 	company, flag, err := as.adminRepo.GetCompanyByID(ctx, nil, req.ID)
@@ -1533,6 +3177,33 @@ func (as *AdminService) UpdateCompany(ctx context.Context, req dto.UpdateCompany
 		Address: company.Address,
 	}, nil
 }
+
+// UpdateCompanyContacts sets the members notified about packages addressed to
+// the company, an empty list notifies every member again.
+func (as *AdminService) UpdateCompanyContacts(ctx context.Context, req dto.UpdateCompanyContactsRequest) (dto.CompanyResponse, error) {
+	company, flag, err := as.adminRepo.GetCompanyByID(ctx, nil, req.ID)
+	if err != nil || !flag {
+		return dto.CompanyResponse{}, dto.ErrCompanyNotFound
+	}
+
+	for _, userID := range req.UserIDs {
+		member, err := as.adminRepo.IsCompanyMember(ctx, nil, company.ID, userID)
+		if err != nil || !member {
+			return dto.CompanyResponse{}, dto.ErrContactNotCompanyMember
+		}
+	}
+
+	if err := as.adminRepo.UpdateCompanyContacts(ctx, nil, company.ID, req.UserIDs); err != nil {
+		return dto.CompanyResponse{}, dto.ErrUpdateCompanyContacts
+	}
+
+	contacts, err := as.adminRepo.GetCompanyMembers(ctx, nil, company.ID.String(), true)
+	if err != nil {
+		return dto.CompanyResponse{}, dto.ErrGetCompanyMembers
+	}
+
+	return toCompanyResponseWithContacts(company, contacts), nil
+}
 func (as *AdminService) DeleteCompany(ctx context.Context, companyID string) (dto.CompanyResponse, error) {
 	deletedCompany, flag, err := as.adminRepo.GetCompanyByID(ctx, nil, companyID)
 	if err != nil || !flag {
@@ -1679,135 +3350,508 @@ func (as *AdminService) DeleteLocker(ctx context.Context, req dto.DeleteLockerRe
 
 	return res, nil
 }
-
-// Sender
-func (as *AdminService) CreateSender(ctx context.Context, req dto.CreateSenderRequest) (dto.SenderResponse, error) {
-	if len(req.Name) < 3 {
-		return dto.SenderResponse{}, dto.ErrInvalidSenderName
+func (as *AdminService) GetLockerOccupancy(ctx context.Context, lockerID string) ([]dto.LockerOccupancyResponse, error) {
+	var lockers []entity.Locker
+	if lockerID != "" {
+		locker, flag, err := as.adminRepo.GetLockerByID(ctx, nil, lockerID)
+		if err != nil || !flag {
+			return nil, dto.ErrLockerNotFound
+		}
+		lockers = append(lockers, locker)
+	} else {
+		all, err := as.adminRepo.GetAllLocker(ctx, nil)
+		if err != nil {
+			return nil, dto.ErrGetLockerOccupancy
+		}
+		lockers = all
 	}
-	if len(req.Address) < 3 {
-		return dto.SenderResponse{}, dto.ErrInvalidAddressName
+
+	slots, err := as.adminRepo.GetAllLockerSlot(ctx, nil, lockerID)
+	if err != nil {
+		return nil, dto.ErrGetLockerOccupancy
 	}
-	phoneNumberFormatted, err := helpers.StandardizePhoneNumber(req.PhoneNumber)
+
+	usage, err := as.adminRepo.GetLockerSlotUsage(ctx, nil, lockerID, nil)
 	if err != nil {
-		return dto.SenderResponse{}, dto.ErrFormatPhoneNumber
+		return nil, dto.ErrGetLockerOccupancy
 	}
 
-	sender := entity.Sender{
-		ID:          uuid.New(),
-		Name:        req.Name,
-		Address:     req.Address,
-		PhoneNumber: phoneNumberFormatted,
+	slotsByLocker := make(map[uuid.UUID][]dto.LockerSlotOccupancyResponse)
+	for _, slot := range slots {
+		slotsByLocker[*slot.LockerID] = append(slotsByLocker[*slot.LockerID], toLockerSlotOccupancyResponse(slot, usage[slot.ID]))
 	}
 
-	if err := as.adminRepo.CreateSender(ctx, nil, sender); err != nil {
-		return dto.SenderResponse{}, dto.ErrCreateSender
+	var datas []dto.LockerOccupancyResponse
+	for _, locker := range lockers {
+		data := dto.LockerOccupancyResponse{
+			Locker: dto.LockerResponse{
+				ID:         locker.ID,
+				LockerCode: locker.LockerCode,
+				Location:   locker.Location,
+			},
+			Slots: slotsByLocker[locker.ID],
+		}
+
+		for _, slot := range data.Slots {
+			data.Capacity += int64(slot.Capacity)
+			data.Used += slot.Used
+			data.Free += slot.Free
+		}
+
+		datas = append(datas, data)
 	}
 
-	return dto.SenderResponse{
-		ID:          sender.ID,
-		Name:        sender.Name,
-		Address:     sender.Address,
-		PhoneNumber: sender.PhoneNumber,
-	}, nil
+	return datas, nil
 }
-func (as *AdminService) GetSenderByID(ctx context.Context, senderID string) (dto.SenderResponse, error) {
-	recipient, flag, err := as.adminRepo.GetSenderByID(ctx, nil, senderID)
-	if err != nil || !flag {
-		return dto.SenderResponse{}, dto.ErrSenderNotFound
+
+// Locker Slot
+func isOccupyingStatus(status entity.Status) bool {
+	for _, s := range entity.OccupyingStatuses {
+		if s == status {
+			return true
+		}
 	}
 
-	return dto.SenderResponse{
-		ID:          recipient.ID,
-		Name:        recipient.Name,
-		Address:     recipient.Address,
-		PhoneNumber: recipient.PhoneNumber,
-	}, nil
+	return false
 }
-func (as *AdminService) GetAllSender(ctx context.Context) ([]dto.SenderResponse, error) {
-	senders, err := as.adminRepo.GetAllSender(ctx, nil)
-	if err != nil {
-		return nil, dto.ErrGetAllSenders
-	}
 
-	var datas []dto.SenderResponse
-	for _, senders := range senders {
-		datas = append(datas, dto.SenderResponse{
-			ID:          senders.ID,
-			Name:        senders.Name,
-			Address:     senders.Address,
-			PhoneNumber: senders.PhoneNumber,
-		})
+// packageSlot returns the slot a loaded package sits in, nil when it has none.
+func packageSlot(pkg entity.Package) *entity.LockerSlot {
+	if pkg.SlotID == nil {
+		return nil
 	}
 
-	return datas, nil
+	return &pkg.Slot
 }
-func (as *AdminService) GetAllSenderWithPagination(ctx context.Context, req dto.PaginationRequest) (dto.SenderPaginationResponse, error) {
-	dataWithPaginate, err := as.adminRepo.GetAllSenderWithPagination(ctx, nil, req)
-	if err != nil {
-		return dto.SenderPaginationResponse{}, dto.ErrGetAllSendersWithPagination
+
+func toLockerSlotResponse(slot entity.LockerSlot) dto.LockerSlotResponse {
+	return dto.LockerSlotResponse{
+		ID:       slot.ID,
+		SlotCode: slot.SlotCode,
+		Size:     slot.Size,
+		Capacity: slot.Capacity,
+		LockerID: slot.LockerID,
 	}
+}
 
-	var datas []dto.SenderResponse
-	for _, sender := range dataWithPaginate.Senders {
-		datas = append(datas, dto.SenderResponse{
-			ID:          sender.ID,
-			Name:        sender.Name,
-			Address:     sender.Address,
-			PhoneNumber: sender.PhoneNumber,
+func toCompanyResponseWithContacts(company entity.Company, contacts []entity.User) dto.CompanyResponse {
+	res := dto.CompanyResponse{
+		ID:       &company.ID,
+		Name:     company.Name,
+		Address:  company.Address,
+		Contacts: []dto.UserResponseCustom{},
+	}
+	for _, contact := range contacts {
+		res.Contacts = append(res.Contacts, dto.UserResponseCustom{
+			ID:    contact.ID,
+			Name:  contact.Name,
+			Email: contact.Email,
 		})
 	}
 
-	return dto.SenderPaginationResponse{
-		Data: datas,
-		PaginationResponse: dto.PaginationResponse{
-			Page:    dataWithPaginate.Page,
-			PerPage: dataWithPaginate.PerPage,
-			MaxPage: dataWithPaginate.MaxPage,
-			Count:   dataWithPaginate.Count,
-		},
-	}, nil
+	return res
 }
-func (as *AdminService) UpdateSender(ctx context.Context, req dto.UpdateSenderRequest) (dto.SenderResponse, error) {
-	sender, _, err := as.adminRepo.GetSenderByID(ctx, nil, req.ID)
+
+func toPackageSlotResponse(slot *entity.LockerSlot) *dto.LockerSlotResponse {
+	if slot == nil {
+		return nil
+	}
+
+	res := toLockerSlotResponse(*slot)
+	return &res
+}
+
+func toLockerSlotOccupancyResponse(slot entity.LockerSlot, used int64) dto.LockerSlotOccupancyResponse {
+	return dto.LockerSlotOccupancyResponse{
+		LockerSlotResponse: toLockerSlotResponse(slot),
+		Used:               used,
+		Free:               max(int64(slot.Capacity)-used, 0),
+	}
+}
+
+// assignSlot picks the smallest free slot of the locker that fits the package,
+// ties go to the lowest slot code. The locker row stays locked until the
+// caller's transaction ends so concurrent intakes cannot overfill a slot.
+// Lockers without configured slots keep accepting packages without a slot.
+func (as *AdminService) assignSlot(ctx context.Context, tx *gorm.DB, lockerID uuid.UUID, size entity.SlotSize, excludePkgID *uuid.UUID) (*entity.LockerSlot, error) {
+	if _, flag, err := as.adminRepo.LockLockerByID(ctx, tx, lockerID.String()); err != nil || !flag {
+		return nil, dto.ErrLockerNotFound
+	}
+
+	slots, err := as.adminRepo.GetAllLockerSlot(ctx, tx, lockerID.String())
 	if err != nil {
-		return dto.SenderResponse{}, dto.ErrGetSenderByID
+		return nil, dto.ErrAssignLockerSlot
+	}
+	if len(slots) == 0 {
+		return nil, nil
 	}
 
-	if req.Name != "" {
-		if len(req.Name) < 3 {
-			return dto.SenderResponse{}, dto.ErrInvalidName
+	usage, err := as.adminRepo.GetLockerSlotUsage(ctx, tx, lockerID.String(), excludePkgID)
+	if err != nil {
+		return nil, dto.ErrAssignLockerSlot
+	}
+
+	var best *entity.LockerSlot
+	for i := range slots {
+		slot := &slots[i]
+		if !slot.Fits(size) || usage[slot.ID] >= int64(slot.Capacity) {
+			continue
+		}
+
+		if best == nil || entity.SlotSizeRank(slot.Size) < entity.SlotSizeRank(best.Size) {
+			best = slot
 		}
-		sender.Name = req.Name
 	}
 
-	if req.PhoneNumber != "" {
-		phoneNumberFormatted, err := helpers.StandardizePhoneNumber(req.PhoneNumber)
+	if best == nil {
+		alternatives, err := as.alternativeLockers(ctx, tx, lockerID, size)
 		if err != nil {
-			return dto.SenderResponse{}, dto.ErrFormatPhoneNumber
+			return nil, dto.ErrAssignLockerSlot
 		}
 
-		sender.PhoneNumber = phoneNumberFormatted
+		return nil, &dto.LockerFullError{Alternatives: alternatives}
 	}
 
-	err = as.adminRepo.UpdateSender(ctx, nil, sender)
+	return best, nil
+}
+
+// alternativeLockers lists the other lockers that still have a free slot
+// fitting the package size.
+func (as *AdminService) alternativeLockers(ctx context.Context, tx *gorm.DB, lockerID uuid.UUID, size entity.SlotSize) ([]dto.LockerResponse, error) {
+	slots, err := as.adminRepo.GetAllLockerSlot(ctx, tx, "")
 	if err != nil {
-		return dto.SenderResponse{}, dto.ErrUpdateSender
+		return nil, err
 	}
 
-	res := dto.SenderResponse{
-		ID:          sender.ID,
-		Name:        sender.Name,
-		Address:     sender.Address,
-		PhoneNumber: sender.PhoneNumber,
+	usage, err := as.adminRepo.GetLockerSlotUsage(ctx, tx, "", nil)
+	if err != nil {
+		return nil, err
 	}
 
-	return res, nil
-}
-func (as *AdminService) DeleteSender(ctx context.Context, req dto.DeleteSenderRequest) (dto.SenderResponse, error) {
-	deletedSender, flag, err := as.adminRepo.GetSenderByID(ctx, nil, req.SenderID)
-	if err != nil || !flag {
-		return dto.SenderResponse{}, dto.ErrSenderNotFound
+	seen := make(map[uuid.UUID]bool)
+	alternatives := []dto.LockerResponse{}
+	for _, slot := range slots {
+		if *slot.LockerID == lockerID || seen[*slot.LockerID] {
+			continue
+		}
+		if !slot.Fits(size) || usage[slot.ID] >= int64(slot.Capacity) {
+			continue
+		}
+
+		seen[*slot.LockerID] = true
+		alternatives = append(alternatives, dto.LockerResponse{
+			ID:         slot.Locker.ID,
+			LockerCode: slot.Locker.LockerCode,
+			Location:   slot.Locker.Location,
+		})
+	}
+
+	return alternatives, nil
+}
+
+func (as *AdminService) CreateLockerSlot(ctx context.Context, req dto.CreateLockerSlotRequest) (dto.LockerSlotResponse, error) {
+	if req.SlotCode == "" {
+		return dto.LockerSlotResponse{}, dto.ErrMissingRequiredField
+	}
+
+	if !entity.IsValidSlotSize(req.Size) {
+		return dto.LockerSlotResponse{}, dto.ErrInvalidSlotSize
+	}
+
+	if req.Capacity == 0 {
+		req.Capacity = 1
+	}
+	if req.Capacity < 0 {
+		return dto.LockerSlotResponse{}, dto.ErrInvalidSlotCapacity
+	}
+
+	locker, flag, err := as.adminRepo.GetLockerByID(ctx, nil, req.LockerID.String())
+	if err != nil || !flag {
+		return dto.LockerSlotResponse{}, dto.ErrLockerNotFound
+	}
+
+	if _, flag, _ := as.adminRepo.GetLockerSlotByCode(ctx, nil, locker.ID, req.SlotCode); flag {
+		return dto.LockerSlotResponse{}, dto.ErrLockerSlotCodeAlreadyExists
+	}
+
+	slot := entity.LockerSlot{
+		ID:       uuid.New(),
+		SlotCode: req.SlotCode,
+		Size:     req.Size,
+		Capacity: req.Capacity,
+		LockerID: &locker.ID,
+	}
+
+	if err := as.adminRepo.CreateLockerSlot(ctx, nil, slot); err != nil {
+		return dto.LockerSlotResponse{}, dto.ErrCreateLockerSlot
+	}
+
+	return toLockerSlotResponse(slot), nil
+}
+func (as *AdminService) ReadAllLockerSlot(ctx context.Context, lockerID string) ([]dto.LockerSlotOccupancyResponse, error) {
+	if _, flag, err := as.adminRepo.GetLockerByID(ctx, nil, lockerID); err != nil || !flag {
+		return nil, dto.ErrLockerNotFound
+	}
+
+	slots, err := as.adminRepo.GetAllLockerSlot(ctx, nil, lockerID)
+	if err != nil {
+		return nil, dto.ErrGetAllLockerSlot
+	}
+
+	usage, err := as.adminRepo.GetLockerSlotUsage(ctx, nil, lockerID, nil)
+	if err != nil {
+		return nil, dto.ErrGetAllLockerSlot
+	}
+
+	var datas []dto.LockerSlotOccupancyResponse
+	for _, slot := range slots {
+		datas = append(datas, toLockerSlotOccupancyResponse(slot, usage[slot.ID]))
+	}
+
+	return datas, nil
+}
+func (as *AdminService) UpdateLockerSlot(ctx context.Context, req dto.UpdateLockerSlotRequest) (dto.LockerSlotResponse, error) {
+	var slot entity.LockerSlot
+	err := as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		current, flag, err := as.adminRepo.GetLockerSlotByID(ctx, tx, req.ID)
+		if err != nil || !flag {
+			return dto.ErrLockerSlotNotFound
+		}
+		slot = current
+
+		if _, _, err := as.adminRepo.LockLockerByID(ctx, tx, slot.LockerID.String()); err != nil {
+			return dto.ErrLockerNotFound
+		}
+
+		if req.SlotCode != "" && req.SlotCode != slot.SlotCode {
+			if _, flag, _ := as.adminRepo.GetLockerSlotByCode(ctx, tx, *slot.LockerID, req.SlotCode); flag {
+				return dto.ErrLockerSlotCodeAlreadyExists
+			}
+			slot.SlotCode = req.SlotCode
+		}
+
+		if req.Size != "" {
+			if !entity.IsValidSlotSize(req.Size) {
+				return dto.ErrInvalidSlotSize
+			}
+			slot.Size = req.Size
+		}
+
+		if req.Capacity != nil {
+			if *req.Capacity <= 0 {
+				return dto.ErrInvalidSlotCapacity
+			}
+			slot.Capacity = *req.Capacity
+		}
+
+		// packages already in the slot must still fit after the change
+		usage, err := as.adminRepo.GetLockerSlotUsage(ctx, tx, slot.LockerID.String(), nil)
+		if err != nil {
+			return dto.ErrUpdateLockerSlot
+		}
+		if usage[slot.ID] > 0 && (usage[slot.ID] > int64(slot.Capacity) || entity.SlotSizeRank(slot.Size) < entity.SlotSizeRank(current.Size)) {
+			return dto.ErrLockerSlotInUse
+		}
+
+		if err := as.adminRepo.UpdateLockerSlot(ctx, tx, slot); err != nil {
+			return dto.ErrUpdateLockerSlot
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.LockerSlotResponse{}, err
+	}
+
+	return toLockerSlotResponse(slot), nil
+}
+func (as *AdminService) DeleteLockerSlot(ctx context.Context, slotID string) (dto.LockerSlotResponse, error) {
+	var slot entity.LockerSlot
+	err := as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		current, flag, err := as.adminRepo.GetLockerSlotByID(ctx, tx, slotID)
+		if err != nil || !flag {
+			return dto.ErrLockerSlotNotFound
+		}
+		slot = current
+
+		if _, _, err := as.adminRepo.LockLockerByID(ctx, tx, slot.LockerID.String()); err != nil {
+			return dto.ErrLockerNotFound
+		}
+
+		usage, err := as.adminRepo.GetLockerSlotUsage(ctx, tx, slot.LockerID.String(), nil)
+		if err != nil {
+			return dto.ErrDeleteLockerSlot
+		}
+		if usage[slot.ID] > 0 {
+			return dto.ErrLockerSlotInUse
+		}
+
+		if err := as.adminRepo.DeleteLockerSlotByID(ctx, tx, slotID); err != nil {
+			return dto.ErrDeleteLockerSlot
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.LockerSlotResponse{}, err
+	}
+
+	return toLockerSlotResponse(slot), nil
+}
+
+// Sender
+func (as *AdminService) CreateSender(ctx context.Context, req dto.CreateSenderRequest) (dto.SenderResponse, error) {
+	if len(req.Name) < 3 {
+		return dto.SenderResponse{}, dto.ErrInvalidSenderName
+	}
+	if len(req.Address) < 3 {
+		return dto.SenderResponse{}, dto.ErrInvalidAddressName
+	}
+	phoneNumberFormatted, err := helpers.StandardizePhoneNumber(req.PhoneNumber)
+	if err != nil {
+		return dto.SenderResponse{}, dto.ErrFormatPhoneNumber
+	}
+
+	sender := entity.Sender{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Address:     req.Address,
+		PhoneNumber: phoneNumberFormatted,
+	}
+
+	if err := as.adminRepo.CreateSender(ctx, nil, sender); err != nil {
+		return dto.SenderResponse{}, dto.ErrCreateSender
+	}
+
+	return dto.SenderResponse{
+		ID:          sender.ID,
+		Name:        sender.Name,
+		Address:     sender.Address,
+		PhoneNumber: sender.PhoneNumber,
+	}, nil
+}
+func (as *AdminService) GetSenderByID(ctx context.Context, senderID string) (dto.SenderResponse, error) {
+	recipient, flag, err := as.adminRepo.GetSenderByID(ctx, nil, senderID)
+	if err != nil || !flag {
+		return dto.SenderResponse{}, dto.ErrSenderNotFound
+	}
+
+	return dto.SenderResponse{
+		ID:          recipient.ID,
+		Name:        recipient.Name,
+		Address:     recipient.Address,
+		PhoneNumber: recipient.PhoneNumber,
+	}, nil
+}
+func (as *AdminService) GetAllSender(ctx context.Context) ([]dto.SenderResponse, error) {
+	senders, err := as.adminRepo.GetAllSender(ctx, nil)
+	if err != nil {
+		return nil, dto.ErrGetAllSenders
+	}
+
+	var datas []dto.SenderResponse
+	for _, senders := range senders {
+		datas = append(datas, dto.SenderResponse{
+			ID:          senders.ID,
+			Name:        senders.Name,
+			Address:     senders.Address,
+			PhoneNumber: senders.PhoneNumber,
+		})
+	}
+
+	return datas, nil
+}
+func (as *AdminService) GetAllSenderWithPagination(ctx context.Context, req dto.PaginationRequest) (dto.SenderPaginationResponse, error) {
+	dataWithPaginate, err := as.adminRepo.GetAllSenderWithPagination(ctx, nil, req)
+	if err != nil {
+		return dto.SenderPaginationResponse{}, dto.ErrGetAllSendersWithPagination
+	}
+
+	var datas []dto.SenderResponse
+	for _, sender := range dataWithPaginate.Senders {
+		datas = append(datas, dto.SenderResponse{
+			ID:          sender.ID,
+			Name:        sender.Name,
+			Address:     sender.Address,
+			PhoneNumber: sender.PhoneNumber,
+		})
+	}
+
+	return dto.SenderPaginationResponse{
+		Data: datas,
+		PaginationResponse: dto.PaginationResponse{
+			Page:    dataWithPaginate.Page,
+			PerPage: dataWithPaginate.PerPage,
+			MaxPage: dataWithPaginate.MaxPage,
+			Count:   dataWithPaginate.Count,
+		},
+	}, nil
+}
+func (as *AdminService) GetAllSenderWithCursor(ctx context.Context, req dto.CursorRequest) (dto.SenderCursorResponse, error) {
+	if err := req.Validate(); err != nil {
+		return dto.SenderCursorResponse{}, err
+	}
+
+	dataWithCursor, err := as.adminRepo.GetAllSenderWithCursor(ctx, nil, req)
+	if err != nil {
+		return dto.SenderCursorResponse{}, dto.ErrGetAllSendersWithCursor
+	}
+
+	datas := []dto.SenderResponse{}
+	for _, sender := range dataWithCursor.Senders {
+		datas = append(datas, dto.SenderResponse{
+			ID:          sender.ID,
+			Name:        sender.Name,
+			Address:     sender.Address,
+			PhoneNumber: sender.PhoneNumber,
+		})
+	}
+
+	return dto.SenderCursorResponse{
+		Data:           datas,
+		CursorResponse: dataWithCursor.CursorResponse,
+	}, nil
+}
+func (as *AdminService) UpdateSender(ctx context.Context, req dto.UpdateSenderRequest) (dto.SenderResponse, error) {
+	sender, _, err := as.adminRepo.GetSenderByID(ctx, nil, req.ID)
+	if err != nil {
+		return dto.SenderResponse{}, dto.ErrGetSenderByID
+	}
+
+	if req.Name != "" {
+		if len(req.Name) < 3 {
+			return dto.SenderResponse{}, dto.ErrInvalidName
+		}
+		sender.Name = req.Name
+	}
+
+	if req.PhoneNumber != "" {
+		phoneNumberFormatted, err := helpers.StandardizePhoneNumber(req.PhoneNumber)
+		if err != nil {
+			return dto.SenderResponse{}, dto.ErrFormatPhoneNumber
+		}
+
+		sender.PhoneNumber = phoneNumberFormatted
+	}
+
+	err = as.adminRepo.UpdateSender(ctx, nil, sender)
+	if err != nil {
+		return dto.SenderResponse{}, dto.ErrUpdateSender
+	}
+
+	res := dto.SenderResponse{
+		ID:          sender.ID,
+		Name:        sender.Name,
+		Address:     sender.Address,
+		PhoneNumber: sender.PhoneNumber,
+	}
+
+	return res, nil
+}
+func (as *AdminService) DeleteSender(ctx context.Context, req dto.DeleteSenderRequest) (dto.SenderResponse, error) {
+	deletedSender, flag, err := as.adminRepo.GetSenderByID(ctx, nil, req.SenderID)
+	if err != nil || !flag {
+		return dto.SenderResponse{}, dto.ErrSenderNotFound
 	}
 
 	err = as.adminRepo.DeleteSenderByID(ctx, nil, req.SenderID)
@@ -1824,3 +3868,779 @@ func (as *AdminService) DeleteSender(ctx context.Context, req dto.DeleteSenderRe
 
 	return res, nil
 }
+
+// Notification
+func toNotificationResponse(outbox entity.NotificationOutbox) dto.NotificationResponse {
+	return dto.NotificationResponse{
+		ID:            outbox.ID,
+		Channels:      outbox.Channels,
+		PhoneNumber:   outbox.PhoneNumber,
+		Email:         outbox.Email,
+		Subject:       outbox.Subject,
+		Status:        outbox.Status,
+		Attempts:      outbox.Attempts,
+		NextAttemptAt: outbox.NextAttemptAt,
+		LastError:     outbox.LastError,
+		SentAt:        outbox.SentAt,
+		UserID:        outbox.UserID,
+		PackageID:     outbox.PackageID,
+		TimeStamp: entity.TimeStamp{
+			CreatedAt: outbox.CreatedAt,
+			UpdatedAt: outbox.UpdatedAt,
+			DeletedAt: outbox.DeletedAt,
+		},
+	}
+}
+func (as *AdminService) ReadAllNotificationWithPagination(ctx context.Context, req dto.PaginationRequest, status string) (dto.NotificationPaginationResponse, error) {
+	if status != "" && !entity.IsValidNotificationStatus(entity.NotificationStatus(status)) {
+		return dto.NotificationPaginationResponse{}, dto.ErrInvalidNotificationStatus
+	}
+
+	dataWithPaginate, err := as.adminRepo.GetAllNotificationWithPagination(ctx, nil, req, status)
+	if err != nil {
+		return dto.NotificationPaginationResponse{}, dto.ErrGetAllNotificationWithPagination
+	}
+
+	datas := []dto.NotificationResponse{}
+	for _, outbox := range dataWithPaginate.Notifications {
+		datas = append(datas, toNotificationResponse(outbox))
+	}
+
+	return dto.NotificationPaginationResponse{
+		Data: datas,
+		PaginationResponse: dto.PaginationResponse{
+			Page:    dataWithPaginate.Page,
+			PerPage: dataWithPaginate.PerPage,
+			MaxPage: dataWithPaginate.MaxPage,
+			Count:   dataWithPaginate.Count,
+		},
+	}, nil
+}
+func (as *AdminService) RetryNotification(ctx context.Context, notificationID string) (dto.NotificationResponse, error) {
+	var outbox entity.NotificationOutbox
+
+	err := as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		var (
+			found bool
+			err   error
+		)
+		outbox, found, err = as.adminRepo.LockNotificationByID(ctx, tx, notificationID)
+		if err != nil || !found {
+			return dto.ErrNotificationNotFound
+		}
+
+		if outbox.Status != entity.NotificationFailed && outbox.Status != entity.NotificationDead && outbox.Status != entity.NotificationCancelled {
+			return dto.ErrNotificationCannotBeRetried
+		}
+
+		outbox.Status = entity.NotificationPending
+		outbox.Attempts = 0
+		outbox.NextAttemptAt = time.Now()
+		outbox.LastError = ""
+
+		if err := as.adminRepo.UpdateNotification(ctx, tx, outbox); err != nil {
+			return dto.ErrUpdateNotification
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.NotificationResponse{}, err
+	}
+
+	return toNotificationResponse(outbox), nil
+}
+func (as *AdminService) CancelNotification(ctx context.Context, notificationID string) (dto.NotificationResponse, error) {
+	var outbox entity.NotificationOutbox
+
+	err := as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		var (
+			found bool
+			err   error
+		)
+		outbox, found, err = as.adminRepo.LockNotificationByID(ctx, tx, notificationID)
+		if err != nil || !found {
+			return dto.ErrNotificationNotFound
+		}
+
+		if outbox.Status != entity.NotificationPending && outbox.Status != entity.NotificationFailed {
+			return dto.ErrNotificationCannotBeCancelled
+		}
+
+		outbox.Status = entity.NotificationCancelled
+
+		if err := as.adminRepo.UpdateNotification(ctx, tx, outbox); err != nil {
+			return dto.ErrUpdateNotification
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.NotificationResponse{}, err
+	}
+
+	return toNotificationResponse(outbox), nil
+}
+
+// Retention Policy
+func joinReminderDays(days []int, expiryDays int) (string, error) {
+	var values []string
+	for _, day := range days {
+		if day <= 0 || day >= expiryDays {
+			return "", dto.ErrInvalidReminderDays
+		}
+		values = append(values, strconv.Itoa(day))
+	}
+
+	return strings.Join(values, ","), nil
+}
+func toRetentionPolicyResponse(policy entity.RetentionPolicy) dto.RetentionPolicyResponse {
+	res := dto.RetentionPolicyResponse{
+		ID:              policy.ID,
+		PackageType:     policy.PackageType,
+		ExpiryDays:      policy.ExpiryDays,
+		ReminderDays:    policy.GetReminderDays(),
+		GracePeriodDays: policy.GracePeriodDays,
+	}
+
+	if policy.CompanyID != nil {
+		res.Company = &dto.CompanyResponse{
+			ID:      policy.CompanyID,
+			Name:    policy.Company.Name,
+			Address: policy.Company.Address,
+		}
+	}
+
+	return res
+}
+func (as *AdminService) CreateRetentionPolicy(ctx context.Context, req dto.CreateRetentionPolicyRequest) (dto.RetentionPolicyResponse, error) {
+	if req.PackageType != "" && !entity.IsValidType(req.PackageType) {
+		return dto.RetentionPolicyResponse{}, dto.ErrInvalidPackageType
+	}
+
+	if req.ExpiryDays <= 0 {
+		return dto.RetentionPolicyResponse{}, dto.ErrInvalidExpiryDays
+	}
+
+	if req.GracePeriodDays < 0 {
+		return dto.RetentionPolicyResponse{}, dto.ErrInvalidGracePeriodDays
+	}
+
+	reminderDays, err := joinReminderDays(req.ReminderDays, req.ExpiryDays)
+	if err != nil {
+		return dto.RetentionPolicyResponse{}, err
+	}
+
+	if req.CompanyID != nil {
+		if _, found, err := as.adminRepo.GetCompanyByID(ctx, nil, req.CompanyID.String()); err != nil || !found {
+			return dto.RetentionPolicyResponse{}, dto.ErrCompanyNotFound
+		}
+	}
+
+	if _, found, _ := as.adminRepo.GetRetentionPolicyByScope(ctx, nil, req.CompanyID, req.PackageType); found {
+		return dto.RetentionPolicyResponse{}, dto.ErrRetentionPolicyAlreadyExists
+	}
+
+	policy := entity.RetentionPolicy{
+		ID:              uuid.New(),
+		CompanyID:       req.CompanyID,
+		PackageType:     req.PackageType,
+		ExpiryDays:      req.ExpiryDays,
+		ReminderDays:    reminderDays,
+		GracePeriodDays: req.GracePeriodDays,
+	}
+
+	if err := as.adminRepo.CreateRetentionPolicy(ctx, nil, policy); err != nil {
+		return dto.RetentionPolicyResponse{}, dto.ErrCreateRetentionPolicy
+	}
+
+	policy, _, err = as.adminRepo.GetRetentionPolicyByID(ctx, nil, policy.ID.String())
+	if err != nil {
+		return dto.RetentionPolicyResponse{}, dto.ErrRetentionPolicyNotFound
+	}
+
+	return toRetentionPolicyResponse(policy), nil
+}
+func (as *AdminService) ReadAllRetentionPolicy(ctx context.Context) ([]dto.RetentionPolicyResponse, error) {
+	policies, err := as.adminRepo.GetAllRetentionPolicy(ctx, nil)
+	if err != nil {
+		return nil, dto.ErrGetAllRetentionPolicy
+	}
+
+	datas := []dto.RetentionPolicyResponse{}
+	for _, policy := range policies {
+		datas = append(datas, toRetentionPolicyResponse(policy))
+	}
+
+	return datas, nil
+}
+func (as *AdminService) GetDetailRetentionPolicy(ctx context.Context, policyID string) (dto.RetentionPolicyResponse, error) {
+	policy, found, err := as.adminRepo.GetRetentionPolicyByID(ctx, nil, policyID)
+	if err != nil || !found {
+		return dto.RetentionPolicyResponse{}, dto.ErrRetentionPolicyNotFound
+	}
+
+	return toRetentionPolicyResponse(policy), nil
+}
+func (as *AdminService) UpdateRetentionPolicy(ctx context.Context, req dto.UpdateRetentionPolicyRequest) (dto.RetentionPolicyResponse, error) {
+	policy, found, err := as.adminRepo.GetRetentionPolicyByID(ctx, nil, req.ID)
+	if err != nil || !found {
+		return dto.RetentionPolicyResponse{}, dto.ErrRetentionPolicyNotFound
+	}
+
+	if req.ExpiryDays != nil {
+		if *req.ExpiryDays <= 0 {
+			return dto.RetentionPolicyResponse{}, dto.ErrInvalidExpiryDays
+		}
+
+		policy.ExpiryDays = *req.ExpiryDays
+	}
+
+	if req.GracePeriodDays != nil {
+		if *req.GracePeriodDays < 0 {
+			return dto.RetentionPolicyResponse{}, dto.ErrInvalidGracePeriodDays
+		}
+
+		policy.GracePeriodDays = *req.GracePeriodDays
+	}
+
+	reminderDays := req.ReminderDays
+	if reminderDays == nil {
+		reminderDays = policy.GetReminderDays()
+	}
+
+	// re-validated against the (possibly new) expiry
+	policy.ReminderDays, err = joinReminderDays(reminderDays, policy.ExpiryDays)
+	if err != nil {
+		return dto.RetentionPolicyResponse{}, err
+	}
+
+	if err := as.adminRepo.UpdateRetentionPolicy(ctx, nil, policy); err != nil {
+		return dto.RetentionPolicyResponse{}, dto.ErrUpdateRetentionPolicy
+	}
+
+	return toRetentionPolicyResponse(policy), nil
+}
+func (as *AdminService) DeleteRetentionPolicy(ctx context.Context, policyID string) (dto.RetentionPolicyResponse, error) {
+	policy, found, err := as.adminRepo.GetRetentionPolicyByID(ctx, nil, policyID)
+	if err != nil || !found {
+		return dto.RetentionPolicyResponse{}, dto.ErrRetentionPolicyNotFound
+	}
+
+	if err := as.adminRepo.DeleteRetentionPolicyByID(ctx, nil, policyID); err != nil {
+		return dto.RetentionPolicyResponse{}, dto.ErrDeleteRetentionPolicy
+	}
+
+	return toRetentionPolicyResponse(policy), nil
+}
+
+// Stats
+func statsCacheKey(ctx context.Context, name string, req dto.StatsRequest) string {
+	key := name + "|" + tenant.Key(ctx)
+	for _, t := range []*time.Time{req.From, req.To} {
+		if t == nil {
+			key += "|"
+			continue
+		}
+		key += "|" + t.UTC().Format(time.RFC3339)
+	}
+
+	return fmt.Sprintf("%s|%s|%d", key, req.Interval, req.Limit)
+}
+
+// formatDuration renders seconds as a short human string such as "1d 4h 5m".
+func formatDuration(seconds float64) string {
+	d := time.Duration(seconds) * time.Second
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (as *AdminService) StatsCacheTTL() time.Duration {
+	return as.statsCache.TTL()
+}
+func (as *AdminService) GetPackageStats(ctx context.Context, req dto.StatsRequest) (dto.PackageStatsResponse, error) {
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return dto.PackageStatsResponse{}, dto.ErrInvalidDateRange
+	}
+
+	key := statsCacheKey(ctx, "summary", req)
+	if cached, ok := as.statsCache.Get(key); ok {
+		return cached.(dto.PackageStatsResponse), nil
+	}
+
+	now := time.Now()
+	res := dto.PackageStatsResponse{GeneratedAt: now}
+
+	var err error
+	if res.Total, err = as.adminRepo.CountPackages(ctx, nil, req); err != nil {
+		return dto.PackageStatsResponse{}, dto.ErrGetPackageStats
+	}
+	if res.Overdue, err = as.adminRepo.CountOverduePackages(ctx, nil, req, now); err != nil {
+		return dto.PackageStatsResponse{}, dto.ErrGetPackageStats
+	}
+	if res.AverageTimeToPickupSeconds, err = as.adminRepo.GetAverageTimeToPickup(ctx, nil, req); err != nil {
+		return dto.PackageStatsResponse{}, dto.ErrGetPackageStats
+	}
+	if res.ByStatus, err = as.adminRepo.CountPackagesByStatus(ctx, nil, req); err != nil {
+		return dto.PackageStatsResponse{}, dto.ErrGetPackageStats
+	}
+	if res.ByType, err = as.adminRepo.CountPackagesByType(ctx, nil, req); err != nil {
+		return dto.PackageStatsResponse{}, dto.ErrGetPackageStats
+	}
+	if res.ByCompany, err = as.adminRepo.CountPackagesByCompany(ctx, nil, req); err != nil {
+		return dto.PackageStatsResponse{}, dto.ErrGetPackageStats
+	}
+	if res.ByLocker, err = as.adminRepo.CountPackagesByLocker(ctx, nil, req); err != nil {
+		return dto.PackageStatsResponse{}, dto.ErrGetPackageStats
+	}
+	res.AverageTimeToPickup = formatDuration(res.AverageTimeToPickupSeconds)
+
+	as.statsCache.Set(key, res)
+
+	return res, nil
+}
+func (as *AdminService) GetPackageReceivedStats(ctx context.Context, req dto.StatsRequest) (dto.PackageReceivedStatsResponse, error) {
+	if req.Interval == "" {
+		req.Interval = "day"
+	}
+	if req.Interval != "day" && req.Interval != "week" && req.Interval != "month" {
+		return dto.PackageReceivedStatsResponse{}, dto.ErrInvalidStatsInterval
+	}
+
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return dto.PackageReceivedStatsResponse{}, dto.ErrInvalidDateRange
+	}
+
+	// without a range the series would cover the whole table, default to the
+	// last 30 days
+	if req.From == nil && req.To == nil {
+		from := time.Now().Truncate(24*time.Hour).AddDate(0, 0, -30)
+		req.From = &from
+	}
+
+	key := statsCacheKey(ctx, "received", req)
+	if cached, ok := as.statsCache.Get(key); ok {
+		return cached.(dto.PackageReceivedStatsResponse), nil
+	}
+
+	series, err := as.adminRepo.CountPackagesReceivedPerPeriod(ctx, nil, req)
+	if err != nil {
+		return dto.PackageReceivedStatsResponse{}, dto.ErrGetPackageStats
+	}
+
+	res := dto.PackageReceivedStatsResponse{
+		Interval:    req.Interval,
+		Series:      series,
+		GeneratedAt: time.Now(),
+	}
+	as.statsCache.Set(key, res)
+
+	return res, nil
+}
+func (as *AdminService) GetTopRecipients(ctx context.Context, req dto.StatsRequest) ([]dto.TopRecipientResponse, error) {
+	if req.Limit <= 0 {
+		req.Limit = statsDefaultTopLimit
+	}
+	req.Limit = min(req.Limit, statsMaxTopLimit)
+
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		return nil, dto.ErrInvalidDateRange
+	}
+
+	key := statsCacheKey(ctx, "top-recipients", req)
+	if cached, ok := as.statsCache.Get(key); ok {
+		return cached.([]dto.TopRecipientResponse), nil
+	}
+
+	recipients, err := as.adminRepo.GetTopRecipients(ctx, nil, req)
+	if err != nil {
+		return nil, dto.ErrGetPackageStats
+	}
+
+	as.statsCache.Set(key, recipients)
+
+	return recipients, nil
+}
+
+// Role & Permission
+// RegisterRoutes stores the routes served by the API once the router is
+// built, they are what permissions can be attached to.
+func (as *AdminService) RegisterRoutes(routes []dto.RouteResponse) {
+	as.routes = routes
+}
+func toPermissionResponse(permission entity.Permission) dto.PermissionResponse {
+	return dto.PermissionResponse{
+		ID:       permission.ID,
+		Endpoint: permission.Endpoint,
+		Method:   permission.Method,
+		RoleID:   permission.RoleID,
+	}
+}
+func toRoleDetailResponse(role entity.Role, userCount int64) dto.RoleDetailResponse {
+	permissions := make([]dto.PermissionResponse, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, toPermissionResponse(permission))
+	}
+
+	return dto.RoleDetailResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		UserCount:   userCount,
+		Permissions: permissions,
+	}
+}
+func isBuiltInRole(name string) bool {
+	return name == entity.RoleAdmin || name == entity.RoleCompanyAdmin || name == entity.RoleUser
+}
+
+// lockedAdminCount locks the admin role and counts its users, callers run it
+// inside the transaction that may take the admin role away from someone.
+func (as *AdminService) lockedAdminCount(ctx context.Context, tx *gorm.DB) (int64, error) {
+	adminRole, flag, err := as.adminRepo.LockRoleByName(ctx, tx, entity.RoleAdmin)
+	if err != nil || !flag {
+		return 0, dto.ErrRoleNotFound
+	}
+
+	count, err := as.adminRepo.CountUsersByRoleID(ctx, tx, adminRole.ID)
+	if err != nil {
+		return 0, dto.ErrCountRoleUsers
+	}
+
+	return count, nil
+}
+func (as *AdminService) CreateRole(ctx context.Context, req dto.CreateRoleRequest) (dto.RoleDetailResponse, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if len(name) < 3 {
+		return dto.RoleDetailResponse{}, dto.ErrInvalidRoleName
+	}
+
+	if _, flag, _ := as.adminRepo.GetRoleByName(ctx, nil, name); flag {
+		return dto.RoleDetailResponse{}, dto.ErrRoleNameExists
+	}
+
+	role := entity.Role{
+		ID:   uuid.New(),
+		Name: name,
+	}
+
+	if err := as.adminRepo.CreateRole(ctx, nil, role); err != nil {
+		return dto.RoleDetailResponse{}, dto.ErrCreateRole
+	}
+
+	return toRoleDetailResponse(role, 0), nil
+}
+func (as *AdminService) ReadAllRole(ctx context.Context) ([]dto.RoleDetailResponse, error) {
+	roles, err := as.adminRepo.GetAllRole(ctx, nil)
+	if err != nil {
+		return nil, dto.ErrGetAllRole
+	}
+
+	counts, err := as.adminRepo.CountUsersByRole(ctx, nil)
+	if err != nil {
+		return nil, dto.ErrCountRoleUsers
+	}
+
+	datas := make([]dto.RoleDetailResponse, 0, len(roles))
+	for _, role := range roles {
+		datas = append(datas, toRoleDetailResponse(role, counts[role.ID]))
+	}
+
+	return datas, nil
+}
+func (as *AdminService) GetDetailRole(ctx context.Context, roleID string) (dto.RoleDetailResponse, error) {
+	role, flag, err := as.adminRepo.GetRoleWithPermissionsByID(ctx, nil, roleID)
+	if err != nil || !flag {
+		return dto.RoleDetailResponse{}, dto.ErrRoleNotFound
+	}
+
+	count, err := as.adminRepo.CountUsersByRoleID(ctx, nil, role.ID)
+	if err != nil {
+		return dto.RoleDetailResponse{}, dto.ErrCountRoleUsers
+	}
+
+	return toRoleDetailResponse(role, count), nil
+}
+func (as *AdminService) UpdateRole(ctx context.Context, req dto.UpdateRoleRequest) (dto.RoleDetailResponse, error) {
+	role, flag, err := as.adminRepo.GetRoleWithPermissionsByID(ctx, nil, req.ID)
+	if err != nil || !flag {
+		return dto.RoleDetailResponse{}, dto.ErrRoleNotFound
+	}
+
+	if isBuiltInRole(role.Name) {
+		return dto.RoleDetailResponse{}, dto.ErrBuiltInRole
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if len(name) < 3 {
+		return dto.RoleDetailResponse{}, dto.ErrInvalidRoleName
+	}
+
+	if name != role.Name {
+		if _, flag, _ := as.adminRepo.GetRoleByName(ctx, nil, name); flag {
+			return dto.RoleDetailResponse{}, dto.ErrRoleNameExists
+		}
+
+		role.Name = name
+		if err := as.adminRepo.UpdateRole(ctx, nil, role); err != nil {
+			return dto.RoleDetailResponse{}, dto.ErrUpdateRole
+		}
+	}
+
+	count, err := as.adminRepo.CountUsersByRoleID(ctx, nil, role.ID)
+	if err != nil {
+		return dto.RoleDetailResponse{}, dto.ErrCountRoleUsers
+	}
+
+	return toRoleDetailResponse(role, count), nil
+}
+
+// DeleteRole removes a role and its permissions, roles still assigned to
+// users have to be emptied with AssignRole first.
+func (as *AdminService) DeleteRole(ctx context.Context, roleID string) (dto.RoleDetailResponse, error) {
+	role, flag, err := as.adminRepo.GetRoleWithPermissionsByID(ctx, nil, roleID)
+	if err != nil || !flag {
+		return dto.RoleDetailResponse{}, dto.ErrRoleNotFound
+	}
+
+	if isBuiltInRole(role.Name) {
+		return dto.RoleDetailResponse{}, dto.ErrBuiltInRole
+	}
+
+	err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		count, err := as.adminRepo.CountUsersByRoleID(ctx, tx, role.ID)
+		if err != nil {
+			return dto.ErrCountRoleUsers
+		}
+
+		if count > 0 {
+			return dto.ErrRoleHasUsers
+		}
+
+		if err := as.adminRepo.DeletePermissionsByRoleID(ctx, tx, role.ID); err != nil {
+			return dto.ErrDeletePermission
+		}
+
+		if err := as.adminRepo.DeleteRoleByID(ctx, tx, role.ID); err != nil {
+			return dto.ErrDeleteRole
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.RoleDetailResponse{}, err
+	}
+
+	as.permissionService.InvalidateRole(role.ID.String())
+
+	return toRoleDetailResponse(role, 0), nil
+}
+
+// ReadAllRoute lists the registered routes, with a roleID each route tells
+// whether that role may call it.
+func (as *AdminService) ReadAllRoute(ctx context.Context, roleID string) ([]dto.RouteResponse, error) {
+	if roleID != "" {
+		if _, err := as.adminRepo.GetRoleByID(ctx, nil, roleID); err != nil {
+			return nil, dto.ErrRoleNotFound
+		}
+	}
+
+	routes := make([]dto.RouteResponse, 0, len(as.routes))
+	for _, route := range as.routes {
+		if roleID != "" {
+			granted, err := as.permissionService.HasAccess(ctx, roleID, route.Method, route.Path)
+			if err != nil {
+				return nil, err
+			}
+
+			route.Granted = &granted
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+// AttachPermission grants a role access to the routes matching the endpoint
+// pattern, a pattern that matches no registered route is most likely a typo
+// and is rejected.
+func (as *AdminService) AttachPermission(ctx context.Context, req dto.AttachPermissionRequest) (dto.PermissionResponse, error) {
+	method := strings.ToUpper(strings.TrimSpace(req.Method))
+	if method == "" {
+		method = entity.PermissionMethodAny
+	}
+
+	if !permissionMethods[method] {
+		return dto.PermissionResponse{}, dto.ErrInvalidPermissionMethod
+	}
+
+	endpoint := strings.TrimSpace(req.Endpoint)
+	if !strings.HasPrefix(endpoint, "/") || !as.matchesRegisteredRoute(method, endpoint) {
+		return dto.PermissionResponse{}, dto.ErrInvalidPermissionEndpoint
+	}
+
+	role, err := as.adminRepo.GetRoleByID(ctx, nil, req.RoleID)
+	if err != nil {
+		return dto.PermissionResponse{}, dto.ErrRoleNotFound
+	}
+
+	attached, err := as.adminRepo.IsPermissionAttached(ctx, nil, role.ID, endpoint, method)
+	if err != nil {
+		return dto.PermissionResponse{}, dto.ErrCreatePermission
+	}
+
+	if attached {
+		return dto.PermissionResponse{}, dto.ErrPermissionExists
+	}
+
+	permission := entity.Permission{
+		ID:       uuid.New(),
+		Endpoint: endpoint,
+		Method:   method,
+		RoleID:   &role.ID,
+	}
+
+	if err := as.adminRepo.CreatePermission(ctx, nil, permission); err != nil {
+		return dto.PermissionResponse{}, dto.ErrCreatePermission
+	}
+
+	as.permissionService.InvalidateRole(role.ID.String())
+
+	return toPermissionResponse(permission), nil
+}
+func (as *AdminService) matchesRegisteredRoute(method, endpoint string) bool {
+	// before the router registered its routes there is nothing to check against
+	if len(as.routes) == 0 {
+		return true
+	}
+
+	for _, route := range as.routes {
+		if matchMethod(method, route.Method) && matchEndpoint(endpoint, route.Path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DetachPermission removes a permission from its role. The admin role always
+// keeps a permission covering AttachPermission, otherwise no one could grant
+// access back.
+func (as *AdminService) DetachPermission(ctx context.Context, permissionID string) (dto.PermissionResponse, error) {
+	permission, flag, err := as.adminRepo.GetPermissionByID(ctx, nil, permissionID)
+	if err != nil || !flag {
+		return dto.PermissionResponse{}, dto.ErrPermissionNotFound
+	}
+
+	err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		if permission.RoleID != nil && permission.Role.Name == entity.RoleAdmin {
+			if _, flag, err := as.adminRepo.LockRoleByName(ctx, tx, entity.RoleAdmin); err != nil || !flag {
+				return dto.ErrRoleNotFound
+			}
+
+			role, flag, err := as.adminRepo.GetRoleWithPermissionsByID(ctx, tx, permission.RoleID.String())
+			if err != nil || !flag {
+				return dto.ErrRoleNotFound
+			}
+
+			keepsAccess := false
+			for _, p := range role.Permissions {
+				if p.ID != permission.ID && matchMethod(p.Method, permissionManagementMethod) && matchEndpoint(p.Endpoint, permissionManagementRoute) {
+					keepsAccess = true
+					break
+				}
+			}
+
+			if !keepsAccess {
+				return dto.ErrAdminLockout
+			}
+		}
+
+		if err := as.adminRepo.DeletePermissionByID(ctx, tx, permission.ID); err != nil {
+			return dto.ErrDeletePermission
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.PermissionResponse{}, err
+	}
+
+	if permission.RoleID != nil {
+		as.permissionService.InvalidateRole(permission.RoleID.String())
+	}
+
+	return toPermissionResponse(permission), nil
+}
+
+// AssignRole moves a user to another role. The role id is part of the user's
+// tokens, so their sessions are revoked and they have to log in again.
+func (as *AdminService) AssignRole(ctx context.Context, req dto.AssignRoleRequest) (dto.AssignRoleResponse, error) {
+	user, flag, err := as.adminRepo.GetUserByID(ctx, nil, req.UserID)
+	if err != nil || !flag {
+		return dto.AssignRoleResponse{}, dto.ErrUserNotFound
+	}
+
+	role, err := as.adminRepo.GetRoleByID(ctx, nil, req.RoleID.String())
+	if err != nil {
+		return dto.AssignRoleResponse{}, dto.ErrRoleNotFound
+	}
+
+	res := dto.AssignRoleResponse{
+		UserID: user.ID,
+		Role: dto.RoleResponse{
+			ID:   &role.ID,
+			Name: role.Name,
+		},
+	}
+
+	if user.RoleID != nil && *user.RoleID == role.ID {
+		return res, nil
+	}
+
+	err = as.adminRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		if user.Role.Name == entity.RoleAdmin && role.Name != entity.RoleAdmin {
+			count, err := as.lockedAdminCount(ctx, tx)
+			if err != nil {
+				return err
+			}
+
+			if count <= 1 {
+				return dto.ErrLastAdmin
+			}
+		}
+
+		if err := as.adminRepo.UpdateUserRole(ctx, tx, user.ID, role.ID); err != nil {
+			return dto.ErrAssignRole
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.AssignRoleResponse{}, err
+	}
+
+	if err := as.sessionService.RevokeAllUserSessions(ctx, user.ID.String(), entity.SessionRevokedRoleChanged); err != nil {
+		return dto.AssignRoleResponse{}, err
+	}
+
+	return res, nil
+}
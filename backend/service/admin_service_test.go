@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/Amierza/TitipanQ/backend/helpers"
+	"github.com/Amierza/TitipanQ/backend/internal/tenant"
+	"github.com/Amierza/TitipanQ/backend/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestCheckManageableUser(t *testing.T) {
+	scoped := tenant.WithCompanies(context.Background(), []uuid.UUID{uuid.New()})
+	unscoped := context.Background()
+
+	customAdmin := entity.Role{Name: "front_desk", Permissions: []entity.Permission{{Endpoint: "/api/v1/admin/get-all-package"}}}
+	customUser := entity.Role{Name: "resident_plus", Permissions: []entity.Permission{{Endpoint: "/api/v1/user/get-all-package"}}}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		role entity.Role
+		want error
+	}{
+		{"admin manages admin", unscoped, entity.Role{Name: entity.RoleAdmin}, nil},
+		{"admin manages company admin", unscoped, entity.Role{Name: entity.RoleCompanyAdmin}, nil},
+		{"admin manages custom admin", unscoped, customAdmin, nil},
+		{"admin manages user", unscoped, entity.Role{Name: entity.RoleUser}, nil},
+		{"company admin manages user", scoped, entity.Role{Name: entity.RoleUser}, nil},
+		{"company admin manages custom user", scoped, customUser, nil},
+		{"company admin manages admin", scoped, entity.Role{Name: entity.RoleAdmin}, dto.ErrUserNotManageable},
+		{"company admin manages company admin", scoped, entity.Role{Name: entity.RoleCompanyAdmin}, dto.ErrUserNotManageable},
+		{"company admin manages custom admin", scoped, customAdmin, dto.ErrUserNotManageable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := entity.User{Role: tt.role}
+			if err := checkManageableUser(tt.ctx, user); !errors.Is(err, tt.want) {
+				t.Fatalf("checkManageableUser() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotificationBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, notificationMaxBackoff},
+		{100, notificationMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := notificationBackoff(tt.attempts); got != tt.want {
+			t.Errorf("notificationBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestApplyDeliveryResult(t *testing.T) {
+	now := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	sendErr := errors.New("provider unavailable")
+
+	tests := []struct {
+		name            string
+		attempts        int
+		sendErr         error
+		wantStatus      entity.NotificationStatus
+		wantNextAttempt time.Time
+		wantLastError   string
+	}{
+		{"sent", 1, nil, entity.NotificationSent, time.Time{}, ""},
+		{"sent on last attempt", notificationMaxAttempts, nil, entity.NotificationSent, time.Time{}, ""},
+		{"first failure backs off", 1, sendErr, entity.NotificationFailed, now.Add(30 * time.Second), sendErr.Error()},
+		{"later failure backs off longer", 3, sendErr, entity.NotificationFailed, now.Add(2 * time.Minute), sendErr.Error()},
+		{"last failure goes dead", notificationMaxAttempts, sendErr, entity.NotificationDead, time.Time{}, sendErr.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaseUntil := now.Add(notificationLease)
+			outbox := entity.NotificationOutbox{
+				Status:     entity.NotificationSending,
+				Attempts:   tt.attempts,
+				Body:       "pickup code 123456",
+				LeaseUntil: &leaseUntil,
+				LastError:  "previous error",
+			}
+
+			applyDeliveryResult(&outbox, tt.sendErr, now)
+
+			if outbox.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", outbox.Status, tt.wantStatus)
+			}
+			if !outbox.NextAttemptAt.Equal(tt.wantNextAttempt) {
+				t.Errorf("next attempt = %v, want %v", outbox.NextAttemptAt, tt.wantNextAttempt)
+			}
+			if outbox.LastError != tt.wantLastError {
+				t.Errorf("last error = %q, want %q", outbox.LastError, tt.wantLastError)
+			}
+			if outbox.LeaseUntil != nil {
+				t.Errorf("lease = %v, want released", outbox.LeaseUntil)
+			}
+			if sent := outbox.SentAt != nil; sent != (tt.sendErr == nil) {
+				t.Errorf("sent at = %v, want set only on success", outbox.SentAt)
+			}
+			if cleared := outbox.Body == ""; cleared != (tt.sendErr == nil) {
+				t.Errorf("body = %q, want cleared only on success", outbox.Body)
+			}
+		})
+	}
+}
+
+func TestCheckPickupCode(t *testing.T) {
+	hash, err := helpers.HashPassword("123456")
+	if err != nil {
+		t.Fatalf("hash pickup code: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		hash     string
+		attempts int
+		code     string
+		want     error
+	}{
+		{"package without code", "", 0, "", nil},
+		{"correct code", hash, 0, "123456", nil},
+		{"correct code on last attempt", hash, pickupCodeMaxAttempts - 1, "123456", nil},
+		{"missing code", hash, 0, "", dto.ErrPickupCodeRequired},
+		{"wrong code", hash, 0, "654321", dto.ErrInvalidPickupCode},
+		{"locked code", hash, pickupCodeMaxAttempts, "123456", dto.ErrPickupCodeLocked},
+		{"locked without code", hash, pickupCodeMaxAttempts, "", dto.ErrPickupCodeLocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg := entity.Package{PickupCode: tt.hash, PickupAttempts: tt.attempts}
+			if err := checkPickupCode(pkg, tt.code); !errors.Is(err, tt.want) {
+				t.Fatalf("checkPickupCode() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickupCodeRejectedError(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     error
+	}{
+		{1, dto.ErrInvalidPickupCode},
+		{pickupCodeMaxAttempts - 1, dto.ErrInvalidPickupCode},
+		{pickupCodeMaxAttempts, dto.ErrPickupCodeLocked},
+		{pickupCodeMaxAttempts + 1, dto.ErrPickupCodeLocked},
+	}
+
+	for _, tt := range tests {
+		if err := pickupCodeRejectedError(tt.attempts); !errors.Is(err, tt.want) {
+			t.Errorf("pickupCodeRejectedError(%d) = %v, want %v", tt.attempts, err, tt.want)
+		}
+	}
+}
+
+// The stubs below only implement what the login paths call, anything else
+// panics on the embedded nil interface.
+type (
+	loginAdminRepo struct {
+		repository.IAdminRepository
+		user entity.User
+	}
+	loginGuardStub struct{ ILoginGuardService }
+	sessionStub    struct{ ISessionService }
+	jwtStub        struct{ IJWTService }
+)
+
+func (r loginAdminRepo) GetUserByEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error) {
+	return r.user, true, nil
+}
+func (loginGuardStub) CheckLogin(ctx context.Context, email string) error             { return nil }
+func (loginGuardStub) RecordFailure(ctx context.Context, email string, reason string) {}
+func (loginGuardStub) RecordSuccess(ctx context.Context, user entity.User)            {}
+func (sessionStub) StartSession(ctx context.Context, userID uuid.UUID) (entity.UserSession, error) {
+	return entity.UserSession{ID: uuid.New()}, nil
+}
+func (jwtStub) GenerateToken(userID string, role string, session entity.UserSession) (string, string, error) {
+	return "access", "refresh", nil
+}
+
+// loginRoles covers the built-in roles and custom roles granted either API.
+var loginRoles = map[string]entity.Role{
+	"admin":                 {Name: entity.RoleAdmin},
+	"company admin":         {Name: entity.RoleCompanyAdmin},
+	"user":                  {Name: entity.RoleUser},
+	"custom admin":          {Name: "front_desk", Permissions: []entity.Permission{{Endpoint: "/api/v1/admin/get-all-package", Method: "GET"}}},
+	"custom wildcard admin": {Name: "auditor", Permissions: []entity.Permission{{Endpoint: "/api/v1/admin/**"}}},
+	"custom user":           {Name: "resident_plus", Permissions: []entity.Permission{{Endpoint: "/api/v1/user/get-all-package"}}},
+	"custom empty":          {Name: "nobody"},
+}
+
+func loginUser(t *testing.T, role entity.Role) entity.User {
+	t.Helper()
+
+	hash, err := helpers.HashPassword("password123")
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	roleID := uuid.New()
+	verifiedAt := time.Now()
+
+	return entity.User{ID: uuid.New(), Email: "someone@example.com", Password: hash, RoleID: &roleID, Role: role, EmailVerifiedAt: &verifiedAt}
+}
+
+func TestAdminLoginByRole(t *testing.T) {
+	tests := []struct {
+		role string
+		want error
+	}{
+		{"admin", nil},
+		{"company admin", nil},
+		{"custom admin", nil},
+		{"custom wildcard admin", nil},
+		{"user", dto.ErrDeniedAccess},
+		{"custom user", dto.ErrDeniedAccess},
+		{"custom empty", dto.ErrDeniedAccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			as := NewAdminService(loginAdminRepo{user: loginUser(t, loginRoles[tt.role])}, jwtStub{}, sessionStub{}, nil, loginGuardStub{}, nil)
+
+			_, err := as.Login(context.Background(), dto.LoginRequest{Email: "someone@example.com", Password: "password123"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Login() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// slotAdminRepo serves the slots of a few lockers and the usage counted for
+// them, as the repository would across every company.
+type slotAdminRepo struct {
+	repository.IAdminRepository
+	slots []entity.LockerSlot
+	usage map[uuid.UUID]int64
+}
+
+func (r slotAdminRepo) LockLockerByID(ctx context.Context, tx *gorm.DB, lockerID string) (entity.Locker, bool, error) {
+	return entity.Locker{ID: uuid.MustParse(lockerID)}, true, nil
+}
+func (r slotAdminRepo) GetAllLockerSlot(ctx context.Context, tx *gorm.DB, lockerID string) ([]entity.LockerSlot, error) {
+	var slots []entity.LockerSlot
+	for _, slot := range r.slots {
+		if lockerID == "" || slot.LockerID.String() == lockerID {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
+func (r slotAdminRepo) GetLockerSlotUsage(ctx context.Context, tx *gorm.DB, lockerID string, excludePkgID *uuid.UUID) (map[uuid.UUID]int64, error) {
+	return r.usage, nil
+}
+
+func TestAssignSlot(t *testing.T) {
+	lockerID, otherLockerID, emptyLockerID := uuid.New(), uuid.New(), uuid.New()
+	newSlot := func(locker uuid.UUID, size entity.SlotSize, capacity int) entity.LockerSlot {
+		return entity.LockerSlot{ID: uuid.New(), Size: size, Capacity: capacity, LockerID: &locker, Locker: entity.Locker{ID: locker}}
+	}
+	small := newSlot(lockerID, entity.SmallSlot, 1)
+	medium := newSlot(lockerID, entity.MediumSlot, 2)
+	large := newSlot(lockerID, entity.LargeSlot, 1)
+	otherLarge := newSlot(otherLockerID, entity.LargeSlot, 1)
+	slots := []entity.LockerSlot{large, medium, small, otherLarge}
+
+	scoped := tenant.WithCompanies(context.Background(), []uuid.UUID{uuid.New()})
+	unscoped := context.Background()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		locker   uuid.UUID
+		size     entity.SlotSize
+		usage    map[uuid.UUID]int64
+		want     *uuid.UUID
+		wantFull bool
+	}{
+		{"smallest fitting slot", unscoped, lockerID, entity.SmallSlot, nil, &small.ID, false},
+		{"medium package skips small slot", unscoped, lockerID, entity.MediumSlot, nil, &medium.ID, false},
+		{"full slot is skipped", unscoped, lockerID, entity.SmallSlot, map[uuid.UUID]int64{small.ID: 1}, &medium.ID, false},
+		{"slot below capacity is kept", unscoped, lockerID, entity.SmallSlot, map[uuid.UUID]int64{small.ID: 1, medium.ID: 1}, &medium.ID, false},
+		{"slot at capacity is skipped", unscoped, lockerID, entity.SmallSlot, map[uuid.UUID]int64{small.ID: 1, medium.ID: 2}, &large.ID, false},
+		{"slot filled by other companies is skipped", scoped, lockerID, entity.SmallSlot, map[uuid.UUID]int64{small.ID: 1}, &medium.ID, false},
+		{"scoped admin gets a free slot", scoped, lockerID, entity.LargeSlot, nil, &large.ID, false},
+		{"full locker", unscoped, lockerID, entity.LargeSlot, map[uuid.UUID]int64{large.ID: 1}, nil, true},
+		{"locker without slots", unscoped, emptyLockerID, entity.SmallSlot, nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := NewAdminService(slotAdminRepo{slots: slots, usage: tt.usage}, nil, nil, nil, nil, nil)
+
+			slot, err := as.assignSlot(tt.ctx, nil, tt.locker, tt.size, nil)
+			if tt.wantFull {
+				var full *dto.LockerFullError
+				if !errors.As(err, &full) {
+					t.Fatalf("assignSlot() error = %v, want a full locker", err)
+				}
+				if len(full.Alternatives) != 1 || full.Alternatives[0].ID != otherLockerID {
+					t.Fatalf("alternatives = %+v, want only the other locker", full.Alternatives)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("assignSlot() error = %v", err)
+			}
+
+			switch {
+			case tt.want == nil && slot != nil:
+				t.Fatalf("assignSlot() = %v, want no slot", slot.ID)
+			case tt.want != nil && slot == nil:
+				t.Fatalf("assignSlot() = no slot, want %v", *tt.want)
+			case tt.want != nil && slot.ID != *tt.want:
+				t.Fatalf("assignSlot() = %v, want %v", slot.ID, *tt.want)
+			}
+		})
+	}
+}
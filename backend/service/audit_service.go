@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/Amierza/TitipanQ/backend/repository"
+	"github.com/google/uuid"
+)
+
+type (
+	// auditEntity is a resource whose rows are snapshotted, IDKey is the field
+	// holding its id in the data of a create response.
+	auditEntity struct {
+		model func() any
+		idKey string
+	}
+
+	// auditRoute overrides what a route name says, for routes acting on
+	// another resource than the one they are named after.
+	auditRoute struct {
+		action     string
+		entityType string
+	}
+
+	IAuditService interface {
+		Target(fullPath string) (action string, entityType string)
+		Snapshot(ctx context.Context, entityType, entityID string) entity.AuditSnapshot
+		CreatedID(entityType string, responseBody []byte) string
+		Record(ctx context.Context, auditLog entity.AuditLog)
+		ReadAllAuditLog(ctx context.Context, req dto.PaginationRequest, filter dto.AuditLogFilter) (dto.AuditLogPaginationResponse, error)
+		GetDetailAuditLog(ctx context.Context, auditID string) (dto.AuditLogResponse, error)
+	}
+
+	AuditService struct {
+		auditLogRepo repository.IAuditLogRepository
+	}
+)
+
+var (
+	auditEntities = map[string]auditEntity{
+		"user":             {func() any { return &entity.User{} }, "user_id"},
+		"role":             {func() any { return &entity.Role{} }, "role_id"},
+		"permission":       {func() any { return &entity.Permission{} }, "permission_id"},
+		"package":          {func() any { return &entity.Package{} }, "package_id"},
+		"company":          {func() any { return &entity.Company{} }, "company_id"},
+		"locker":           {func() any { return &entity.Locker{} }, "locker_id"},
+		"locker_slot":      {func() any { return &entity.LockerSlot{} }, "slot_id"},
+		"sender":           {func() any { return &entity.Sender{} }, "sender_id"},
+		"notification":     {func() any { return &entity.NotificationOutbox{} }, "notification_id"},
+		"retention_policy": {func() any { return &entity.RetentionPolicy{} }, "retention_policy_id"},
+	}
+
+	auditRoutes = map[string]auditRoute{
+		"logout":                  {"logout", "session"},
+		"logout-all":              {"logout_all", "session"},
+		"assign-role":             {"assign_role", "user"},
+		"attach-permission":       {"attach_permission", "role"},
+		"update-status-packages":  {"update_status", "package"},
+		"update-company-contacts": {"update_contacts", "company"},
+		"regenerate-pickup-code":  {"regenerate_pickup_code", "package"},
+		"trigger-expire-packages": {"trigger_expire", "package"},
+	}
+
+	// columns never copied into a snapshot, search_vector is derived data
+	auditRedactedColumns = []string{"password", "pickup_code", "code_hash", "token_hash", "search_vector"}
+)
+
+func NewAuditService(auditLogRepo repository.IAuditLogRepository) *AuditService {
+	return &AuditService{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// Target reads the action and entity type from the route name, e.g.
+// `/api/v1/admin/update-locker-slot/:id` is an update of a locker_slot.
+func (as *AuditService) Target(fullPath string) (string, string) {
+	var name string
+	for _, segment := range strings.Split(fullPath, "/") {
+		if segment != "" && !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			name = segment
+		}
+	}
+
+	if route, ok := auditRoutes[name]; ok {
+		return route.action, route.entityType
+	}
+
+	action, entityType, _ := strings.Cut(name, "-")
+
+	return action, strings.ReplaceAll(entityType, "-", "_")
+}
+
+// Snapshot returns the current row of the entity, nil when the entity type is
+// not snapshotted or the row does not exist.
+func (as *AuditService) Snapshot(ctx context.Context, entityType, entityID string) entity.AuditSnapshot {
+	target, ok := auditEntities[entityType]
+	if !ok || entityID == "" {
+		return nil
+	}
+
+	if _, err := uuid.Parse(entityID); err != nil {
+		return nil
+	}
+
+	row, found, err := as.auditLogRepo.GetSnapshot(ctx, nil, target.model(), entityID)
+	if err != nil || !found {
+		return nil
+	}
+
+	for _, column := range auditRedactedColumns {
+		delete(row, column)
+	}
+
+	snapshot := entity.AuditSnapshot{}
+	for column, value := range row {
+		// jsonb and bytea columns come back as bytes
+		if b, ok := value.([]byte); ok {
+			value = string(b)
+		}
+		snapshot[column] = value
+	}
+
+	return snapshot
+}
+
+// CreatedID picks the id of a created row out of the handler's response.
+func (as *AuditService) CreatedID(entityType string, responseBody []byte) string {
+	target, ok := auditEntities[entityType]
+	if !ok {
+		return ""
+	}
+
+	var res struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(responseBody, &res); err != nil {
+		return ""
+	}
+
+	id, _ := res.Data[target.idKey].(string)
+
+	return id
+}
+
+// Record stores the audit log with the actor and request metadata of ctx.
+// Errors are logged, the request it describes already succeeded.
+func (as *AuditService) Record(ctx context.Context, auditLog entity.AuditLog) {
+	auditLog.ID = uuid.New()
+	auditLog.IPAddress, auditLog.UserAgent = requestMeta(ctx)
+
+	if userID, ok := ctx.Value("user_id").(string); ok {
+		if id, err := uuid.Parse(userID); err == nil {
+			auditLog.ActorID = &id
+		}
+	}
+
+	if roleID, ok := ctx.Value("role_id").(string); ok && roleID != "" {
+		auditLog.ActorRole, _ = as.auditLogRepo.GetRoleNameByID(ctx, nil, roleID)
+	}
+
+	if err := as.auditLogRepo.CreateAuditLog(ctx, nil, auditLog); err != nil {
+		log.Printf("Failed to record audit log for %s %s: %v", auditLog.Method, auditLog.Path, err)
+	}
+}
+
+func (as *AuditService) ReadAllAuditLog(ctx context.Context, req dto.PaginationRequest, filter dto.AuditLogFilter) (dto.AuditLogPaginationResponse, error) {
+	dataWithPaginate, err := as.auditLogRepo.GetAllAuditLogWithPagination(ctx, nil, req, filter)
+	if err != nil {
+		return dto.AuditLogPaginationResponse{}, dto.ErrGetAllAuditLogWithPagination
+	}
+
+	datas := []dto.AuditLogResponse{}
+	for _, auditLog := range dataWithPaginate.AuditLogs {
+		datas = append(datas, toAuditLogResponse(auditLog))
+	}
+
+	return dto.AuditLogPaginationResponse{
+		Data: datas,
+		PaginationResponse: dto.PaginationResponse{
+			Page:    dataWithPaginate.Page,
+			PerPage: dataWithPaginate.PerPage,
+			MaxPage: dataWithPaginate.MaxPage,
+			Count:   dataWithPaginate.Count,
+		},
+	}, nil
+}
+
+func (as *AuditService) GetDetailAuditLog(ctx context.Context, auditID string) (dto.AuditLogResponse, error) {
+	if _, err := uuid.Parse(auditID); err != nil {
+		return dto.AuditLogResponse{}, dto.ErrParseUUID
+	}
+
+	auditLog, found, err := as.auditLogRepo.GetAuditLogByID(ctx, nil, auditID)
+	if err != nil || !found {
+		return dto.AuditLogResponse{}, dto.ErrAuditLogNotFound
+	}
+
+	return toAuditLogResponse(auditLog), nil
+}
+
+func toAuditLogResponse(auditLog entity.AuditLog) dto.AuditLogResponse {
+	res := dto.AuditLogResponse{
+		ID:         auditLog.ID,
+		Action:     auditLog.Action,
+		EntityType: auditLog.EntityType,
+		EntityID:   auditLog.EntityID,
+		Method:     auditLog.Method,
+		Path:       auditLog.Path,
+		StatusCode: auditLog.StatusCode,
+		Before:     auditLog.Before,
+		After:      auditLog.After,
+		ActorRole:  auditLog.ActorRole,
+		IPAddress:  auditLog.IPAddress,
+		UserAgent:  auditLog.UserAgent,
+		CreatedAt:  auditLog.CreatedAt,
+	}
+
+	if auditLog.ActorID != nil {
+		res.Actor = &dto.UserResponseCustom{
+			ID:    auditLog.Actor.ID,
+			Name:  auditLog.Actor.Name,
+			Email: auditLog.Actor.Email,
+		}
+	}
+
+	return res
+}
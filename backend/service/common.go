@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/google/uuid"
+)
+
+func joinNotificationChannels(channels []entity.NotificationChannel) (string, error) {
+	var values []string
+	for _, channel := range channels {
+		if !entity.IsValidNotificationChannel(channel) {
+			return "", dto.ErrInvalidNotificationChannel
+		}
+		values = append(values, string(channel))
+	}
+
+	return strings.Join(values, ","), nil
+}
+
+func toPickupDelegationResponse(delegation entity.PickupDelegation, now time.Time) dto.PickupDelegationResponse {
+	pkgIDs := []uuid.UUID{}
+	for _, pkg := range delegation.Packages {
+		pkgIDs = append(pkgIDs, pkg.ID)
+	}
+
+	return dto.PickupDelegationResponse{
+		ID:          delegation.ID,
+		Name:        delegation.Name,
+		PhoneNumber: delegation.PhoneNumber,
+		ValidFrom:   delegation.ValidFrom,
+		ValidUntil:  delegation.ValidUntil,
+		AllPackages: delegation.AllPackages,
+		PackageIDs:  pkgIDs,
+		RevokedAt:   delegation.RevokedAt,
+		Active:      delegation.IsActive(now),
+		UserID:      delegation.UserID,
+	}
+}
+
+// toPackageCompanyResponse returns the company a package is addressed to, nil
+// for packages addressed to a single user.
+func toPackageCompanyResponse(pkg entity.Package) *dto.CompanyResponse {
+	if pkg.CompanyID == nil {
+		return nil
+	}
+
+	return &dto.CompanyResponse{
+		ID:      pkg.CompanyID,
+		Name:    pkg.Company.Name,
+		Address: pkg.Company.Address,
+	}
+}
+
+// toUserListResponse maps a user the way the user lists show it.
+func toUserListResponse(user entity.User) dto.UserResponse {
+	var companies []dto.CompanyResponse
+	for _, uc := range user.UserCompanies {
+		companies = append(companies, dto.CompanyResponse{
+			ID:      &uc.Company.ID,
+			Name:    uc.Company.Name,
+			Address: uc.Company.Address,
+		})
+	}
+
+	return dto.UserResponse{
+		ID:          user.ID,
+		Name:        user.Name,
+		Email:       user.Email,
+		Password:    user.Password,
+		PhoneNumber: user.PhoneNumber,
+		Address:     user.Address,
+		Companies:   companies,
+		Role: dto.RoleResponse{
+			ID:   user.RoleID,
+			Name: user.Role.Name,
+		},
+	}
+}
+
+// toPackageListResponse maps a package the way the package lists show it.
+func toPackageListResponse(pkg entity.Package) dto.PackageResponse {
+	return dto.PackageResponse{
+		ID:           pkg.ID,
+		TrackingCode: pkg.TrackingCode,
+		Description:  pkg.Description,
+		Image:        pkg.Image,
+		Type:         pkg.Type,
+		Status:       pkg.Status,
+		Quantity:     pkg.Quantity,
+		CompletedAt:  pkg.CompletedAt,
+		ExpiredAt:    pkg.ExpiredAt,
+		Sender: dto.SenderResponse{
+			ID:          pkg.Sender.ID,
+			Name:        pkg.Sender.Name,
+			Address:     pkg.Sender.Address,
+			PhoneNumber: pkg.Sender.PhoneNumber,
+		},
+		Locker: dto.LockerResponse{
+			ID:         pkg.Locker.ID,
+			LockerCode: pkg.Locker.LockerCode,
+			Location:   pkg.Locker.Location,
+		},
+		Size:    pkg.Size,
+		Slot:    toPackageSlotResponse(packageSlot(pkg)),
+		Company: toPackageCompanyResponse(pkg),
+		User:    toUserListResponse(pkg.User),
+		TimeStamp: entity.TimeStamp{
+			CreatedAt: pkg.CreatedAt,
+			UpdatedAt: pkg.UpdatedAt,
+			DeletedAt: pkg.DeletedAt,
+		},
+	}
+}
+
+func toPackageHistoryResponse(history entity.PackageHistory, withRequestMeta bool) dto.PackageHistoryResponse {
+	res := dto.PackageHistoryResponse{
+		ID:          history.ID,
+		Status:      history.Status,
+		Description: history.Description,
+		ChangedBy: dto.UserResponseCustom{
+			ID:    history.ChangedByUser.ID,
+			Name:  history.ChangedByUser.Name,
+			Email: history.ChangedByUser.Email,
+		},
+		CollectedBy: history.CollectedBy,
+		CreatedAt:   history.CreatedAt,
+		EventType:   history.EventType,
+		Changes:     history.Changes,
+		ActorRole:   history.ActorRole,
+		Summary:     historySummary(history),
+	}
+
+	if withRequestMeta {
+		res.IPAddress = history.IPAddress
+		res.UserAgent = history.UserAgent
+	}
+
+	return res
+}
+
+// historySummary renders one history entry as a single readable timeline line,
+// e.g. `15:04 Budi (admin) package_updated: package_quantity 1 → 2`.
+func historySummary(history entity.PackageHistory) string {
+	actor := history.ChangedByUser.Name
+	switch {
+	case actor != "" && history.ActorRole != "":
+		actor = fmt.Sprintf("%s (%s)", actor, history.ActorRole)
+	case actor == "" && history.ActorRole != "":
+		actor = history.ActorRole
+	case actor == "":
+		actor = entity.SystemActor
+	}
+
+	summary := fmt.Sprintf("%s %s %s", history.CreatedAt.Format("15:04"), actor, history.EventType)
+	if len(history.Changes) == 0 {
+		return summary + ": " + history.Description
+	}
+
+	return summary + ": " + historyChanges(history.Changes)
+}
+
+// historyChanges renders field diffs as `field old → new, ...`.
+func historyChanges(changes entity.HistoryChanges) string {
+	var fields []string
+	for _, change := range changes {
+		fields = append(fields, fmt.Sprintf("%s %s → %s", change.Field, historyValue(change.Old), historyValue(change.New)))
+	}
+
+	return strings.Join(fields, ", ")
+}
+
+func historyValue(value any) string {
+	if value == nil {
+		return "-"
+	}
+
+	if s, ok := value.(string); ok {
+		if s == "" {
+			return "-"
+		}
+		return fmt.Sprintf("%q", s)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// buildPackageTimeline groups history entries per day, oldest first.
+func buildPackageTimeline(histories []dto.PackageHistoryResponse) []dto.PackageTimelineDay {
+	sort.SliceStable(histories, func(i, j int) bool {
+		return histories[i].CreatedAt.Before(histories[j].CreatedAt)
+	})
+
+	timeline := []dto.PackageTimelineDay{}
+	for _, history := range histories {
+		date := history.CreatedAt.Format("2006-01-02")
+		if len(timeline) == 0 || timeline[len(timeline)-1].Date != date {
+			timeline = append(timeline, dto.PackageTimelineDay{Date: date})
+		}
+
+		day := &timeline[len(timeline)-1]
+		day.Events = append(day.Events, history)
+	}
+
+	return timeline
+}
+
+// requestMeta returns the client IP and user agent set by the RequestMeta
+// middleware, empty outside of a request.
+func requestMeta(ctx context.Context) (string, string) {
+	ip, _ := ctx.Value("client_ip").(string)
+	userAgent, _ := ctx.Value("user_agent").(string)
+
+	return ip, userAgent
+}
@@ -6,21 +6,34 @@ import (
 	"time"
 
 	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	AccessTokenTTL  = 5 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
+
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+)
+
 type (
 	IJWTService interface {
-		GenerateToken(userID string, role string, permissions []string) (string, string, error)
+		GenerateToken(userID string, role string, session entity.UserSession) (string, string, error)
 		ValidateToken(token string) (*jwt.Token, error)
 		GetUserIDByToken(tokenString string) (string, error)
 		GetRoleIDByToken(tokenString string) (string, error)
+		GetSessionIDByToken(tokenString string) (string, error)
+		GetRefreshIDByToken(tokenString string) (string, error)
+		IsAccessToken(tokenString string) bool
 	}
 
 	jwtCustomClaim struct {
-		UserID      string   `json:"user_id"`
-		RoleID      string   `json:"role_id"`
-		Permissions []string `json:"endpoints"`
+		UserID    string `json:"user_id"`
+		RoleID    string `json:"role_id"`
+		SessionID string `json:"session_id"`
+		TokenType string `json:"token_type"`
 		jwt.RegisteredClaims
 	}
 
@@ -46,13 +59,16 @@ func getSecretKey() string {
 	return secretKey
 }
 
-func (j *JWTService) GenerateToken(userID string, roleID string, endpoints []string) (string, string, error) {
+// GenerateToken issues an access token and a refresh token bound to session,
+// the refresh token id is session.RefreshID and it expires with the session.
+func (j *JWTService) GenerateToken(userID string, roleID string, session entity.UserSession) (string, string, error) {
 	accessClaims := jwtCustomClaim{
 		userID,
 		roleID,
-		endpoints,
+		session.ID.String(),
+		accessTokenType,
 		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Second * 300)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			Issuer:    j.issuer,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -67,9 +83,11 @@ func (j *JWTService) GenerateToken(userID string, roleID string, endpoints []str
 	refreshClaims := jwtCustomClaim{
 		userID,
 		roleID,
-		endpoints,
+		session.ID.String(),
+		refreshTokenType,
 		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Second * 3600 * 24 * 7)),
+			ID:        session.RefreshID.String(),
+			ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
 			Issuer:    j.issuer,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -132,3 +150,60 @@ func (j *JWTService) GetRoleIDByToken(tokenString string) (string, error) {
 
 	return roleID, nil
 }
+
+func (j *JWTService) getClaims(tokenString string) (jwt.MapClaims, error) {
+	token, err := j.ValidateToken(tokenString)
+	if err != nil {
+		return nil, dto.ErrValidateToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, dto.ErrTokenInvalid
+	}
+
+	return claims, nil
+}
+
+func (j *JWTService) GetSessionIDByToken(tokenString string) (string, error) {
+	claims, err := j.getClaims(tokenString)
+	if err != nil {
+		return "", err
+	}
+
+	sessionID, ok := claims["session_id"].(string)
+	if !ok || sessionID == "" {
+		return "", dto.ErrTokenInvalid
+	}
+
+	return sessionID, nil
+}
+
+// GetRefreshIDByToken returns the jti of a refresh token, access tokens are
+// rejected so they cannot be exchanged for new tokens.
+func (j *JWTService) GetRefreshIDByToken(tokenString string) (string, error) {
+	claims, err := j.getClaims(tokenString)
+	if err != nil {
+		return "", err
+	}
+
+	if claims["token_type"] != refreshTokenType {
+		return "", dto.ErrNotRefreshToken
+	}
+
+	refreshID, ok := claims["jti"].(string)
+	if !ok || refreshID == "" {
+		return "", dto.ErrTokenInvalid
+	}
+
+	return refreshID, nil
+}
+
+func (j *JWTService) IsAccessToken(tokenString string) bool {
+	claims, err := j.getClaims(tokenString)
+	if err != nil {
+		return false
+	}
+
+	return claims["token_type"] == accessTokenType
+}
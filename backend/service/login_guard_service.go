@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/Amierza/TitipanQ/backend/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// wrong passwords an account may have before each next attempt is delayed,
+	// the delay doubles from one second until the account is locked
+	loginDelayAfter      = 3
+	loginLockoutAfter    = 10
+	loginLockoutDuration = 15 * time.Minute
+
+	// failed logins from one IP address, over all accounts, within the window
+	loginIPWindow = 15 * time.Minute
+	loginIPLimit  = 50
+
+	loginAttemptRetention = 90 * 24 * time.Hour
+)
+
+type (
+	ILoginGuardService interface {
+		CheckLogin(ctx context.Context, email string) error
+		RecordFailure(ctx context.Context, email string, reason string)
+		RecordSuccess(ctx context.Context, user entity.User)
+		UnlockUser(ctx context.Context, userID uuid.UUID) error
+		PurgeLoginAttempts(ctx context.Context) (int64, error)
+	}
+
+	LoginGuardService struct {
+		loginAttemptRepo repository.ILoginAttemptRepository
+	}
+)
+
+func NewLoginGuardService(loginAttemptRepo repository.ILoginAttemptRepository) *LoginGuardService {
+	return &LoginGuardService{
+		loginAttemptRepo: loginAttemptRepo,
+	}
+}
+
+// loginDelay is how long an account has to wait after its failed-th wrong
+// password in a row.
+func loginDelay(failed int) time.Duration {
+	switch {
+	case failed >= loginLockoutAfter:
+		return loginLockoutDuration
+	case failed >= loginDelayAfter:
+		return time.Second << (failed - loginDelayAfter)
+	default:
+		return 0
+	}
+}
+
+// CheckLogin runs before the password is looked at. Blocked attempts are
+// recorded as failures too, so hammering a locked account keeps the IP
+// address counted.
+func (ls *LoginGuardService) CheckLogin(ctx context.Context, email string) error {
+	now := time.Now()
+	ip, _ := requestMeta(ctx)
+
+	if ip != "" {
+		failed, err := ls.loginAttemptRepo.CountFailedLoginAttemptsByIP(ctx, nil, ip, now.Add(-loginIPWindow))
+		if err == nil && failed >= loginIPLimit {
+			ls.RecordFailure(ctx, email, entity.LoginAttemptIPBlocked)
+			return dto.ErrTooManyLoginAttempts
+		}
+	}
+
+	user, found, _ := ls.loginAttemptRepo.GetUserByEmail(ctx, nil, email)
+	if found && user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		ls.RecordFailure(ctx, email, entity.LoginAttemptLocked)
+		return dto.ErrAccountLocked
+	}
+
+	return nil
+}
+
+// RecordFailure stores a failed login. Only a wrong password counts against
+// the account, the other reasons count against the IP address alone. Errors
+// are logged, a login is never refused because its attempt was not saved.
+func (ls *LoginGuardService) RecordFailure(ctx context.Context, email string, reason string) {
+	now := time.Now()
+	ip, userAgent := requestMeta(ctx)
+
+	err := ls.loginAttemptRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		attempt := entity.LoginAttempt{
+			ID:        uuid.New(),
+			Email:     email,
+			Reason:    reason,
+			IPAddress: ip,
+			UserAgent: userAgent,
+		}
+
+		user, found, _ := ls.loginAttemptRepo.LockUserByEmail(ctx, tx, email)
+		if found {
+			attempt.UserID = &user.ID
+		}
+
+		if err := ls.loginAttemptRepo.CreateLoginAttempt(ctx, tx, attempt); err != nil {
+			return err
+		}
+
+		if !found || reason != entity.LoginAttemptPasswordNotMatch {
+			return nil
+		}
+
+		failed := user.FailedLoginAttempts + 1
+		var lockedUntil *time.Time
+		if delay := loginDelay(failed); delay > 0 {
+			until := now.Add(delay)
+			lockedUntil = &until
+		}
+
+		return ls.loginAttemptRepo.UpdateUserLoginFailures(ctx, tx, user.ID, failed, lockedUntil)
+	})
+	if err != nil {
+		log.Printf("Failed to record login failure for %s: %v", email, err)
+	}
+}
+
+// RecordSuccess stores a successful login and clears the account's failures.
+func (ls *LoginGuardService) RecordSuccess(ctx context.Context, user entity.User) {
+	ip, userAgent := requestMeta(ctx)
+
+	err := ls.loginAttemptRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		if err := ls.loginAttemptRepo.CreateLoginAttempt(ctx, tx, entity.LoginAttempt{
+			ID:        uuid.New(),
+			Email:     user.Email,
+			Success:   true,
+			Reason:    entity.LoginAttemptSuccess,
+			IPAddress: ip,
+			UserAgent: userAgent,
+			UserID:    &user.ID,
+		}); err != nil {
+			return err
+		}
+
+		if user.FailedLoginAttempts == 0 && user.LockedUntil == nil {
+			return nil
+		}
+
+		return ls.loginAttemptRepo.UpdateUserLoginFailures(ctx, tx, user.ID, 0, nil)
+	})
+	if err != nil {
+		log.Printf("Failed to record login of %s: %v", user.Email, err)
+	}
+}
+
+func (ls *LoginGuardService) UnlockUser(ctx context.Context, userID uuid.UUID) error {
+	if err := ls.loginAttemptRepo.UpdateUserLoginFailures(ctx, nil, userID, 0, nil); err != nil {
+		return dto.ErrUnlockUser
+	}
+
+	return nil
+}
+
+func (ls *LoginGuardService) PurgeLoginAttempts(ctx context.Context) (int64, error) {
+	return ls.loginAttemptRepo.DeleteLoginAttemptsBefore(ctx, nil, time.Now().Add(-loginAttemptRetention))
+}
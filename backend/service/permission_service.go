@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/Amierza/TitipanQ/backend/internal/cache"
+	"github.com/Amierza/TitipanQ/backend/repository"
+	"github.com/google/uuid"
+)
+
+// permissionDefaultCacheTTL bounds how stale a role's permissions can be when
+// a change is made outside this process (e.g. straight in the database).
+const permissionDefaultCacheTTL = 5 * time.Minute
+
+type (
+	IPermissionService interface {
+		HasAccess(ctx context.Context, roleID, method, route string) (bool, error)
+		InvalidateRole(roleID string)
+		InvalidateAll()
+		CompanyScope(ctx context.Context, userID, roleID string) ([]uuid.UUID, bool, error)
+	}
+
+	PermissionService struct {
+		permissionRepo repository.IPermissionRepository
+		cache          *cache.TTL
+	}
+)
+
+func NewPermissionService(permissionRepo repository.IPermissionRepository) *PermissionService {
+	ttl := permissionDefaultCacheTTL
+	if v, err := time.ParseDuration(os.Getenv("PERMISSION_CACHE_TTL")); err == nil {
+		ttl = v
+	}
+
+	return &PermissionService{
+		permissionRepo: permissionRepo,
+		cache:          cache.NewTTL(ttl),
+	}
+}
+
+// HasAccess reports whether roleID may call route (the gin FullPath) with
+// method, permissions are loaded per role and cached until invalidated.
+func (ps *PermissionService) HasAccess(ctx context.Context, roleID, method, route string) (bool, error) {
+	permissions, err := ps.rolePermissions(ctx, roleID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, permission := range permissions {
+		if matchMethod(permission.Method, method) && matchEndpoint(permission.Endpoint, route) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// InvalidateRole drops the cached permissions of roleID, call it after the
+// role's permissions change.
+func (ps *PermissionService) InvalidateRole(roleID string) {
+	ps.cache.Delete(roleID)
+}
+
+func (ps *PermissionService) InvalidateAll() {
+	ps.cache.Clear()
+}
+
+// CompanyScope returns the companies a role is limited to, only the built-in
+// admin sees every company. Custom roles are limited like company admins.
+func (ps *PermissionService) CompanyScope(ctx context.Context, userID, roleID string) ([]uuid.UUID, bool, error) {
+	role, found, err := ps.permissionRepo.GetRoleByID(ctx, nil, roleID)
+	if err != nil || !found {
+		return nil, false, dto.ErrRoleNotFound
+	}
+
+	if role.Name == entity.RoleAdmin {
+		return nil, false, nil
+	}
+
+	companyIDs, err := ps.permissionRepo.GetCompanyIDsByUserID(ctx, nil, userID)
+	if err != nil {
+		return nil, false, dto.ErrGetCompanyByUserID
+	}
+
+	return companyIDs, true, nil
+}
+
+func (ps *PermissionService) rolePermissions(ctx context.Context, roleID string) ([]entity.Permission, error) {
+	if cached, ok := ps.cache.Get(roleID); ok {
+		return cached.([]entity.Permission), nil
+	}
+
+	permissions, err := ps.permissionRepo.GetPermissionsByRoleID(ctx, nil, roleID)
+	if err != nil {
+		return nil, dto.ErrGetPermissionsByRoleID
+	}
+
+	ps.cache.Set(roleID, permissions)
+
+	return permissions, nil
+}
+
+func matchMethod(allowed, method string) bool {
+	return allowed == "" || allowed == entity.PermissionMethodAny || strings.EqualFold(allowed, method)
+}
+
+// matchEndpoint matches a route against a permission pattern segment by
+// segment, see entity.Permission for the supported wildcards.
+func matchEndpoint(pattern, route string) bool {
+	if pattern == route {
+		return true
+	}
+
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	routeParts := strings.Split(strings.Trim(route, "/"), "/")
+
+	for i, part := range patternParts {
+		if part == "**" && i == len(patternParts)-1 {
+			return len(routeParts) > i
+		}
+
+		if i >= len(routeParts) {
+			return false
+		}
+
+		if part == routeParts[i] {
+			continue
+		}
+
+		ok, err := path.Match(part, routeParts[i])
+		if err != nil || !ok {
+			return false
+		}
+	}
+
+	return len(patternParts) == len(routeParts)
+}
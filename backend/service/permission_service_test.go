@@ -0,0 +1,56 @@
+package service
+
+import "testing"
+
+func TestMatchEndpoint(t *testing.T) {
+	tests := []struct {
+		pattern string
+		route   string
+		want    bool
+	}{
+		{"/api/v1/admin/get-all-package", "/api/v1/admin/get-all-package", true},
+		{"/api/v1/admin/get-all-package", "/api/v1/admin/get-all-user", false},
+		{"/api/v1/admin/get-all-package", "/api/v1/admin/get-all-package/:id", false},
+		{"/api/v1/admin/*", "/api/v1/admin/get-all-package", true},
+		{"/api/v1/admin/*", "/api/v1/admin/get-detail-package/:id", false},
+		{"/api/v1/admin/*", "/api/v1/admin", false},
+		{"/api/v1/admin/*-package", "/api/v1/admin/create-package", true},
+		{"/api/v1/admin/*-package", "/api/v1/admin/create-user", false},
+		{"/api/v1/*/get-all-package", "/api/v1/user/get-all-package", true},
+		{"/api/v1/admin/**", "/api/v1/admin/get-all-package", true},
+		{"/api/v1/admin/**", "/api/v1/admin/get-detail-package/:id", true},
+		{"/api/v1/admin/**", "/api/v1/admin", false},
+		{"/api/v1/admin/**", "/api/v1/user/get-all-package", false},
+		{"/api/v1/**/get-all-package", "/api/v1/admin/get-all-package", true},
+		{"/api/v1/**/get-all-package", "/api/v1/admin/company/get-all-package", false},
+		{"/api/v1/admin/[", "/api/v1/admin/[", true},
+		{"/api/v1/admin/[*", "/api/v1/admin/create-package", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchEndpoint(tt.pattern, tt.route); got != tt.want {
+			t.Errorf("matchEndpoint(%q, %q) = %v, want %v", tt.pattern, tt.route, got, tt.want)
+		}
+	}
+}
+
+func TestMatchMethod(t *testing.T) {
+	tests := []struct {
+		allowed string
+		method  string
+		want    bool
+	}{
+		{"", "GET", true},
+		{"*", "DELETE", true},
+		{"GET", "GET", true},
+		{"get", "GET", true},
+		{"GET", "POST", false},
+		{"PATCH", "PUT", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchMethod(tt.allowed, tt.method); got != tt.want {
+			t.Errorf("matchMethod(%q, %q) = %v, want %v", tt.allowed, tt.method, got, tt.want)
+		}
+	}
+}
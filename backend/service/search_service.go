@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/repository"
+)
+
+const (
+	searchMinLength    = 2
+	searchDefaultLimit = 20
+	searchMaxLimit     = 50
+	// phone numbers are searched once the term has this many digits
+	searchPhoneMinDigits = 4
+)
+
+type (
+	ISearchService interface {
+		Search(ctx context.Context, req dto.SearchRequest) (dto.SearchResponse, error)
+	}
+
+	SearchService struct {
+		searchRepo repository.ISearchRepository
+	}
+)
+
+func NewSearchService(searchRepo repository.ISearchRepository) *SearchService {
+	return &SearchService{
+		searchRepo: searchRepo,
+	}
+}
+
+// Search runs the term against every requested type and merges the results
+// by rank, the best req.Limit of them are returned.
+func (ss *SearchService) Search(ctx context.Context, req dto.SearchRequest) (dto.SearchResponse, error) {
+	term := strings.TrimSpace(req.Query)
+	if len([]rune(term)) < searchMinLength {
+		return dto.SearchResponse{}, dto.ErrSearchQueryTooShort
+	}
+
+	searchers := map[string]func(context.Context, dto.SearchQuery) ([]dto.SearchResult, error){
+		"package": func(ctx context.Context, q dto.SearchQuery) ([]dto.SearchResult, error) {
+			return ss.searchRepo.SearchPackages(ctx, nil, q)
+		},
+		"user": func(ctx context.Context, q dto.SearchQuery) ([]dto.SearchResult, error) {
+			return ss.searchRepo.SearchUsers(ctx, nil, q)
+		},
+		"sender": func(ctx context.Context, q dto.SearchQuery) ([]dto.SearchResult, error) {
+			return ss.searchRepo.SearchSenders(ctx, nil, q)
+		},
+		"company": func(ctx context.Context, q dto.SearchQuery) ([]dto.SearchResult, error) {
+			return ss.searchRepo.SearchCompanies(ctx, nil, q)
+		},
+	}
+
+	types := req.Types
+	if len(types) == 0 {
+		types = []string{"package", "user", "sender", "company"}
+	}
+	for _, t := range types {
+		if _, ok := searchers[t]; !ok {
+			return dto.SearchResponse{}, dto.ErrInvalidSearchType
+		}
+	}
+
+	query := dto.SearchQuery{
+		Term:         term,
+		TSQuery:      searchTSQuery(term),
+		Pattern:      "%" + escapeLike(term) + "%",
+		PhonePattern: searchPhonePattern(term),
+		Limit:        req.Limit,
+	}
+	if query.Limit <= 0 {
+		query.Limit = searchDefaultLimit
+	}
+	query.Limit = min(query.Limit, searchMaxLimit)
+
+	results := []dto.SearchResult{}
+	for _, t := range types {
+		found, err := searchers[t](ctx, query)
+		if err != nil {
+			return dto.SearchResponse{}, dto.ErrSearch
+		}
+
+		results = append(results, found...)
+	}
+	slices.SortStableFunc(results, func(a, b dto.SearchResult) int {
+		switch {
+		case a.Rank > b.Rank:
+			return -1
+		case a.Rank < b.Rank:
+			return 1
+		}
+		return 0
+	})
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return dto.SearchResponse{
+		Query:   term,
+		Results: results,
+	}, nil
+}
+
+// searchTSQuery turns a free text term into a to_tsquery input where every
+// word must match as a prefix, e.g. `pt maju` becomes `pt:* & maju:*`.
+// Only letters and digits are kept so the input can never be malformed.
+func searchTSQuery(term string) string {
+	words := strings.FieldsFunc(strings.ToLower(term), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	if len(words) == 0 {
+		// still a valid query, it simply matches nothing
+		return "''"
+	}
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+// searchPhonePattern matches a phone number typed in part, numbers are stored
+// as 62... so a leading 0 is rewritten. It is empty when the term is not a
+// phone number and the phone match is then left out.
+func searchPhonePattern(term string) string {
+	var digits strings.Builder
+	for _, r := range term {
+		switch {
+		case unicode.IsDigit(r):
+			digits.WriteRune(r)
+		case r == '+' || r == '-' || r == ' ':
+		default:
+			return ""
+		}
+	}
+
+	phone := digits.String()
+	if len(phone) < searchPhoneMinDigits {
+		return ""
+	}
+	if strings.HasPrefix(phone, "0") {
+		phone = "62" + phone[1:]
+	}
+
+	return "%" + phone + "%"
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/Amierza/TitipanQ/backend/repository"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// sessionRetention is how long expired and revoked sessions are kept for
+// auditing before PurgeExpiredSessions removes them.
+const sessionRetention = 30 * 24 * time.Hour
+
+type (
+	ISessionService interface {
+		StartSession(ctx context.Context, userID uuid.UUID) (entity.UserSession, error)
+		RotateSession(ctx context.Context, refreshToken string) (entity.UserSession, error)
+		ValidateSession(ctx context.Context, sessionID string) error
+		RevokeSession(ctx context.Context, sessionID string, reason string) error
+		RevokeAllUserSessions(ctx context.Context, userID string, reason string) error
+		PurgeExpiredSessions(ctx context.Context) (int64, error)
+	}
+
+	SessionService struct {
+		sessionRepo repository.ISessionRepository
+		jwtService  IJWTService
+	}
+)
+
+func NewSessionService(sessionRepo repository.ISessionRepository, jwtService IJWTService) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+		jwtService:  jwtService,
+	}
+}
+
+// StartSession records a new login, the returned session is handed to
+// GenerateToken.
+func (ss *SessionService) StartSession(ctx context.Context, userID uuid.UUID) (entity.UserSession, error) {
+	now := time.Now()
+	ip, userAgent := requestMeta(ctx)
+
+	session := entity.UserSession{
+		ID:         uuid.New(),
+		RefreshID:  uuid.New(),
+		ExpiresAt:  now.Add(RefreshTokenTTL),
+		LastUsedAt: now,
+		IPAddress:  ip,
+		UserAgent:  userAgent,
+		UserID:     &userID,
+	}
+
+	if err := ss.sessionRepo.CreateSession(ctx, nil, session); err != nil {
+		return entity.UserSession{}, dto.ErrCreateSession
+	}
+
+	return session, nil
+}
+
+// RotateSession exchanges a refresh token for a new refresh id. Refresh tokens
+// are single use: presenting one that was already rotated means it leaked, so
+// the whole session is revoked and every token of it stops working.
+func (ss *SessionService) RotateSession(ctx context.Context, refreshToken string) (entity.UserSession, error) {
+	refreshID, err := ss.jwtService.GetRefreshIDByToken(refreshToken)
+	if err != nil {
+		return entity.UserSession{}, err
+	}
+
+	sessionID, err := ss.jwtService.GetSessionIDByToken(refreshToken)
+	if err != nil {
+		return entity.UserSession{}, err
+	}
+
+	userID, err := ss.jwtService.GetUserIDByToken(refreshToken)
+	if err != nil {
+		return entity.UserSession{}, dto.ErrGetUserIDFromToken
+	}
+
+	var (
+		session entity.UserSession
+		reused  bool
+		now     = time.Now()
+	)
+	err = ss.sessionRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		var found bool
+		session, found, err = ss.sessionRepo.LockSessionByID(ctx, tx, sessionID)
+		if err != nil || !found {
+			return dto.ErrSessionNotFound
+		}
+
+		if !session.IsActive(now) || session.UserID == nil || session.UserID.String() != userID {
+			return dto.ErrSessionRevoked
+		}
+
+		if session.RefreshID.String() != refreshID {
+			reused = true
+			if err := ss.sessionRepo.RevokeSession(ctx, tx, session.ID, now, entity.SessionRevokedTokenReuse); err != nil {
+				return dto.ErrRevokeSession
+			}
+			return nil
+		}
+
+		session.RefreshID = uuid.New()
+		session.ExpiresAt = now.Add(RefreshTokenTTL)
+		session.LastUsedAt = now
+		if err := ss.sessionRepo.RotateSession(ctx, tx, session.ID, session.RefreshID, session.ExpiresAt, session.LastUsedAt); err != nil {
+			return dto.ErrRotateSession
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entity.UserSession{}, err
+	}
+
+	if reused {
+		return entity.UserSession{}, dto.ErrRefreshTokenReused
+	}
+
+	return session, nil
+}
+
+func (ss *SessionService) ValidateSession(ctx context.Context, sessionID string) error {
+	session, found, err := ss.sessionRepo.GetSessionByID(ctx, nil, sessionID)
+	if err != nil || !found {
+		return dto.ErrSessionNotFound
+	}
+
+	if !session.IsActive(time.Now()) {
+		return dto.ErrSessionRevoked
+	}
+
+	return nil
+}
+
+func (ss *SessionService) RevokeSession(ctx context.Context, sessionID string, reason string) error {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return dto.ErrParseUUID
+	}
+
+	if err := ss.sessionRepo.RevokeSession(ctx, nil, id, time.Now(), reason); err != nil {
+		return dto.ErrRevokeSession
+	}
+
+	return nil
+}
+
+func (ss *SessionService) RevokeAllUserSessions(ctx context.Context, userID string, reason string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return dto.ErrParseUUID
+	}
+
+	if err := ss.sessionRepo.RevokeAllUserSessions(ctx, nil, id, time.Now(), reason); err != nil {
+		return dto.ErrRevokeSession
+	}
+
+	return nil
+}
+
+func (ss *SessionService) PurgeExpiredSessions(ctx context.Context) (int64, error) {
+	return ss.sessionRepo.DeleteExpiredSessions(ctx, nil, time.Now().Add(-sessionRetention))
+}
@@ -2,12 +2,35 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/Amierza/TitipanQ/backend/dto"
 	"github.com/Amierza/TitipanQ/backend/entity"
 	"github.com/Amierza/TitipanQ/backend/helpers"
+	"github.com/Amierza/TitipanQ/backend/internal/mail"
+	"github.com/Amierza/TitipanQ/backend/internal/notification"
 	"github.com/Amierza/TitipanQ/backend/repository"
+	"github.com/Amierza/TitipanQ/backend/utils"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	passwordResetTTL     = time.Hour
+
+	loginOTPDigits         = 6
+	loginOTPTTL            = 5 * time.Minute
+	loginOTPMaxAttempts    = 5
+	loginOTPResendCooldown = time.Minute
+	loginOTPWindow         = time.Hour
+	loginOTPPhoneLimit     = 5
+	loginOTPIPLimit        = 20
 )
 
 type (
@@ -16,6 +39,14 @@ type (
 		Register(ctx context.Context, req dto.CreateUserRequest) (dto.UserResponse, error)
 		Login(ctx context.Context, req dto.LoginRequest) (dto.LoginResponse, error)
 		RefreshToken(ctx context.Context, req dto.RefreshTokenRequest) (dto.RefreshTokenResponse, error)
+		Logout(ctx context.Context) error
+		LogoutAll(ctx context.Context) error
+		VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error
+		ResendVerification(ctx context.Context, req dto.ResendVerificationRequest) error
+		ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest) error
+		ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error
+		RequestLoginOTP(ctx context.Context, req dto.RequestLoginOTPRequest) (dto.RequestLoginOTPResponse, error)
+		VerifyLoginOTP(ctx context.Context, req dto.VerifyLoginOTPRequest) (dto.LoginResponse, error)
 
 		// Company
 		ReadAllCompany(ctx context.Context) ([]dto.CompanyResponse, error)
@@ -25,21 +56,35 @@ type (
 		UpdateUser(ctx context.Context, req dto.UpdateUserRequest) (dto.UserResponse, error)
 
 		// Package
-		ReadAllPackage(ctx context.Context) ([]dto.PackageResponse, error)
+		ReadAllPackage(ctx context.Context, filter dto.PackageFilter) ([]dto.PackageResponse, error)
 		GetDetailPackage(ctx context.Context, pkgID string) (dto.PackageResponse, error)
 		ReadAllPackageHistory(ctx context.Context, pkgID string) ([]dto.PackageHistoryResponse, error)
+		ReadPackageTimeline(ctx context.Context, pkgID string) ([]dto.PackageTimelineDay, error)
+
+		// Pickup Delegation
+		CreatePickupDelegation(ctx context.Context, req dto.CreatePickupDelegationRequest) (dto.PickupDelegationResponse, error)
+		ReadAllPickupDelegation(ctx context.Context) ([]dto.PickupDelegationResponse, error)
+		RevokePickupDelegation(ctx context.Context, delegationID string) (dto.PickupDelegationResponse, error)
 	}
 
 	UserService struct {
-		userRepo   repository.IUserRepository
-		jwtService IJWTService
+		userRepo       repository.IUserRepository
+		jwtService     IJWTService
+		sessionService ISessionService
+		loginGuard     ILoginGuardService
+		mailer         mail.IMailer
+		whatsapp       notification.INotifier
 	}
 )
 
-func NewUserService(userRepo repository.IUserRepository, jwtService IJWTService) *UserService {
+func NewUserService(userRepo repository.IUserRepository, jwtService IJWTService, sessionService ISessionService, loginGuard ILoginGuardService, mailer mail.IMailer, whatsapp notification.INotifier) *UserService {
 	return &UserService{
-		userRepo:   userRepo,
-		jwtService: jwtService,
+		userRepo:       userRepo,
+		jwtService:     jwtService,
+		sessionService: sessionService,
+		loginGuard:     loginGuard,
+		mailer:         mailer,
+		whatsapp:       whatsapp,
 	}
 }
 
@@ -67,35 +112,40 @@ func (us *UserService) Register(ctx context.Context, req dto.CreateUserRequest)
 		return dto.UserResponse{}, dto.ErrFormatPhoneNumber
 	}
 
+	notificationChannels, err := joinNotificationChannels(req.NotificationChannels)
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+
 	role, _, err := us.userRepo.GetRoleByName(ctx, nil, "user")
 	if err != nil {
 		return dto.UserResponse{}, dto.ErrGetRoleFromName
 	}
 
 	user := entity.User{
-		ID:          uuid.New(),
-		Name:        req.Name,
-		Email:       req.Email,
-		Password:    req.Password,
-		PhoneNumber: phoneNumberFormatted,
-		Address:     req.Address,
-		RoleID:      &role.ID,
-		Role:        role,
+		ID:                   uuid.New(),
+		Name:                 req.Name,
+		Email:                req.Email,
+		Password:             req.Password,
+		PhoneNumber:          phoneNumberFormatted,
+		Address:              req.Address,
+		NotificationChannels: notificationChannels,
+		RoleID:               &role.ID,
+		Role:                 role,
 	}
 
-	err = us.userRepo.Register(ctx, nil, user)
-	if err != nil {
-		return dto.UserResponse{}, dto.ErrRegisterUser
-	}
+	err = us.userRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		if err := us.userRepo.Register(ctx, tx, user); err != nil {
+			return dto.ErrRegisterUser
+		}
 
-	if req.CompanyIDs != nil && len(req.CompanyIDs) > 0 {
 		for _, cid := range req.CompanyIDs {
 			if cid == nil {
 				continue
 			}
-			_, found, err := us.userRepo.GetCompanyByID(ctx, nil, cid.String())
+			_, found, err := us.userRepo.GetCompanyByID(ctx, tx, cid.String())
 			if err != nil || !found {
-				return dto.UserResponse{}, dto.ErrGetCompanyByID
+				return dto.ErrGetCompanyByID
 			}
 
 			userCompany := entity.UserCompany{
@@ -104,11 +154,15 @@ func (us *UserService) Register(ctx context.Context, req dto.CreateUserRequest)
 				CompanyID: cid,
 			}
 
-			err = us.userRepo.CreateUserCompany(ctx, nil, userCompany)
-			if err != nil {
-				return dto.UserResponse{}, err
+			if err := us.userRepo.CreateUserCompany(ctx, tx, userCompany); err != nil {
+				return dto.ErrFailedCreateUserCompany
 			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.UserResponse{}, err
 	}
 
 	user, _, err = us.userRepo.GetUserByID(ctx, nil, user.ID.String())
@@ -116,6 +170,12 @@ func (us *UserService) Register(ctx context.Context, req dto.CreateUserRequest)
 		return dto.UserResponse{}, err
 	}
 
+	// the account exists either way, a lost mail can be sent again with
+	// ResendVerification
+	if err := us.sendUserToken(ctx, user, entity.EmailVerificationToken); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+
 	companies := []dto.CompanyResponse{}
 	for _, uc := range user.UserCompanies {
 		companies = append(companies, dto.CompanyResponse{
@@ -126,13 +186,14 @@ func (us *UserService) Register(ctx context.Context, req dto.CreateUserRequest)
 	}
 
 	return dto.UserResponse{
-		ID:          user.ID,
-		Name:        user.Name,
-		Email:       user.Email,
-		Password:    user.Password,
-		PhoneNumber: user.PhoneNumber,
-		Address:     user.Address,
-		Companies:   companies,
+		ID:                   user.ID,
+		Name:                 user.Name,
+		Email:                user.Email,
+		Password:             user.Password,
+		PhoneNumber:          user.PhoneNumber,
+		Address:              user.Address,
+		NotificationChannels: user.GetNotificationChannels(),
+		Companies:            companies,
 		Role: dto.RoleResponse{
 			ID:   user.RoleID,
 			Name: user.Role.Name,
@@ -149,26 +210,45 @@ func (us *UserService) Login(ctx context.Context, req dto.LoginRequest) (dto.Log
 		return dto.LoginResponse{}, dto.ErrInvalidPassword
 	}
 
+	if err := us.loginGuard.CheckLogin(ctx, req.Email); err != nil {
+		return dto.LoginResponse{}, err
+	}
+
 	user, flag, err := us.userRepo.GetUserByEmail(ctx, nil, req.Email)
 	if !flag || err != nil {
+		us.loginGuard.RecordFailure(ctx, req.Email, entity.LoginAttemptEmailNotFound)
 		return dto.LoginResponse{}, dto.ErrEmailNotFound
 	}
 
 	checkPassword, err := helpers.CheckPassword(user.Password, []byte(req.Password))
 	if err != nil || !checkPassword {
+		us.loginGuard.RecordFailure(ctx, req.Email, entity.LoginAttemptPasswordNotMatch)
 		return dto.LoginResponse{}, dto.ErrPasswordNotMatch
 	}
 
-	if user.Role.Name != "user" {
+	if !user.Role.CanSignInTo(entity.UserAPIPrefix) {
+		us.loginGuard.RecordFailure(ctx, req.Email, entity.LoginAttemptDeniedAccess)
 		return dto.LoginResponse{}, dto.ErrDeniedAccess
 	}
 
-	permissions, _, err := us.userRepo.GetPermissionsByRoleID(ctx, nil, user.RoleID.String())
+	if user.EmailVerifiedAt == nil {
+		us.loginGuard.RecordFailure(ctx, req.Email, entity.LoginAttemptEmailNotVerified)
+		return dto.LoginResponse{}, dto.ErrEmailNotVerified
+	}
+
+	us.loginGuard.RecordSuccess(ctx, user)
+
+	return us.startLogin(ctx, user)
+}
+
+// startLogin opens a session for an authenticated user and issues its tokens.
+func (us *UserService) startLogin(ctx context.Context, user entity.User) (dto.LoginResponse, error) {
+	session, err := us.sessionService.StartSession(ctx, user.ID)
 	if err != nil {
-		return dto.LoginResponse{}, dto.ErrGetPermissionsByRoleID
+		return dto.LoginResponse{}, err
 	}
 
-	accessToken, refreshToken, err := us.jwtService.GenerateToken(user.ID.String(), user.RoleID.String(), permissions)
+	accessToken, refreshToken, err := us.jwtService.GenerateToken(user.ID.String(), user.RoleID.String(), session)
 	if err != nil {
 		return dto.LoginResponse{}, err
 	}
@@ -179,42 +259,358 @@ func (us *UserService) Login(ctx context.Context, req dto.LoginRequest) (dto.Log
 	}, nil
 }
 func (us *UserService) RefreshToken(ctx context.Context, req dto.RefreshTokenRequest) (dto.RefreshTokenResponse, error) {
-	_, err := us.jwtService.ValidateToken(req.RefreshToken)
+	userID, err := us.jwtService.GetUserIDByToken(req.RefreshToken)
+	if err != nil {
+		return dto.RefreshTokenResponse{}, dto.ErrGetUserIDFromToken
+	}
+
+	// the role is read again so demoted or deleted users cannot refresh
+	user, flag, err := us.userRepo.GetUserByID(ctx, nil, userID)
+	if err != nil || !flag {
+		return dto.RefreshTokenResponse{}, dto.ErrUserNotFound
+	}
+
+	if !user.Role.CanSignInTo(entity.UserAPIPrefix) {
+		return dto.RefreshTokenResponse{}, dto.ErrDeniedAccess
+	}
 
+	session, err := us.sessionService.RotateSession(ctx, req.RefreshToken)
 	if err != nil {
-		return dto.RefreshTokenResponse{}, dto.ErrValidateToken
+		return dto.RefreshTokenResponse{}, err
 	}
 
-	userID, err := us.jwtService.GetUserIDByToken(req.RefreshToken)
+	accessToken, refreshToken, err := us.jwtService.GenerateToken(userID, user.RoleID.String(), session)
 	if err != nil {
-		return dto.RefreshTokenResponse{}, dto.ErrGetUserIDFromToken
+		return dto.RefreshTokenResponse{}, err
+	}
+
+	return dto.RefreshTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+func (us *UserService) Logout(ctx context.Context) error {
+	token := ctx.Value("Authorization").(string)
+
+	sessionID, err := us.jwtService.GetSessionIDByToken(token)
+	if err != nil {
+		return err
 	}
 
-	roleID, err := us.jwtService.GetRoleIDByToken(req.RefreshToken)
+	return us.sessionService.RevokeSession(ctx, sessionID, entity.SessionRevokedLogout)
+}
+func (us *UserService) LogoutAll(ctx context.Context) error {
+	token := ctx.Value("Authorization").(string)
+
+	userID, err := us.jwtService.GetUserIDByToken(token)
 	if err != nil {
-		return dto.RefreshTokenResponse{}, dto.ErrGetRoleFromToken
+		return dto.ErrGetUserIDFromToken
 	}
 
-	role, _, err := us.userRepo.GetRoleByID(ctx, nil, roleID)
+	return us.sessionService.RevokeAllUserSessions(ctx, userID, entity.SessionRevokedLogoutAll)
+}
+
+// sendUserToken mails a fresh single-use token of purpose to the user, tokens
+// mailed before for the same purpose stop working.
+func (us *UserService) sendUserToken(ctx context.Context, user entity.User, purpose entity.TokenPurpose) error {
+	token, tokenHash, err := helpers.GenerateSecureToken()
 	if err != nil {
-		return dto.RefreshTokenResponse{}, dto.ErrGetRoleFromID
+		return dto.ErrCreateUserToken
 	}
 
-	if role.Name != "user" {
-		return dto.RefreshTokenResponse{}, dto.ErrDeniedAccess
+	ttl, path, subject := emailVerificationTTL, "/verify-email", "Verifikasi email TitipanQ"
+	if purpose == entity.PasswordResetToken {
+		ttl, path, subject = passwordResetTTL, "/reset-password", "Atur ulang kata sandi TitipanQ"
+	}
+
+	now := time.Now()
+	err = us.userRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		if err := us.userRepo.InvalidateUserTokens(ctx, tx, user.ID, purpose, now); err != nil {
+			return dto.ErrCreateUserToken
+		}
+
+		userToken := entity.UserToken{
+			ID:        uuid.New(),
+			Purpose:   purpose,
+			TokenHash: tokenHash,
+			ExpiresAt: now.Add(ttl),
+			UserID:    &user.ID,
+		}
+		if err := us.userRepo.CreateUserToken(ctx, tx, userToken); err != nil {
+			return dto.ErrCreateUserToken
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	link := frontendLink(path, token)
+	body := utils.BuildEmailVerificationMessage(user.Name, link, ttl)
+	if purpose == entity.PasswordResetToken {
+		body = utils.BuildPasswordResetMessage(user.Name, link, ttl)
+	}
+
+	return us.mailer.Send(ctx, mail.Mail{
+		To:      user.Email,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// redeemUserToken locks the token behind rawToken and marks it used within
+// tx, it fails when the token is unknown, expired or already used.
+func (us *UserService) redeemUserToken(ctx context.Context, tx *gorm.DB, purpose entity.TokenPurpose, rawToken string, now time.Time) (entity.UserToken, error) {
+	token, flag, err := us.userRepo.LockUserTokenByHash(ctx, tx, purpose, helpers.HashToken(rawToken))
+	if err != nil || !flag || !token.IsUsable(now) || token.UserID == nil {
+		return entity.UserToken{}, dto.ErrUserTokenInvalid
 	}
 
-	endpoints, _, err := us.userRepo.GetPermissionsByRoleID(ctx, nil, roleID)
+	if err := us.userRepo.UseUserToken(ctx, tx, token.ID, now); err != nil {
+		return entity.UserToken{}, dto.ErrUserTokenInvalid
+	}
+
+	return token, nil
+}
+
+// frontendLink points at the frontend page handling path, FRONTEND_URL is
+// the base address of the web app.
+func frontendLink(path, token string) string {
+	base := strings.TrimRight(os.Getenv("FRONTEND_URL"), "/")
+	if base == "" {
+		base = "http://localhost:3000"
+	}
+
+	return base + path + "?token=" + url.QueryEscape(token)
+}
+func (us *UserService) VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error {
+	now := time.Now()
+
+	return us.userRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		token, err := us.redeemUserToken(ctx, tx, entity.EmailVerificationToken, req.Token, now)
+		if err != nil {
+			return err
+		}
+
+		if err := us.userRepo.UpdateUserEmailVerifiedAt(ctx, tx, *token.UserID, now); err != nil {
+			return dto.ErrVerifyEmail
+		}
+
+		return nil
+	})
+}
+
+// ResendVerification and ForgotPassword answer the same whether or not the
+// email belongs to an account, so they cannot be used to probe for users.
+func (us *UserService) ResendVerification(ctx context.Context, req dto.ResendVerificationRequest) error {
+	user, flag, err := us.userRepo.GetUserByEmail(ctx, nil, req.Email)
+	if err != nil || !flag || user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	if err := us.sendUserToken(ctx, user, entity.EmailVerificationToken); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+
+	return nil
+}
+func (us *UserService) ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest) error {
+	user, flag, err := us.userRepo.GetUserByEmail(ctx, nil, req.Email)
+	if err != nil || !flag {
+		return nil
+	}
+
+	if err := us.sendUserToken(ctx, user, entity.PasswordResetToken); err != nil {
+		log.Printf("Failed to send password reset email to %s: %v", user.Email, err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password with a token from ForgotPassword. Every
+// session of the user is revoked, and since the token arrived by mail the
+// email counts as verified.
+func (us *UserService) ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error {
+	if len(req.NewPassword) < 8 {
+		return dto.ErrInvalidPassword
+	}
+
+	hashedPassword, err := helpers.HashPassword(req.NewPassword)
 	if err != nil {
-		return dto.RefreshTokenResponse{}, dto.ErrGetPermissionsByRoleID
+		return dto.ErrHashPassword
 	}
 
-	accessToken, _, err := us.jwtService.GenerateToken(userID, roleID, endpoints)
+	now := time.Now()
+	var userID uuid.UUID
+	err = us.userRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		token, err := us.redeemUserToken(ctx, tx, entity.PasswordResetToken, req.Token, now)
+		if err != nil {
+			return err
+		}
+		userID = *token.UserID
+
+		if err := us.userRepo.UpdateUserPassword(ctx, tx, userID, hashedPassword); err != nil {
+			return dto.ErrResetPassword
+		}
+
+		if err := us.userRepo.UpdateUserEmailVerifiedAt(ctx, tx, userID, now); err != nil {
+			return dto.ErrResetPassword
+		}
+
+		return nil
+	})
 	if err != nil {
-		return dto.RefreshTokenResponse{}, dto.ErrGenerateAccessToken
+		return err
 	}
 
-	return dto.RefreshTokenResponse{AccessToken: accessToken}, nil
+	return us.sessionService.RevokeAllUserSessions(ctx, userID.String(), entity.SessionRevokedPassword)
+}
+
+// RequestLoginOTP sends a login code to the phone number over WhatsApp. A code
+// row is written for unknown numbers too, so they are rate limited and answered
+// exactly like registered ones, but nothing is sent.
+func (us *UserService) RequestLoginOTP(ctx context.Context, req dto.RequestLoginOTPRequest) (dto.RequestLoginOTPResponse, error) {
+	phoneNumber, err := helpers.StandardizePhoneNumber(req.PhoneNumber)
+	if err != nil {
+		return dto.RequestLoginOTPResponse{}, dto.ErrFormatPhoneNumber
+	}
+
+	code, err := helpers.GenerateNumericCode(loginOTPDigits)
+	if err != nil {
+		return dto.RequestLoginOTPResponse{}, dto.ErrCreateOTP
+	}
+
+	now := time.Now()
+	ip, _ := requestMeta(ctx)
+
+	var recipient *entity.User
+	err = us.userRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		// the latest code is locked so concurrent requests for a number queue up
+		latest, found, _ := us.userRepo.LockLatestLoginOTP(ctx, tx, phoneNumber)
+		if found && now.Sub(latest.CreatedAt) < loginOTPResendCooldown {
+			return dto.ErrOTPRateLimited
+		}
+
+		byPhone, byIP, err := us.userRepo.CountLoginOTPsSince(ctx, tx, phoneNumber, ip, now.Add(-loginOTPWindow))
+		if err != nil {
+			return dto.ErrCreateOTP
+		}
+
+		if byPhone >= loginOTPPhoneLimit || byIP >= loginOTPIPLimit {
+			return dto.ErrOTPRateLimited
+		}
+
+		otp := entity.LoginOTP{
+			ID:          uuid.New(),
+			PhoneNumber: phoneNumber,
+			CodeHash:    helpers.HashToken(code),
+			ExpiresAt:   now.Add(loginOTPTTL),
+			IPAddress:   ip,
+		}
+
+		if user, flag, err := us.userRepo.GetUserByPhoneNumber(ctx, tx, phoneNumber); err == nil && flag && user.Role.CanSignInTo(entity.UserAPIPrefix) {
+			otp.UserID = &user.ID
+			recipient = &user
+		}
+
+		if err := us.userRepo.ConsumeLoginOTPs(ctx, tx, phoneNumber, now); err != nil {
+			return dto.ErrCreateOTP
+		}
+
+		if err := us.userRepo.CreateLoginOTP(ctx, tx, otp); err != nil {
+			return dto.ErrCreateOTP
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.RequestLoginOTPResponse{}, err
+	}
+
+	if recipient != nil {
+		err := us.whatsapp.Send(ctx, notification.Message{
+			PhoneNumber: recipient.PhoneNumber,
+			Body:        utils.BuildLoginOTPMessage(code, loginOTPTTL),
+			Channels:    []entity.NotificationChannel{entity.WhatsAppChannel},
+		})
+		if err != nil {
+			log.Printf("Failed to send login code to %s: %v", recipient.PhoneNumber, err)
+			return dto.RequestLoginOTPResponse{}, dto.ErrSendOTP
+		}
+	}
+
+	return dto.RequestLoginOTPResponse{
+		ExpiresIn:  int(loginOTPTTL.Seconds()),
+		RetryAfter: int(loginOTPResendCooldown.Seconds()),
+	}, nil
+}
+
+// VerifyLoginOTP logs the user in with the latest code sent to the phone
+// number. Wrong codes are counted and the code is burnt after
+// loginOTPMaxAttempts. The phone proves who the resident is, so unlike Login
+// an unverified email does not block it.
+func (us *UserService) VerifyLoginOTP(ctx context.Context, req dto.VerifyLoginOTPRequest) (dto.LoginResponse, error) {
+	phoneNumber, err := helpers.StandardizePhoneNumber(req.PhoneNumber)
+	if err != nil {
+		return dto.LoginResponse{}, dto.ErrFormatPhoneNumber
+	}
+
+	now := time.Now()
+
+	// wrong attempts must be saved, so they are reported after the commit
+	var (
+		verifyErr error
+		userID    uuid.UUID
+	)
+	err = us.userRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		otp, found, _ := us.userRepo.LockLatestLoginOTP(ctx, tx, phoneNumber)
+		if !found || !otp.IsUsable(now) || otp.UserID == nil {
+			verifyErr = dto.ErrOTPInvalid
+			return nil
+		}
+
+		attempts := otp.Attempts + 1
+		if subtle.ConstantTimeCompare([]byte(helpers.HashToken(req.Code)), []byte(otp.CodeHash)) != 1 {
+			var consumedAt *time.Time
+			verifyErr = dto.ErrOTPInvalid
+			if attempts >= loginOTPMaxAttempts {
+				consumedAt = &now
+				verifyErr = dto.ErrOTPTooManyAttempts
+			}
+
+			if err := us.userRepo.UpdateLoginOTPAttempts(ctx, tx, otp.ID, attempts, consumedAt); err != nil {
+				return dto.ErrOTPInvalid
+			}
+
+			return nil
+		}
+
+		if err := us.userRepo.UpdateLoginOTPAttempts(ctx, tx, otp.ID, attempts, &now); err != nil {
+			return dto.ErrOTPInvalid
+		}
+		userID = *otp.UserID
+
+		return nil
+	})
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	if verifyErr != nil {
+		return dto.LoginResponse{}, verifyErr
+	}
+
+	user, flag, err := us.userRepo.GetUserByID(ctx, nil, userID.String())
+	if err != nil || !flag {
+		return dto.LoginResponse{}, dto.ErrUserNotFound
+	}
+
+	if !user.Role.CanSignInTo(entity.UserAPIPrefix) {
+		return dto.LoginResponse{}, dto.ErrDeniedAccess
+	}
+
+	return us.startLogin(ctx, user)
 }
 
 // Company
@@ -260,13 +656,14 @@ func (us *UserService) GetDetailUser(ctx context.Context) (dto.UserResponse, err
 	}
 
 	return dto.UserResponse{
-		ID:          user.ID,
-		Name:        user.Name,
-		Email:       user.Email,
-		Password:    user.Password,
-		PhoneNumber: user.PhoneNumber,
-		Address:     user.Address,
-		Companies:   companies,
+		ID:                   user.ID,
+		Name:                 user.Name,
+		Email:                user.Email,
+		Password:             user.Password,
+		PhoneNumber:          user.PhoneNumber,
+		Address:              user.Address,
+		NotificationChannels: user.GetNotificationChannels(),
+		Companies:            companies,
 		Role: dto.RoleResponse{
 			ID:   user.RoleID,
 			Name: user.Role.Name,
@@ -334,40 +731,53 @@ func (us *UserService) UpdateUser(ctx context.Context, req dto.UpdateUserRequest
 		user.Address = req.Address
 	}
 
-	if len(req.CompanyIDs) > 0 {
-		err := us.userRepo.DeleteUserCompaniesByUserID(ctx, nil, user.ID.String())
+	if len(req.NotificationChannels) > 0 {
+		notificationChannels, err := joinNotificationChannels(req.NotificationChannels)
 		if err != nil {
-			return dto.UserResponse{}, dto.ErrDeletedUserCompanies
+			return dto.UserResponse{}, err
 		}
-		for _, companyID := range req.CompanyIDs {
-			_, flag, err := us.userRepo.GetCompanyByID(ctx, nil, companyID.String())
-			if err != nil || !flag {
-				return dto.UserResponse{}, dto.ErrFindCompanyID
+
+		user.NotificationChannels = notificationChannels
+	}
+
+	err = us.userRepo.RunInTransaction(ctx, func(tx *gorm.DB) error {
+		if len(req.CompanyIDs) > 0 {
+			if err := us.userRepo.DeleteUserCompaniesByUserID(ctx, tx, user.ID.String()); err != nil {
+				return dto.ErrDeletedUserCompanies
 			}
 
-			userCompany := entity.UserCompany{
-				ID:        uuid.New(),
-				UserID:    &user.ID,
-				CompanyID: companyID,
+			for _, companyID := range req.CompanyIDs {
+				_, flag, err := us.userRepo.GetCompanyByID(ctx, tx, companyID.String())
+				if err != nil || !flag {
+					return dto.ErrFindCompanyID
+				}
+
+				userCompany := entity.UserCompany{
+					ID:        uuid.New(),
+					UserID:    &user.ID,
+					CompanyID: companyID,
+				}
+
+				if err := us.userRepo.CreateUserCompany(ctx, tx, userCompany); err != nil {
+					return dto.ErrFailedCreateUserCompany
+				}
 			}
 
-			err = us.userRepo.CreateUserCompany(ctx, nil, userCompany)
-			if err != nil {
-				return dto.UserResponse{}, dto.ErrFailedCreateUserCompany
+			// load ulang relasi UserCompanies
+			user.UserCompanies = nil
+			if err := us.userRepo.PreloadUserCompanies(ctx, tx, &user); err != nil {
+				return dto.ErrFailedPreloadUserCompany
 			}
 		}
 
-		// load ulang relasi UserCompanies
-		user.UserCompanies = nil
-		err = us.userRepo.PreloadUserCompanies(ctx, nil, &user)
-		if err != nil {
-			return dto.UserResponse{}, dto.ErrFailedPreloadUserCompany
+		if err := us.userRepo.UpdateUser(ctx, tx, user); err != nil {
+			return dto.ErrUpdateUser
 		}
-	}
 
-	err = us.userRepo.UpdateUser(ctx, nil, user)
+		return nil
+	})
 	if err != nil {
-		return dto.UserResponse{}, dto.ErrUpdateUser
+		return dto.UserResponse{}, err
 	}
 
 	var companies []dto.CompanyResponse
@@ -380,13 +790,14 @@ func (us *UserService) UpdateUser(ctx context.Context, req dto.UpdateUserRequest
 	}
 
 	res := dto.UserResponse{
-		ID:          user.ID,
-		Name:        user.Name,
-		Email:       user.Email,
-		Password:    user.Password,
-		PhoneNumber: user.PhoneNumber,
-		Address:     user.Address,
-		Companies:   companies,
+		ID:                   user.ID,
+		Name:                 user.Name,
+		Email:                user.Email,
+		Password:             user.Password,
+		PhoneNumber:          user.PhoneNumber,
+		Address:              user.Address,
+		NotificationChannels: user.GetNotificationChannels(),
+		Companies:            companies,
 		Role: dto.RoleResponse{
 			ID:   user.RoleID,
 			Name: user.Role.Name,
@@ -397,7 +808,7 @@ func (us *UserService) UpdateUser(ctx context.Context, req dto.UpdateUserRequest
 }
 
 // Package
-func (us *UserService) ReadAllPackage(ctx context.Context) ([]dto.PackageResponse, error) {
+func (us *UserService) ReadAllPackage(ctx context.Context, filter dto.PackageFilter) ([]dto.PackageResponse, error) {
 	token := ctx.Value("Authorization").(string)
 
 	userID, err := us.jwtService.GetUserIDByToken(token)
@@ -405,7 +816,11 @@ func (us *UserService) ReadAllPackage(ctx context.Context) ([]dto.PackageRespons
 		return []dto.PackageResponse{}, dto.ErrGetUserIDFromToken
 	}
 
-	dataWithPaginate, err := us.userRepo.GetAllPackage(ctx, nil, userID)
+	if err := filter.Validate(); err != nil {
+		return []dto.PackageResponse{}, err
+	}
+
+	dataWithPaginate, err := us.userRepo.GetAllPackage(ctx, nil, userID, filter)
 	if err != nil {
 		return []dto.PackageResponse{}, dto.ErrGetAllPackageWithPagination
 	}
@@ -447,6 +862,7 @@ func (us *UserService) ReadAllPackage(ctx context.Context) ([]dto.PackageRespons
 				UpdatedAt: pkg.UpdatedAt,
 				DeletedAt: pkg.DeletedAt,
 			},
+			Company: toPackageCompanyResponse(pkg),
 		}
 		datas = append(datas, data)
 	}
@@ -495,6 +911,7 @@ func (us *UserService) GetDetailPackage(ctx context.Context, pkgID string) (dto.
 			UpdatedAt: pkg.UpdatedAt,
 			DeletedAt: pkg.DeletedAt,
 		},
+		Company: toPackageCompanyResponse(pkg),
 	}, nil
 }
 func (us *UserService) ReadAllPackageHistory(ctx context.Context, pkgID string) ([]dto.PackageHistoryResponse, error) {
@@ -503,21 +920,135 @@ func (us *UserService) ReadAllPackageHistory(ctx context.Context, pkgID string)
 		return []dto.PackageHistoryResponse{}, dto.ErrGetAllPackageHistory
 	}
 
+	// owners do not get the request metadata of the staff who made the change
 	var datas []dto.PackageHistoryResponse
 	for _, pkgH := range dataWithPaginate {
-		data := dto.PackageHistoryResponse{
-			ID:     pkgH.ID,
-			Status: pkgH.Status,
-			ChangedBy: dto.UserResponseCustom{
-				ID:    pkgH.ChangedByUser.ID,
-				Name:  pkgH.ChangedByUser.Name,
-				Email: pkgH.ChangedByUser.Email,
-			},
-			CreatedAt: pkgH.CreatedAt,
+		datas = append(datas, toPackageHistoryResponse(pkgH, false))
+	}
+
+	return datas, nil
+}
+func (us *UserService) ReadPackageTimeline(ctx context.Context, pkgID string) ([]dto.PackageTimelineDay, error) {
+	histories, err := us.ReadAllPackageHistory(ctx, pkgID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildPackageTimeline(histories), nil
+}
+
+// Pickup Delegation
+func (us *UserService) CreatePickupDelegation(ctx context.Context, req dto.CreatePickupDelegationRequest) (dto.PickupDelegationResponse, error) {
+	token := ctx.Value("Authorization").(string)
+
+	userIDStr, err := us.jwtService.GetUserIDByToken(token)
+	if err != nil {
+		return dto.PickupDelegationResponse{}, dto.ErrGetUserIDFromToken
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return dto.PickupDelegationResponse{}, dto.ErrParseUUID
+	}
+
+	if len(req.Name) < 3 {
+		return dto.PickupDelegationResponse{}, dto.ErrInvalidDelegateName
+	}
+
+	phoneNumberFormatted, err := helpers.StandardizePhoneNumber(req.PhoneNumber)
+	if err != nil {
+		return dto.PickupDelegationResponse{}, dto.ErrFormatPhoneNumber
+	}
+
+	now := time.Now()
+	validFrom := now
+	if req.ValidFrom != nil {
+		validFrom = *req.ValidFrom
+	}
+
+	if !req.ValidUntil.After(validFrom) || !req.ValidUntil.After(now) {
+		return dto.PickupDelegationResponse{}, dto.ErrInvalidDelegationWindow
+	}
+
+	delegation := entity.PickupDelegation{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		PhoneNumber: phoneNumberFormatted,
+		ValidFrom:   validFrom,
+		ValidUntil:  req.ValidUntil,
+		AllPackages: len(req.PackageIDs) == 0,
+		UserID:      &userID,
+	}
+
+	for _, pkgID := range req.PackageIDs {
+		pkg, flag, err := us.userRepo.GetPackageByID(ctx, nil, pkgID.String())
+		if err != nil || !flag {
+			return dto.PickupDelegationResponse{}, dto.ErrPackageNotFound
 		}
 
-		datas = append(datas, data)
+		owned := pkg.UserID != nil && *pkg.UserID == userID
+		if !owned && pkg.CompanyID != nil {
+			owned, err = us.userRepo.IsCompanyMember(ctx, nil, *pkg.CompanyID, userID)
+			if err != nil {
+				return dto.PickupDelegationResponse{}, dto.ErrGetCompanyByUserID
+			}
+		}
+		if !owned {
+			return dto.PickupDelegationResponse{}, dto.ErrPackageNotOwned
+		}
+
+		delegation.Packages = append(delegation.Packages, entity.Package{ID: pkg.ID})
+	}
+
+	if err := us.userRepo.CreatePickupDelegation(ctx, nil, delegation); err != nil {
+		return dto.PickupDelegationResponse{}, dto.ErrCreatePickupDelegation
+	}
+
+	return toPickupDelegationResponse(delegation, now), nil
+}
+func (us *UserService) ReadAllPickupDelegation(ctx context.Context) ([]dto.PickupDelegationResponse, error) {
+	token := ctx.Value("Authorization").(string)
+
+	userID, err := us.jwtService.GetUserIDByToken(token)
+	if err != nil {
+		return nil, dto.ErrGetUserIDFromToken
+	}
+
+	delegations, err := us.userRepo.GetAllPickupDelegationByUserID(ctx, nil, userID)
+	if err != nil {
+		return nil, dto.ErrGetAllPickupDelegation
+	}
+
+	now := time.Now()
+	var datas []dto.PickupDelegationResponse
+	for _, delegation := range delegations {
+		datas = append(datas, toPickupDelegationResponse(delegation, now))
 	}
 
 	return datas, nil
 }
+func (us *UserService) RevokePickupDelegation(ctx context.Context, delegationID string) (dto.PickupDelegationResponse, error) {
+	token := ctx.Value("Authorization").(string)
+
+	userID, err := us.jwtService.GetUserIDByToken(token)
+	if err != nil {
+		return dto.PickupDelegationResponse{}, dto.ErrGetUserIDFromToken
+	}
+
+	delegation, flag, err := us.userRepo.GetPickupDelegationByID(ctx, nil, delegationID)
+	if err != nil || !flag || delegation.UserID == nil || delegation.UserID.String() != userID {
+		return dto.PickupDelegationResponse{}, dto.ErrPickupDelegationNotFound
+	}
+
+	if delegation.RevokedAt != nil {
+		return dto.PickupDelegationResponse{}, dto.ErrPickupDelegationRevoked
+	}
+
+	now := time.Now()
+	if err := us.userRepo.RevokePickupDelegation(ctx, nil, delegation.ID, now); err != nil {
+		return dto.PickupDelegationResponse{}, dto.ErrRevokePickupDelegation
+	}
+	delegation.RevokedAt = &now
+
+	return toPickupDelegationResponse(delegation, now), nil
+}
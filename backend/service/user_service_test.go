@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Amierza/TitipanQ/backend/dto"
+	"github.com/Amierza/TitipanQ/backend/entity"
+	"github.com/Amierza/TitipanQ/backend/repository"
+	"gorm.io/gorm"
+)
+
+type loginUserRepo struct {
+	repository.IUserRepository
+	user entity.User
+}
+
+func (r loginUserRepo) GetUserByEmail(ctx context.Context, tx *gorm.DB, email string) (entity.User, bool, error) {
+	return r.user, true, nil
+}
+
+func TestUserLoginByRole(t *testing.T) {
+	tests := []struct {
+		role string
+		want error
+	}{
+		{"user", nil},
+		{"custom user", nil},
+		{"admin", dto.ErrDeniedAccess},
+		{"company admin", dto.ErrDeniedAccess},
+		{"custom admin", dto.ErrDeniedAccess},
+		{"custom empty", dto.ErrDeniedAccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			us := NewUserService(loginUserRepo{user: loginUser(t, loginRoles[tt.role])}, jwtStub{}, sessionStub{}, loginGuardStub{}, nil, nil)
+
+			_, err := us.Login(context.Background(), dto.LoginRequest{Email: "someone@example.com", Password: "password123"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Login() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Amierza/TitipanQ/backend/entity"
@@ -39,7 +40,7 @@ func BuildResponseFailed(message string, err string, data any) Response {
 	return res
 }
 
-func BuildReceivedMessage(p *entity.Package) string {
+func BuildReceivedMessage(p *entity.Package, pickupCode string) string {
 	return fmt.Sprintf(
 		`📦 Paket dengan kode *%s* telah diterima oleh kantor TitipanQ pada *%s*.
 
@@ -48,6 +49,9 @@ Jumlah: %d
 Tipe: %s
 Pengirim: %s
 
+🔐 Kode pengambilan: *%s*
+Tunjukkan kode ini kepada petugas saat mengambil paket. Jangan bagikan kode ini kepada orang lain.
+
 Kami akan segera memprosesnya.`,
 		p.TrackingCode,
 		p.CreatedAt.Format("02 Jan 2006"),
@@ -55,12 +59,74 @@ Kami akan segera memprosesnya.`,
 		p.Quantity,
 		p.Type,
 		p.Sender.Name,
+		pickupCode,
+	)
+}
+
+// BuildReceivedBatchMessage lists every package received for one recipient in
+// a single message, pickupCodes is parallel to packages.
+func BuildReceivedBatchMessage(packages []entity.Package, pickupCodes []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📦 %d paket untuk Anda telah diterima oleh kantor TitipanQ pada *%s*.\n", len(packages), packages[0].CreatedAt.Format("02 Jan 2006"))
+
+	for i, p := range packages {
+		fmt.Fprintf(&b, `
+%d. Kode paket: *%s*
+Deskripsi: %s
+Jumlah: %d
+Tipe: %s
+Pengirim: %s
+🔐 Kode pengambilan: *%s*
+`,
+			i+1,
+			p.TrackingCode,
+			p.Description,
+			p.Quantity,
+			p.Type,
+			p.Sender.Name,
+			pickupCodes[i],
+		)
+	}
+
+	b.WriteString("\nTunjukkan kode pengambilan kepada petugas saat mengambil paket. Jangan bagikan kode ini kepada orang lain.")
+
+	return b.String()
+}
+
+func BuildPickupCodeMessage(p *entity.Package, pickupCode string) string {
+	return fmt.Sprintf(
+		`🔐 Kode pengambilan baru untuk paket *%s*: *%s*
+
+Kode sebelumnya sudah tidak berlaku. Tunjukkan kode ini kepada petugas saat mengambil paket.`,
+		p.TrackingCode,
+		pickupCode,
 	)
 }
 
-func BuildCompletedMessage(p *entity.Package) string {
+func BuildCompletedMessage(p *entity.Package, delegation *entity.PickupDelegation) string {
 	var message string
-	if p.UserID != nil {
+	if p.UserID != nil && delegation != nil {
+		message = fmt.Sprintf(
+			`✅ Paket dengan kode *%s* telah diambil oleh kuasa Anda pada *%s*.
+
+Deskripsi: %s
+Jumlah: %d
+Tipe: %s
+Nama Penerima: %s
+No Hp Penerima: %s
+Atas Nama: %s
+
+Jika Anda tidak memberikan kuasa ini, segera hubungi petugas TitipanQ.`,
+			p.TrackingCode,
+			p.CompletedAt.Format("02 Jan 2006"),
+			p.Description,
+			p.Quantity,
+			p.Type,
+			delegation.Name,
+			delegation.PhoneNumber,
+			p.User.Name,
+		)
+	} else if p.UserID != nil {
 		message = fmt.Sprintf(
 			`✅ Paket dengan kode *%s* telah berhasil diterima oleh pemilik pada *%s*.
 
@@ -85,3 +151,69 @@ Terima kasih telah menggunakan layanan TitipanQ!`,
 	
 	return message
 }
+
+// BuildCompanyCompletedMessage tells the members of a company that its package
+// was collected, collectedBy is empty when the admin did not record who.
+func BuildCompanyCompletedMessage(p *entity.Package, collectedBy string) string {
+	if collectedBy == "" {
+		collectedBy = "-"
+	}
+
+	return fmt.Sprintf(
+		`✅ Paket untuk *%s* dengan kode *%s* telah diambil pada *%s*.
+
+Deskripsi: %s
+Jumlah: %d
+Tipe: %s
+Diambil oleh: %s
+
+Terima kasih telah menggunakan layanan TitipanQ!`,
+		p.Company.Name,
+		p.TrackingCode,
+		p.CompletedAt.Format("02 Jan 2006"),
+		p.Description,
+		p.Quantity,
+		p.Type,
+		collectedBy,
+	)
+}
+
+func BuildEmailVerificationMessage(name, link string, ttl time.Duration) string {
+	return fmt.Sprintf(
+		`Halo %s,
+
+Terima kasih telah mendaftar di TitipanQ. Buka tautan berikut untuk memverifikasi email Anda:
+
+%s
+
+Tautan ini berlaku selama %d jam dan hanya dapat digunakan satu kali. Abaikan email ini jika Anda tidak merasa mendaftar.`,
+		name,
+		link,
+		int(ttl.Hours()),
+	)
+}
+
+func BuildPasswordResetMessage(name, link string, ttl time.Duration) string {
+	return fmt.Sprintf(
+		`Halo %s,
+
+Kami menerima permintaan untuk mengatur ulang kata sandi akun TitipanQ Anda. Buka tautan berikut untuk membuat kata sandi baru:
+
+%s
+
+Tautan ini berlaku selama %d jam dan hanya dapat digunakan satu kali. Abaikan email ini jika Anda tidak meminta pengaturan ulang kata sandi, kata sandi Anda tidak akan berubah.`,
+		name,
+		link,
+		int(ttl.Hours()),
+	)
+}
+
+func BuildLoginOTPMessage(code string, ttl time.Duration) string {
+	return fmt.Sprintf(
+		`🔐 Kode masuk TitipanQ Anda: *%s*
+
+Kode ini berlaku selama %d menit. Jangan bagikan kode ini kepada siapa pun, termasuk petugas TitipanQ.`,
+		code,
+		int(ttl.Minutes()),
+	)
+}
@@ -82,6 +82,10 @@ axiosAdminConfig.interceptors.response.use(
 
         const newAccessToken = response.data.data.access_token;
         localStorage.setItem("access_token", newAccessToken);
+        // refresh tokens are single use, keep the rotated one
+        if (response.data.data.refresh_token) {
+          localStorage.setItem("refresh_token", response.data.data.refresh_token);
+        }
         axiosAdminConfig.defaults.headers.common.Authorization = `Bearer ${newAccessToken}`;
         originalRequest.headers.Authorization = `Bearer ${newAccessToken}`;
 
@@ -78,6 +78,10 @@ axiosUserConfig.interceptors.response.use(
         }
 
         localStorage.setItem("access_token", newAccessToken);
+        // refresh tokens are single use, keep the rotated one
+        if (response.data?.data?.refresh_token) {
+          localStorage.setItem("refresh_token", response.data.data.refresh_token);
+        }
         axiosUserConfig.defaults.headers.common.Authorization = `Bearer ${newAccessToken}`;
         originalRequest.headers.Authorization = `Bearer ${newAccessToken}`;
 